@@ -0,0 +1,577 @@
+// Package aco — муравьиный алгоритм поиска пути на графе без браузера.
+//
+// Модель повторяет static/js/aco.js: тот же генератор псевдослучайных чисел, размещение
+// вершин на холсте, выбор самой удаленной пары вершин как старта и цели, правило рулетки
+// с порогом 1e-10 для феромона и расстояния, испарение с нижней границей 1e-10 и
+// симметричное подкрепление на неориентированном графе. При одинаковом зерне и размере
+// холста Colony проходит те же итерации, что и страница в браузере на V8: Math.pow и
+// Math.hypot воспроизводятся бит в бит (см. internal/jsmath).
+//
+// Кроме случайного графа колония работает на графах из файлов TSPLIB, DIMACS и CSV,
+// в том числе ориентированных и неполных: см. LoadInstance и NewOnInstance.
+//
+// Задача колонии — путь от старта до цели (Path, как на странице по умолчанию) или
+// замкнутый обход всех вершин (Tour), как в Ant System Дориго для задачи коммивояжера.
+// Для туров на графах TSPLIB с известным оптимумом Gap возвращает отставание от него.
+//
+// Правило обновления феромона задает Variant: кроме исходной Ant System, которую повторяет
+// страница, есть элитная AS, ранговая AS_rank, MAX–MIN AS и Ant Colony System (variants.go).
+// Эти варианты есть только в Go и сравниваются на одних и тех же графах через API и sweep
+package aco
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/RiddlerXenon/roi/internal/jsmath"
+)
+
+// GraphType — тип графа: на неориентированном феромон откладывается в обе стороны ребра
+type GraphType string
+
+const (
+	Undirected GraphType = "undirected"
+	Directed   GraphType = "directed"
+)
+
+// StartDist — распределение стартовых вершин муравьев
+type StartDist string
+
+const (
+	// Uniform — каждый муравей стартует из случайной вершины
+	Uniform StartDist = "uniform"
+	// Fixed — все муравьи стартуют из начальной точки
+	Fixed StartDist = "fixed"
+)
+
+// Objective — задача, которую решают муравьи
+type Objective string
+
+const (
+	// Path — путь от начальной вершины до цели
+	Path Objective = "path"
+	// Tour — гамильтонов цикл: муравей обходит все вершины и возвращается в первую
+	Tour Objective = "tour"
+)
+
+// EngineVersion — версия модели. Увеличивается, когда правка меняет итерации при тех же
+// параметрах: манифесты прогонов прежней версии перестают воспроизводиться
+const EngineVersion = 1
+
+// Отступ вершин от края холста и нижняя граница феромона и расстояния, как в aco.js
+const (
+	margin       = 50
+	minPheromone = 1e-10
+)
+
+// Params — параметры алгоритма; имена JSON совпадают с полями params в aco.js
+// и с описаниями в static/latex/params/aco.tex, кроме ключей из ParamAliases.
+// Параметры вариантов, от Variant до Xi, есть только в Go; каждый вариант читает лишь свои
+type Params struct {
+	NodeCount     int       `json:"nodeCount"`
+	Alpha         float64   `json:"alpha"`
+	Beta          float64   `json:"beta"`
+	Rho           float64   `json:"rho"`
+	Q             float64   `json:"Q"`
+	ColonySize    int       `json:"colonySize"`
+	MaxIterations int       `json:"maxIterations"`
+	Tau0          float64   `json:"tau0"`
+	GraphType     GraphType `json:"graphType"`
+	StartDist     StartDist `json:"startDist"`
+	Objective     Objective `json:"objective"`
+	Seed          float64   `json:"seed"`
+
+	Variant       Variant `json:"variant"`
+	ElitistWeight float64 `json:"elitistWeight"` // e: вес лучшего пути в элитной AS
+	Ranks         int     `json:"ranks"`         // w: в AS_rank подкрепляют w − 1 лучших муравьев итерации
+	TauMin        float64 `json:"tauMin"`        // границы феромона MMAS; 0 — по лучшему пути
+	TauMax        float64 `json:"tauMax"`
+	RestartAfter  int     `json:"restartAfter"` // перезапуск MMAS после стольких итераций без улучшения; 0 — без перезапусков
+	Q0            float64 `json:"q0"`           // доля жадных переходов в ACS
+	Xi            float64 `json:"xi"`           // локальное испарение ACS
+
+	// Width и Height — размер холста в пикселях; от него зависят координаты вершин
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+}
+
+// ParamAliases сопоставляет ключам описаний aco.tex, которые отличаются от имен полей JSON,
+// имена полей. Ключи принимают API и cmd/sweep
+var ParamAliases = map[string]string{"m": "colonySize", "T": "maxIterations", "e": "elitistWeight", "w": "ranks"}
+
+// DefaultParams возвращает параметры страницы templates/aco.html на холсте 1280×720
+func DefaultParams() Params {
+	return Params{
+		NodeCount:     10,
+		Alpha:         1.0,
+		Beta:          2.0,
+		Rho:           0.5,
+		Q:             1.0,
+		ColonySize:    10,
+		MaxIterations: 100,
+		Tau0:          1.0,
+		GraphType:     Undirected,
+		StartDist:     Fixed,
+		Objective:     Path,
+		Seed:          42,
+		Width:         1280,
+		Height:        720,
+		Variant:       AntSystem,
+		ElitistWeight: 10,
+		Ranks:         6,
+		RestartAfter:  50,
+		Q0:            0.9,
+		Xi:            0.1,
+	}
+}
+
+// Validate проверяет ограничения из static/latex/params/aco.tex
+func (p Params) Validate() error {
+	switch {
+	case p.NodeCount < 2:
+		return fmt.Errorf("nodeCount = %d: нужно не меньше двух вершин", p.NodeCount)
+	case p.ColonySize < 1:
+		return fmt.Errorf("colonySize = %d: нужен хотя бы один муравей", p.ColonySize)
+	case p.MaxIterations < 0:
+		return fmt.Errorf("maxIterations = %d: бюджет итераций не может быть отрицательным", p.MaxIterations)
+	case !(p.Rho > 0 && p.Rho <= 1):
+		return fmt.Errorf("rho = %v: коэффициент испарения должен лежать в (0, 1]", p.Rho)
+	case !(p.Q > 0):
+		return fmt.Errorf("Q = %v: интенсивность подкрепления должна быть положительной", p.Q)
+	case !(p.Tau0 > 0):
+		return fmt.Errorf("tau0 = %v: начальная концентрация феромона должна быть положительной", p.Tau0)
+	case p.GraphType != Undirected && p.GraphType != Directed:
+		return fmt.Errorf("graphType = %q: ожидается %q или %q", p.GraphType, Undirected, Directed)
+	case p.StartDist != Uniform && p.StartDist != Fixed:
+		return fmt.Errorf("startDist = %q: ожидается %q или %q", p.StartDist, Uniform, Fixed)
+	case p.Objective != Path && p.Objective != Tour:
+		return fmt.Errorf("objective = %q: ожидается %q или %q", p.Objective, Path, Tour)
+	case !slices.Contains(Variants, p.Variant):
+		return fmt.Errorf("variant = %q: ожидается одно из %q", p.Variant, Variants)
+	case !(p.Width > 2*margin && p.Height > 2*margin):
+		return errors.New("размер холста должен превышать удвоенный отступ вершин от края")
+	}
+	return p.validateVariant()
+}
+
+// validateVariant проверяет только параметры выбранного варианта: остальные он не читает,
+// и запрос AS с ranks = 0 не должен отклоняться
+func (p Params) validateVariant() error {
+	switch p.Variant {
+	case Elitist:
+		if !(p.ElitistWeight >= 0) || math.IsInf(p.ElitistWeight, 1) {
+			return fmt.Errorf("elitistWeight = %v: вес элитного пути должен быть конечным неотрицательным", p.ElitistWeight)
+		}
+	case RankBased:
+		if p.Ranks < 2 {
+			return fmt.Errorf("ranks = %d: нужно не меньше двух рангов", p.Ranks)
+		}
+	case MaxMin:
+		switch {
+		case !(p.TauMin >= 0) || !(p.TauMax >= 0) || math.IsInf(p.TauMin, 1) || math.IsInf(p.TauMax, 1):
+			return fmt.Errorf("tauMin = %v, tauMax = %v: границы феромона должны быть конечными неотрицательными", p.TauMin, p.TauMax)
+		case p.TauMin > 0 && p.TauMax > 0 && p.TauMin >= p.TauMax:
+			return fmt.Errorf("tauMin = %v не меньше tauMax = %v", p.TauMin, p.TauMax)
+		case p.RestartAfter < 0:
+			return fmt.Errorf("restartAfter = %d: число итераций не может быть отрицательным", p.RestartAfter)
+		}
+	case ColonySystem:
+		switch {
+		case !(p.Q0 >= 0 && p.Q0 <= 1):
+			return fmt.Errorf("q0 = %v: доля жадных переходов должна лежать в [0, 1]", p.Q0)
+		case !(p.Xi >= 0 && p.Xi <= 1):
+			return fmt.Errorf("xi = %v: локальное испарение должно лежать в [0, 1]", p.Xi)
+		}
+	}
+	return nil
+}
+
+// Point — координаты вершины на холсте
+type Point struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// Graph — граф колонии: вершины на холсте, старт и цель. Edges заполнен только у неполного
+// графа из файла; в полном, как в сгенерированном, ребро есть между любыми двумя вершинами
+type Graph struct {
+	Nodes    []Point `json:"nodes"`
+	Start    int     `json:"start"`
+	End      int     `json:"end"`
+	Directed bool    `json:"directed,omitempty"`
+	Edges    []Edge  `json:"edges,omitempty"`
+}
+
+// Colony — состояние симуляции: граф, феромоны и лучшие найденные пути
+type Colony struct {
+	params Params
+	rng    *PRNG
+
+	nodes      []Point
+	distances  [][]float64
+	pheromones [][]float64
+	start, end int
+
+	// Граф из файла: ребра неполного графа и ориентированность, которую нельзя снять параметрами
+	edges    []Edge
+	directed bool
+	// optimum — известная длина оптимального тура графа из файла, 0 — неизвестна
+	optimum float64
+
+	bestPath          []int
+	bestLength        float64
+	currentBestPath   []int
+	currentBestLength float64
+	iteration         int
+	// improved — итерация, после которой последний раз улучшился лучший путь; по ней MMAS
+	// решает, пора ли перезапуститься
+	improved int
+
+	// Буферы для построения путей, чтобы не выделять память на каждом шаге
+	visited       []bool
+	candidates    []int
+	probabilities []float64
+}
+
+// New строит граф по параметрам p, как generateGraph в aco.js
+func New(p Params) (*Colony, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+
+	c := newColony(p)
+	c.generateGraph()
+	c.reset()
+	return c, nil
+}
+
+// NewOnInstance запускает колонию на графе из файла. Число вершин и тип графа берутся
+// из inst, длины ребер — веса из файла; вершины вписываются в холст только для отрисовки.
+// Пары без ребра получают бесконечное расстояние, и муравьи по ним не ходят
+func NewOnInstance(p Params, inst *Instance) (*Colony, error) {
+	if err := inst.Validate(); err != nil {
+		return nil, err
+	}
+	p = inst.Apply(p)
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+
+	c := newColony(p)
+	c.nodes = fitCanvas(inst.Nodes, p.Width, p.Height)
+	c.distances = inst.weights()
+	c.start, c.end = inst.Start, inst.End
+	c.directed = inst.Directed
+	c.optimum = inst.Optimum
+	if !complete(c.distances) {
+		c.edges = slices.Clone(inst.Edges)
+	}
+	c.reset()
+	return c, nil
+}
+
+func newColony(p Params) *Colony {
+	return &Colony{
+		params:        p,
+		rng:           NewPRNG(p.Seed),
+		visited:       make([]bool, p.NodeCount),
+		candidates:    make([]int, 0, p.NodeCount),
+		probabilities: make([]float64, 0, p.NodeCount),
+	}
+}
+
+// generateGraph размещает вершины, выбирает старт и цель и заполняет матрицу расстояний
+func (c *Colony) generateGraph() {
+	n := c.params.NodeCount
+	c.nodes = make([]Point, n)
+	for i := range c.nodes {
+		c.nodes[i].X = float64(c.rng.Float64()*(c.params.Width-2*margin)) + margin
+		c.nodes[i].Y = float64(c.rng.Float64()*(c.params.Height-2*margin)) + margin
+	}
+
+	// Старт и цель — самые удаленные друг от друга вершины
+	maxDistance := 0.0
+	c.start, c.end = 0, 1
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			if d := c.dist(i, j); d > maxDistance {
+				maxDistance = d
+				c.start, c.end = i, j
+			}
+		}
+	}
+
+	c.distances = make([][]float64, n)
+	for i := range c.distances {
+		c.distances[i] = make([]float64, n)
+	}
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			if i != j {
+				c.distances[i][j] = c.dist(i, j)
+			}
+		}
+	}
+}
+
+// reset заполняет феромон значением tau0 и сбрасывает лучшие пути
+func (c *Colony) reset() {
+	n := c.params.NodeCount
+	c.pheromones = make([][]float64, n)
+	for i := range c.pheromones {
+		c.pheromones[i] = make([]float64, n)
+		for j := range c.pheromones[i] {
+			c.pheromones[i][j] = c.params.Tau0
+		}
+	}
+
+	c.bestPath = nil
+	c.bestLength = math.Inf(1)
+	c.currentBestPath = nil
+	c.currentBestLength = math.Inf(1)
+	c.iteration = 0
+	c.improved = 0
+}
+
+func (c *Colony) dist(i, j int) float64 {
+	return jsmath.Hypot(c.nodes[i].X-c.nodes[j].X, c.nodes[i].Y-c.nodes[j].Y)
+}
+
+// Step выполняет одну итерацию: каждый муравей строит путь, затем феромон обновляется
+// по правилу варианта Variant. Возвращает false, если бюджет итераций исчерпан
+func (c *Colony) Step() bool {
+	if c.iteration >= c.params.MaxIterations {
+		return false
+	}
+
+	paths := make([][]int, c.params.ColonySize)
+	lengths := make([]float64, c.params.ColonySize)
+	for ant := range paths {
+		paths[ant] = c.constructPath(c.startingNode())
+		lengths[ant] = c.pathLength(paths[ant])
+	}
+
+	// Лучший путь итерации выбирается только среди решивших задачу
+	iterationBest := -1
+	iterationBestLength := math.Inf(1)
+	for i, path := range paths {
+		if c.solved(path) && lengths[i] < iterationBestLength {
+			iterationBestLength = lengths[i]
+			iterationBest = i
+		}
+	}
+	if iterationBest != -1 {
+		c.currentBestPath = paths[iterationBest]
+		c.currentBestLength = iterationBestLength
+		if iterationBestLength < c.bestLength {
+			c.bestLength = iterationBestLength
+			c.bestPath = append([]int(nil), paths[iterationBest]...)
+			c.improved = c.iteration + 1
+		}
+	}
+
+	c.updatePheromones(paths, lengths)
+	c.iteration++
+	return true
+}
+
+// evaporate испаряет феромон на всех ребрах, не опуская его ниже minPheromone
+func (c *Colony) evaporate() {
+	for _, row := range c.pheromones {
+		for j := range row {
+			row[j] *= 1 - c.params.Rho
+			if row[j] < minPheromone {
+				row[j] = minPheromone
+			}
+		}
+	}
+}
+
+// rewarded сообщает, откладывает ли муравей феромон: он решил задачу, и длина его пути
+// конечна и положительна. У тура подкрепляется и замыкающее ребро; пустой путь — лучший
+// путь до первого успеха — не подкрепляется
+func (c *Colony) rewarded(path []int, length float64) bool {
+	return len(path) > 0 && c.solved(path) && !math.IsInf(length, 1) && length > 0
+}
+
+// deposit добавляет amount на ребра пути, в неориентированном графе в обе стороны
+func (c *Colony) deposit(path []int, amount float64) {
+	for i := 0; i < len(path)-1; i++ {
+		from, to := path[i], path[i+1]
+		c.pheromones[from][to] += amount
+		if c.params.GraphType == Undirected {
+			c.pheromones[to][from] += amount
+		}
+	}
+}
+
+// Run выполняет итерации до исчерпания бюджета
+func (c *Colony) Run() {
+	for c.Step() {
+	}
+}
+
+// startingNode выбирает вершину, из которой стартует очередной муравей
+func (c *Colony) startingNode() int {
+	if c.params.StartDist == Uniform {
+		return int(math.Floor(c.rng.Float64() * float64(c.params.NodeCount)))
+	}
+	return c.start
+}
+
+// constructPath строит путь муравья из from методом рулетки: до цели или, в режиме Tour,
+// через все вершины с возвратом в from. Пары без ребра (бесконечное расстояние в графе
+// из файла) в рулетку не попадают
+func (c *Colony) constructPath(from int) []int {
+	n := c.params.NodeCount
+	tour := c.params.Objective == Tour
+	clear(c.visited)
+	path := []int{from}
+	c.visited[from] = true
+	current := from
+
+	for (tour || current != c.end) && len(path) < n {
+		c.candidates = c.candidates[:0]
+		c.probabilities = c.probabilities[:0]
+		total := 0.0
+		for j := 0; j < n; j++ {
+			if c.visited[j] || math.IsInf(c.distances[current][j], 1) {
+				continue
+			}
+			tau := jsmath.Pow(max(c.pheromones[current][j], minPheromone), c.params.Alpha)
+			eta := jsmath.Pow(1/max(c.distances[current][j], minPheromone), c.params.Beta)
+			probability := tau * eta
+			if !math.IsInf(probability, 0) && !math.IsNaN(probability) && probability > 0 {
+				c.candidates = append(c.candidates, j)
+				c.probabilities = append(c.probabilities, probability)
+				total += probability
+			}
+		}
+		if len(c.candidates) == 0 {
+			break
+		}
+
+		var chosen int
+		if c.params.Variant == ColonySystem && c.rng.Float64() < c.params.Q0 {
+			chosen = c.greedyCandidate()
+		} else {
+			random := c.rng.Float64() * total
+			accumulated := 0.0
+			chosen = c.candidates[len(c.candidates)-1]
+			for i, probability := range c.probabilities {
+				accumulated += probability
+				if random <= accumulated {
+					chosen = c.candidates[i]
+					break
+				}
+			}
+		}
+
+		c.localUpdate(current, chosen)
+		path = append(path, chosen)
+		c.visited[chosen] = true
+		current = chosen
+	}
+
+	// Тур замыкается, если муравей обошел все вершины и из последней есть ребро в первую
+	if tour && len(path) == n && !math.IsInf(c.distances[current][from], 1) {
+		c.localUpdate(current, from)
+		path = append(path, from)
+	}
+	return path
+}
+
+// solved сообщает, решил ли муравей задачу: дошел до цели или замкнул тур через все вершины
+func (c *Colony) solved(path []int) bool {
+	if c.params.Objective == Tour {
+		return len(path) == c.params.NodeCount+1
+	}
+	return path[len(path)-1] == c.end
+}
+
+// pathLength возвращает длину пути; у пути из одной вершины длина бесконечна
+func (c *Colony) pathLength(path []int) float64 {
+	if len(path) < 2 {
+		return math.Inf(1)
+	}
+	length := 0.0
+	for i := 0; i < len(path)-1; i++ {
+		length += c.distances[path[i]][path[i+1]]
+	}
+	return length
+}
+
+// Params возвращает параметры колонии
+func (c *Colony) Params() Params { return c.params }
+
+// SetParams меняет параметры посреди прогона, как updateParams в aco.js. Граф остается
+// прежним, поэтому nodeCount, seed и размер холста так не меняются — для них нужна новая
+// колония, как и для смены задачи objective. Новое tau0 заново заполняет феромон на всех ребрах
+func (c *Colony) SetParams(p Params) error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	old := c.params
+	if p.NodeCount != old.NodeCount || p.Seed != old.Seed || p.Width != old.Width || p.Height != old.Height {
+		return errors.New("nodeCount, seed, width и height задают граф: их смена требует новой колонии")
+	}
+	if p.Objective != old.Objective {
+		return errors.New("objective меняет задачу, и найденные решения теряют смысл: нужна новая колония")
+	}
+	if c.directed && p.GraphType != Directed {
+		return errors.New("граф из файла ориентированный: graphType должен быть directed")
+	}
+
+	c.params = p
+	if p.Tau0 != old.Tau0 {
+		for i, row := range c.pheromones {
+			for j := range row {
+				if i != j {
+					row[j] = p.Tau0
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Nodes возвращает координаты вершин
+func (c *Colony) Nodes() []Point { return c.nodes }
+
+// Endpoints возвращает начальную и конечную вершины
+func (c *Colony) Endpoints() (start, end int) { return c.start, c.end }
+
+// Graph возвращает копию графа колонии
+func (c *Colony) Graph() Graph {
+	return Graph{Nodes: slices.Clone(c.nodes), Start: c.start, End: c.end, Directed: c.directed, Edges: slices.Clone(c.edges)}
+}
+
+// Iteration возвращает число выполненных итераций
+func (c *Colony) Iteration() int { return c.iteration }
+
+// Best возвращает лучший путь за все итерации и его длину; до первого успешного пути — nil и +Inf
+func (c *Colony) Best() ([]int, float64) { return c.bestPath, c.bestLength }
+
+// CurrentBest возвращает лучший путь последней итерации, в которой кто-то решил задачу.
+// Тур записывается замкнутым: первая вершина повторяется в конце
+func (c *Colony) CurrentBest() ([]int, float64) { return c.currentBestPath, c.currentBestLength }
+
+// Optimum возвращает известную длину оптимального тура графа из файла
+func (c *Colony) Optimum() (float64, bool) { return c.optimum, c.optimum > 0 }
+
+// Gap возвращает отставание лучшего тура от оптимума в процентах, (L − L*) / L* · 100.
+// ok = false вне режима Tour, без известного оптимума и пока ни один тур не замкнут
+func (c *Colony) Gap() (gap float64, ok bool) {
+	if c.params.Objective != Tour || c.optimum <= 0 || math.IsInf(c.bestLength, 1) {
+		return 0, false
+	}
+	return (c.bestLength - c.optimum) / c.optimum * 100, true
+}
+
+// Pheromone возвращает концентрацию феромона на ребре (i, j)
+func (c *Colony) Pheromone(i, j int) float64 { return c.pheromones[i][j] }
+
+// Distance возвращает длину ребра (i, j)
+func (c *Colony) Distance(i, j int) float64 { return c.distances[i][j] }
@@ -0,0 +1,183 @@
+package aco
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ParseCSV читает граф из CSV, где первое поле строки задает ее вид:
+//
+//	node,id,x,y    вершина с координатами
+//	edge,u,v[,w]   неориентированное ребро
+//	arc,u,v[,w]    дуга; хотя бы одна дуга делает граф ориентированным
+//	start,id       начальная вершина
+//	end,id         целевая вершина
+//	optimum,L      известная длина оптимального тура для оценки отставания
+//
+// Идентификаторы вершин — произвольные строки, вершины нумеруются в порядке появления.
+// Без веса ребро получает евклидово расстояние между вершинами, без ребер граф полный
+// евклидов. Строки с # — комментарии; первая строка может быть заголовком kind,...
+func ParseCSV(r io.Reader) (*Instance, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.Comment = '#'
+	reader.TrimLeadingSpace = true
+
+	inst := &Instance{Format: "csv"}
+	index := map[string]int{}
+	var labels []string
+	node := func(label string) int {
+		if i, ok := index[label]; ok {
+			return i
+		}
+		index[label] = len(labels)
+		labels = append(labels, label)
+		return len(labels) - 1
+	}
+
+	type rawEdge struct {
+		u, v     int
+		weight   float64
+		weighted bool
+		arc      bool
+	}
+	var (
+		coords       = map[int]Point{}
+		edges        []rawEdge
+		start, end   string
+		first        = true
+		needsWeights bool
+	)
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		kind := strings.ToLower(strings.TrimSpace(record[0]))
+		if first && kind == "kind" {
+			first = false
+			continue
+		}
+		first = false
+
+		switch kind {
+		case "node":
+			if len(record) != 4 {
+				return nil, fmt.Errorf("строка %d: ожидается node,id,x,y", line)
+			}
+			x, errX := strconv.ParseFloat(strings.TrimSpace(record[2]), 64)
+			y, errY := strconv.ParseFloat(strings.TrimSpace(record[3]), 64)
+			if errX != nil || errY != nil || math.IsInf(x, 0) || math.IsInf(y, 0) {
+				return nil, fmt.Errorf("строка %d: координаты %q %q не числа", line, record[2], record[3])
+			}
+			i := node(strings.TrimSpace(record[1]))
+			if _, ok := coords[i]; ok {
+				return nil, fmt.Errorf("строка %d: вершина %q описана повторно", line, record[1])
+			}
+			coords[i] = Point{X: x, Y: y}
+		case "edge", "arc":
+			if len(record) != 3 && len(record) != 4 {
+				return nil, fmt.Errorf("строка %d: ожидается %s,u,v[,w]", line, kind)
+			}
+			e := rawEdge{u: node(strings.TrimSpace(record[1])), v: node(strings.TrimSpace(record[2])), arc: kind == "arc"}
+			if len(record) == 4 && strings.TrimSpace(record[3]) != "" {
+				e.weight, err = strconv.ParseFloat(strings.TrimSpace(record[3]), 64)
+				if err != nil || !(e.weight >= 0) || math.IsInf(e.weight, 1) {
+					return nil, fmt.Errorf("строка %d: вес %q, ожидается конечное неотрицательное число", line, record[3])
+				}
+				e.weighted = true
+			} else {
+				needsWeights = true
+			}
+			if e.u != e.v {
+				edges = append(edges, e)
+			}
+		case "start":
+			if len(record) != 2 {
+				return nil, fmt.Errorf("строка %d: ожидается start,id", line)
+			}
+			start = strings.TrimSpace(record[1])
+		case "end":
+			if len(record) != 2 {
+				return nil, fmt.Errorf("строка %d: ожидается end,id", line)
+			}
+			end = strings.TrimSpace(record[1])
+		case "optimum":
+			if len(record) != 2 {
+				return nil, fmt.Errorf("строка %d: ожидается optimum,L", line)
+			}
+			if inst.Optimum, err = strconv.ParseFloat(strings.TrimSpace(record[1]), 64); err != nil {
+				return nil, fmt.Errorf("строка %d: optimum %q не число", line, record[1])
+			}
+		default:
+			return nil, fmt.Errorf("строка %d: неизвестный вид строки %q, ожидается node, edge, arc, start, end или optimum", line, record[0])
+		}
+	}
+
+	n := len(labels)
+	if n > MaxInstanceNodes {
+		return nil, fmt.Errorf("%d вершин, поддерживается не больше %d", n, MaxInstanceNodes)
+	}
+	hasCoordinates := len(coords) == n
+	if (needsWeights || len(edges) == 0) && !hasCoordinates {
+		return nil, errors.New("ребра без веса и граф без ребер требуют координат у всех вершин")
+	}
+	inst.Nodes = make([]Point, n)
+	for i, p := range coords {
+		inst.Nodes[i] = p
+	}
+	euclidean := func(u, v int) float64 {
+		return math.Hypot(inst.Nodes[u].X-inst.Nodes[v].X, inst.Nodes[u].Y-inst.Nodes[v].Y)
+	}
+
+	if len(edges) == 0 {
+		for u := range n {
+			for v := u + 1; v < n; v++ {
+				inst.Edges = append(inst.Edges, Edge{From: u, To: v, Weight: euclidean(u, v)})
+			}
+		}
+	}
+	for _, e := range edges {
+		inst.Directed = inst.Directed || e.arc
+	}
+	for _, e := range edges {
+		w := e.weight
+		if !e.weighted {
+			w = euclidean(e.u, e.v)
+		}
+		inst.Edges = append(inst.Edges, Edge{From: e.u, To: e.v, Weight: w})
+		if inst.Directed && !e.arc {
+			inst.Edges = append(inst.Edges, Edge{From: e.v, To: e.u, Weight: w})
+		}
+	}
+
+	if err := inst.finish(hasCoordinates); err != nil {
+		return nil, err
+	}
+	for _, endpoint := range []struct {
+		label  string
+		target *int
+	}{{start, &inst.Start}, {end, &inst.End}} {
+		if endpoint.label == "" {
+			continue
+		}
+		i, ok := index[endpoint.label]
+		if !ok {
+			return nil, fmt.Errorf("вершина %q из start или end не встречается в графе", endpoint.label)
+		}
+		*endpoint.target = i
+	}
+	if err := inst.Validate(); err != nil {
+		return nil, err
+	}
+	return inst, nil
+}
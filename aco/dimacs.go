@@ -0,0 +1,158 @@
+package aco
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Разбор формата DIMACS: задачи кратчайшего пути (p sp n m, дуги «a u v w»)
+// и неориентированные графы (p edge n m, ребра «e u v [w]», без веса — 1).
+// Вершины нумеруются с единицы, строки «c» — комментарии
+
+// ParseDIMACS читает граф DIMACS. Дуги задачи sp, каждая из которых продублирована обратной
+// с тем же весом, как в дорожных графах 9th DIMACS Challenge, сворачиваются в неориентированные ребра.
+// Петли отбрасываются
+func ParseDIMACS(r io.Reader) (*Instance, error) {
+	scanner := bufio.NewScanner(r)
+	inst := &Instance{Format: "dimacs"}
+	problem := ""
+	n, m := 0, 0
+	weights := map[[2]int]float64{}
+	count := 0
+
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "c":
+			if inst.Comment == "" {
+				inst.Comment = strings.Join(fields[1:], " ")
+			}
+		case "p":
+			if problem != "" {
+				return nil, fmt.Errorf("строка %d: повторная строка p", line)
+			}
+			if len(fields) != 4 {
+				return nil, fmt.Errorf("строка %d: ожидается «p sp n m» или «p edge n m»", line)
+			}
+			problem = fields[1]
+			if problem != "sp" && problem != "edge" {
+				return nil, fmt.Errorf("строка %d: задача %q не поддерживается, ожидается sp или edge", line, problem)
+			}
+			var errN, errM error
+			n, errN = strconv.Atoi(fields[2])
+			m, errM = strconv.Atoi(fields[3])
+			if errN != nil || errM != nil || n < 2 || n > MaxInstanceNodes || m < 0 {
+				return nil, fmt.Errorf("строка %d: %s вершин и %s ребер, вершин должно быть от 2 до %d", line, fields[2], fields[3], MaxInstanceNodes)
+			}
+		case "a", "e":
+			if (fields[0] == "a") != (problem == "sp") {
+				return nil, fmt.Errorf("строка %d: строка %s не подходит к задаче %q", line, fields[0], problem)
+			}
+			if len(fields) < 3 || len(fields) > 4 || fields[0] == "a" && len(fields) != 4 {
+				return nil, fmt.Errorf("строка %d: ожидается «a u v w» или «e u v [w]»", line)
+			}
+			u, errU := strconv.Atoi(fields[1])
+			v, errV := strconv.Atoi(fields[2])
+			if errU != nil || errV != nil || u < 1 || u > n || v < 1 || v > n {
+				return nil, fmt.Errorf("строка %d: вершины %s и %s вне 1..%d", line, fields[1], fields[2], n)
+			}
+			w := 1.0
+			if len(fields) == 4 {
+				var err error
+				if w, err = strconv.ParseFloat(fields[3], 64); err != nil || w < 0 {
+					return nil, fmt.Errorf("строка %d: вес %q, ожидается неотрицательное число", line, fields[3])
+				}
+			}
+			count++
+			if u == v {
+				continue
+			}
+			key := [2]int{u - 1, v - 1}
+			if fields[0] == "e" && key[0] > key[1] {
+				key[0], key[1] = key[1], key[0]
+			}
+			if old, ok := weights[key]; !ok || w < old {
+				weights[key] = w
+			}
+		default:
+			return nil, fmt.Errorf("строка %d: неизвестная строка %q", line, fields[0])
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if problem == "" {
+		return nil, fmt.Errorf("нет строки p")
+	}
+	if count != m {
+		return nil, fmt.Errorf("в строке p заявлено %d ребер, прочитано %d", m, count)
+	}
+
+	if problem == "sp" {
+		for key, w := range weights {
+			if back, ok := weights[[2]int{key[1], key[0]}]; !ok || back != w {
+				inst.Directed = true
+				break
+			}
+		}
+	}
+	for u := range n {
+		for v := range n {
+			w, ok := weights[[2]int{u, v}]
+			if ok && (inst.Directed || u < v || problem == "edge") {
+				inst.Edges = append(inst.Edges, Edge{From: u, To: v, Weight: w})
+			}
+		}
+	}
+
+	inst.Nodes = make([]Point, n)
+	if err := inst.finish(false); err != nil {
+		return nil, err
+	}
+	return inst, nil
+}
+
+// ReadDIMACSCoordinates читает координаты вершин из файла .co («v id x y»)
+// и заменяет ими расстановку по окружности
+func ReadDIMACSCoordinates(r io.Reader, inst *Instance) error {
+	scanner := bufio.NewScanner(r)
+	nodes := make([]Point, len(inst.Nodes))
+	seen := make([]bool, len(nodes))
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || fields[0] == "c" || fields[0] == "p" {
+			continue
+		}
+		if fields[0] != "v" || len(fields) != 4 {
+			return fmt.Errorf("строка %d: ожидается «v id x y»", line)
+		}
+		id, err := strconv.Atoi(fields[1])
+		if err != nil || id < 1 || id > len(nodes) {
+			return fmt.Errorf("строка %d: вершина %s вне 1..%d", line, fields[1], len(nodes))
+		}
+		x, errX := strconv.ParseFloat(fields[2], 64)
+		y, errY := strconv.ParseFloat(fields[3], 64)
+		if errX != nil || errY != nil {
+			return fmt.Errorf("строка %d: координаты %q %q не числа", line, fields[2], fields[3])
+		}
+		nodes[id-1] = Point{X: x, Y: y}
+		seen[id-1] = true
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	for i, ok := range seen {
+		if !ok {
+			return fmt.Errorf("нет координат вершины %d", i+1)
+		}
+	}
+	inst.Nodes = nodes
+	inst.Layout = false
+	return nil
+}
@@ -0,0 +1,314 @@
+package aco
+
+import (
+	"container/heap"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// MaxInstanceNodes ограничивает граф из файла: колония хранит матрицы расстояний
+// и феромона n×n, а полный граф на тысячу вершин — это полмиллиона ребер в JSON для страницы
+const MaxInstanceNodes = 1000
+
+// Edge — ребро графа из файла; в JSON записывается как [from, to, weight]
+type Edge struct {
+	From, To int
+	Weight   float64
+}
+
+func (e Edge) MarshalJSON() ([]byte, error) {
+	return json.Marshal([3]float64{float64(e.From), float64(e.To), e.Weight})
+}
+
+func (e *Edge) UnmarshalJSON(data []byte) error {
+	var triple [3]float64
+	if err := json.Unmarshal(data, &triple); err != nil {
+		return fmt.Errorf("ребро: ожидается [from, to, weight]: %w", err)
+	}
+	if triple[0] != math.Trunc(triple[0]) || triple[1] != math.Trunc(triple[1]) {
+		return fmt.Errorf("ребро %v: номера вершин должны быть целыми", triple)
+	}
+	*e = Edge{int(triple[0]), int(triple[1]), triple[2]}
+	return nil
+}
+
+// Instance — граф из файла TSPLIB, DIMACS или CSV. Вершины нумеруются с нуля; координаты
+// нужны только для отрисовки, ось y в них направлена вверх. В неориентированном графе каждое
+// ребро записано один раз, в ориентированном — каждая дуга. Пары без ребра недостижимы напрямую
+type Instance struct {
+	Name    string `json:"name"`
+	Comment string `json:"comment,omitempty"`
+	Format  string `json:"format"`
+
+	Nodes []Point `json:"nodes"`
+	// Layout — в файле нет координат, и вершины расставлены по окружности
+	Layout bool `json:"layout,omitempty"`
+
+	Directed bool   `json:"directed"`
+	Edges    []Edge `json:"edges"`
+
+	Start int `json:"start"`
+	End   int `json:"end"`
+
+	// Optimum — длина оптимального тура, если она известна (задачи TSPLIB, строка optimum в CSV)
+	Optimum float64 `json:"optimum,omitempty"`
+}
+
+// LoadInstance читает граф, выбирая формат по расширению: .tsp и .atsp — TSPLIB,
+// .gr — DIMACS (координаты берутся из одноименного .co, если он есть), .csv — CSV
+func LoadInstance(path string) (*Instance, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("чтение графа: %w", err)
+	}
+	defer file.Close()
+
+	ext := strings.ToLower(filepath.Ext(path))
+	var inst *Instance
+	switch ext {
+	case ".tsp", ".atsp":
+		inst, err = ParseTSPLIB(file)
+	case ".gr":
+		inst, err = ParseDIMACS(file)
+		if err == nil {
+			err = loadDIMACSCoordinates(strings.TrimSuffix(path, filepath.Ext(path))+".co", inst)
+		}
+	case ".csv":
+		inst, err = ParseCSV(file)
+	default:
+		return nil, fmt.Errorf("%s: неизвестный формат графа, ожидается .tsp, .atsp, .gr или .csv", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if inst.Name == "" {
+		inst.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	return inst, nil
+}
+
+func loadDIMACSCoordinates(path string, inst *Instance) error {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if err := ReadDIMACSCoordinates(file, inst); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// Validate проверяет номера вершин, веса ребер и старт с целью
+func (inst *Instance) Validate() error {
+	n := len(inst.Nodes)
+	switch {
+	case n < 2:
+		return fmt.Errorf("граф %s: нужно не меньше двух вершин", inst.Name)
+	case n > MaxInstanceNodes:
+		return fmt.Errorf("граф %s: %d вершин, поддерживается не больше %d", inst.Name, n, MaxInstanceNodes)
+	case inst.Start < 0 || inst.Start >= n || inst.End < 0 || inst.End >= n || inst.Start == inst.End:
+		return fmt.Errorf("граф %s: старт %d и цель %d должны быть разными вершинами из %d", inst.Name, inst.Start, inst.End, n)
+	}
+	for _, e := range inst.Edges {
+		switch {
+		case e.From < 0 || e.From >= n || e.To < 0 || e.To >= n || e.From == e.To:
+			return fmt.Errorf("граф %s: ребро (%d, %d) вне вершин 0..%d", inst.Name, e.From, e.To, n-1)
+		case !(e.Weight >= 0) || math.IsInf(e.Weight, 1):
+			return fmt.Errorf("граф %s: вес ребра (%d, %d) = %v, ожидается конечное неотрицательное число", inst.Name, e.From, e.To, e.Weight)
+		}
+	}
+	if !(inst.Optimum >= 0) || math.IsInf(inst.Optimum, 1) {
+		return fmt.Errorf("граф %s: optimum = %v, ожидается конечное неотрицательное число", inst.Name, inst.Optimum)
+	}
+	return nil
+}
+
+// Apply возвращает параметры колонии на этом графе: число вершин берется из графа,
+// а ориентированный граф подкрепляется только по направлению дуг
+func (inst *Instance) Apply(p Params) Params {
+	p.NodeCount = len(inst.Nodes)
+	if inst.Directed {
+		p.GraphType = Directed
+	}
+	return p
+}
+
+// Complete сообщает, есть ли ребро между любыми двумя вершинами
+func (inst *Instance) Complete() bool { return complete(inst.weights()) }
+
+func complete(w [][]float64) bool {
+	for i, row := range w {
+		for j, weight := range row {
+			if i != j && math.IsInf(weight, 1) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// weights строит матрицу весов; недостающие ребра бесконечны, из кратных берется легчайшее
+func (inst *Instance) weights() [][]float64 {
+	n := len(inst.Nodes)
+	w := make([][]float64, n)
+	for i := range w {
+		w[i] = make([]float64, n)
+		for j := range w[i] {
+			if i != j {
+				w[i][j] = math.Inf(1)
+			}
+		}
+	}
+	for _, e := range inst.Edges {
+		w[e.From][e.To] = min(w[e.From][e.To], e.Weight)
+		if !inst.Directed {
+			w[e.To][e.From] = min(w[e.To][e.From], e.Weight)
+		}
+	}
+	return w
+}
+
+// finish проверяет граф после разбора, расставляет вершины без координат по окружности
+// и выбирает старт и цель
+func (inst *Instance) finish(hasCoordinates bool) error {
+	if !hasCoordinates {
+		inst.Layout = true
+		n := float64(len(inst.Nodes))
+		for i := range inst.Nodes {
+			angle := 2 * math.Pi * float64(i) / n
+			inst.Nodes[i] = Point{X: math.Cos(angle), Y: math.Sin(angle)}
+		}
+	}
+	if len(inst.Nodes) < 2 {
+		return errors.New("нужно не меньше двух вершин")
+	}
+	if len(inst.Nodes) > MaxInstanceNodes {
+		return fmt.Errorf("%d вершин, поддерживается не больше %d", len(inst.Nodes), MaxInstanceNodes)
+	}
+	if err := inst.pickEndpoints(); err != nil {
+		return err
+	}
+	return inst.Validate()
+}
+
+// pickEndpoints выбирает самую удаленную пару, как generateGraph в aco.js. В полном графе
+// это концы самого длинного ребра, в разреженном — самый длинный из кратчайших путей
+// между достижимыми друг из друга вершинами
+func (inst *Instance) pickEndpoints() error {
+	w := inst.weights()
+	n := len(w)
+	if complete(w) {
+		longest := -1.0
+		for i := 0; i < n; i++ {
+			for j := 0; j < n; j++ {
+				if (inst.Directed || j > i) && i != j && w[i][j] > longest {
+					longest = w[i][j]
+					inst.Start, inst.End = i, j
+				}
+			}
+		}
+		return nil
+	}
+
+	adjacency := make([][]Edge, n)
+	for i, row := range w {
+		for j, weight := range row {
+			if i != j && !math.IsInf(weight, 1) {
+				adjacency[i] = append(adjacency[i], Edge{From: i, To: j, Weight: weight})
+			}
+		}
+	}
+	longest := -1.0
+	for source := range n {
+		dist := shortestPaths(adjacency, source)
+		for target, d := range dist {
+			if target != source && !math.IsInf(d, 1) && d > longest {
+				longest = d
+				inst.Start, inst.End = source, target
+			}
+		}
+	}
+	if longest < 0 {
+		return errors.New("в графе нет ни одного ребра")
+	}
+	return nil
+}
+
+// shortestPaths — алгоритм Дейкстры из source; недостижимые вершины получают +Inf
+func shortestPaths(adjacency [][]Edge, source int) []float64 {
+	dist := make([]float64, len(adjacency))
+	for i := range dist {
+		dist[i] = math.Inf(1)
+	}
+	dist[source] = 0
+	queue := &distanceHeap{{source, 0}}
+	for queue.Len() > 0 {
+		item := heap.Pop(queue).(queued)
+		if item.dist > dist[item.node] {
+			continue
+		}
+		for _, e := range adjacency[item.node] {
+			if d := item.dist + e.Weight; d < dist[e.To] {
+				dist[e.To] = d
+				heap.Push(queue, queued{e.To, d})
+			}
+		}
+	}
+	return dist
+}
+
+type queued struct {
+	node int
+	dist float64
+}
+
+// distanceHeap — очередь вершин по возрастанию расстояния для container/heap
+type distanceHeap []queued
+
+func (h distanceHeap) Len() int           { return len(h) }
+func (h distanceHeap) Less(i, j int) bool { return h[i].dist < h[j].dist }
+func (h distanceHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *distanceHeap) Push(x any)        { *h = append(*h, x.(queued)) }
+func (h *distanceHeap) Pop() any {
+	old := *h
+	item := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return item
+}
+
+// fitCanvas вписывает координаты из файла в холст с отступом margin, сохраняя пропорции
+// и центрируя рисунок; ось y переворачивается, потому что на холсте она направлена вниз.
+// Так же вершины загруженного графа размещает loadGraph в aco.js
+func fitCanvas(points []Point, width, height float64) []Point {
+	minX, maxX := math.Inf(1), math.Inf(-1)
+	minY, maxY := math.Inf(1), math.Inf(-1)
+	for _, p := range points {
+		minX, maxX = min(minX, p.X), max(maxX, p.X)
+		minY, maxY = min(minY, p.Y), max(maxY, p.Y)
+	}
+	spanX, spanY := maxX-minX, maxY-minY
+	if spanX == 0 {
+		spanX = 1
+	}
+	if spanY == 0 {
+		spanY = 1
+	}
+	scale := min((width-2*margin)/spanX, (height-2*margin)/spanY)
+	offsetX := margin + (width-2*margin-(maxX-minX)*scale)/2
+	offsetY := margin + (height-2*margin-(maxY-minY)*scale)/2
+
+	fitted := make([]Point, len(points))
+	for i, p := range points {
+		fitted[i] = Point{X: offsetX + (p.X-minX)*scale, Y: height - offsetY - (p.Y-minY)*scale}
+	}
+	return fitted
+}
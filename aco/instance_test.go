@@ -0,0 +1,330 @@
+package aco
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// tsplib собирает файл TSPLIB из заголовка и секций
+func tsplib(lines ...string) string { return strings.Join(lines, "\n") + "\n" }
+
+func TestParseTSPLIBExplicit(t *testing.T) {
+	// Каждый формат треугольника описывает одну и ту же симметричную матрицу 3×3
+	symmetric := [][]float64{{0, 1, 2}, {1, 0, 3}, {2, 3, 0}}
+	tests := []struct {
+		format  string
+		weights string
+		want    [][]float64
+	}{
+		{"FULL_MATRIX", "0 1 2\n3 0 4\n5 6 0", [][]float64{{0, 1, 2}, {3, 0, 4}, {5, 6, 0}}},
+		{"UPPER_ROW", "1 2\n3", symmetric},
+		{"LOWER_ROW", "1\n2 3", symmetric},
+		{"UPPER_DIAG_ROW", "0 1 2\n0 3\n0", symmetric},
+		{"LOWER_DIAG_ROW", "0\n1 0\n2 3 0", symmetric},
+		{"UPPER_COL", "1\n2 3", symmetric},
+		{"LOWER_COL", "1 2\n3", symmetric},
+		{"UPPER_DIAG_COL", "0\n1 0\n2 3 0", symmetric},
+		{"LOWER_DIAG_COL", "0 1 2\n0 3\n0", symmetric},
+	}
+	for _, test := range tests {
+		t.Run(test.format, func(t *testing.T) {
+			inst, err := ParseTSPLIB(strings.NewReader(tsplib("NAME: m3", "TYPE: TSP", "DIMENSION: 3",
+				"EDGE_WEIGHT_TYPE: EXPLICIT", "EDGE_WEIGHT_FORMAT: "+test.format, "EDGE_WEIGHT_SECTION", test.weights, "EOF")))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := inst.weights(); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("матрица %v, ожидалась %v", got, test.want)
+			}
+			if want := test.format == "FULL_MATRIX"; inst.Directed != want {
+				t.Errorf("directed = %v, ожидалось %v", inst.Directed, want)
+			}
+			if !inst.Layout {
+				t.Error("без координат вершины должны быть расставлены по окружности")
+			}
+		})
+	}
+}
+
+func TestTSPLIBDistance(t *testing.T) {
+	tests := []struct {
+		weightType string
+		a, b       Point
+		want       float64
+	}{
+		{"EUC_2D", Point{0, 0}, Point{3, 4}, 5},
+		{"EUC_2D", Point{0, 0}, Point{1, 1}, 1},           // nint(1.414)
+		{"EUC_2D", Point{0, 0}, Point{1.5, 2}, 3},         // nint(2.5) округляет вверх
+		{"CEIL_2D", Point{0, 0}, Point{1, 1}, 2},          // ceil(1.414)
+		{"CEIL_2D", Point{0, 0}, Point{3, 4}, 5},          // целое не округляется
+		{"ATT", Point{0, 0}, Point{3, 4}, 2},              // r = 1.58, nint(r) = 2 ≥ r
+		{"ATT", Point{0, 0}, Point{10, 0}, 4},             // r = 3.16, nint(r) = 3 < r
+		{"ATT", Point{6734, 1453}, Point{2233, 10}, 1495}, // att48, вершины 1 и 2
+		// burma14, вершины 1–2 и 1–3: «градусы.минуты», пи = 3.141592, отсечение после + 1
+		{"GEO", Point{16.47, 96.10}, Point{16.47, 94.44}, 153},
+		{"GEO", Point{16.47, 96.10}, Point{20.09, 92.54}, 510},
+	}
+	for _, test := range tests {
+		if got := tsplibDistance(test.weightType, test.a, test.b); got != test.want {
+			t.Errorf("%s %v–%v = %v, ожидалось %v", test.weightType, test.a, test.b, got, test.want)
+		}
+	}
+}
+
+func TestBurma14Optimum(t *testing.T) {
+	inst, err := LoadInstance("../static/graphs/burma14.tsp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inst.Directed || inst.Layout || len(inst.Nodes) != 14 {
+		t.Fatalf("directed = %v, layout = %v, %d вершин", inst.Directed, inst.Layout, len(inst.Nodes))
+	}
+	// Для отрисовки x — долгота, y — широта
+	if inst.Nodes[0] != (Point{X: 96.10, Y: 16.47}) {
+		t.Errorf("вершина 1 в %v, ожидалось (96.10, 16.47)", inst.Nodes[0])
+	}
+
+	// Первая строка опубликованной матрицы burma14
+	w := inst.weights()
+	row := []float64{0, 153, 510, 706, 966, 581, 455, 70, 160, 372, 157, 567, 342, 398}
+	if !reflect.DeepEqual(w[0], row) {
+		t.Errorf("строка 1 матрицы %v, ожидалась %v", w[0], row)
+	}
+
+	// Опубликованный оптимальный тур, вершины с единицы
+	tour := []int{1, 2, 14, 3, 4, 5, 6, 12, 7, 13, 8, 11, 9, 10}
+	length := 0.0
+	for k, v := range tour {
+		length += w[v-1][tour[(k+1)%len(tour)]-1]
+	}
+	optimum, ok := KnownOptimum(inst.Name)
+	if !ok || length != 3323 || inst.Optimum != 3323 || optimum != 3323 {
+		t.Errorf("тур %v, optimum = %v, KnownOptimum = %v %v; ожидалось 3323", length, inst.Optimum, optimum, ok)
+	}
+}
+
+func TestParseTSPLIBErrors(t *testing.T) {
+	coords := []string{"NODE_COORD_SECTION", "1 0 0", "2 3 4", "3 6 8"}
+	header := func(lines ...string) []string {
+		return append([]string{"NAME: t", "TYPE: TSP", "DIMENSION: 3", "EDGE_WEIGHT_TYPE: EUC_2D"}, lines...)
+	}
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"нет DIMENSION", tsplib("TYPE: TSP", "EDGE_WEIGHT_TYPE: EUC_2D", "EOF"), "нет ключа DIMENSION"},
+		{"секция до DIMENSION", tsplib(append([]string{"TYPE: TSP", "EDGE_WEIGHT_TYPE: EUC_2D"}, coords...)...), "строка 3: секция NODE_COORD_SECTION до DIMENSION"},
+		{"DIMENSION не число", tsplib("TYPE: TSP", "DIMENSION: три"), `строка 2: DIMENSION = "три"`},
+		{"DIMENSION меньше 2", tsplib("TYPE: TSP", "DIMENSION: 1"), `строка 2: DIMENSION = "1"`},
+		{"нет TYPE", tsplib("DIMENSION: 3", "EDGE_WEIGHT_TYPE: EUC_2D", "EOF"), "нет ключа TYPE"},
+		{"TYPE не TSP", tsplib("TYPE: HCP", "DIMENSION: 3", "EOF"), "TYPE = HCP"},
+		{"нет EDGE_WEIGHT_TYPE", tsplib("TYPE: TSP", "DIMENSION: 3", "EOF"), "нет ключа EDGE_WEIGHT_TYPE"},
+		{"неизвестный EDGE_WEIGHT_TYPE", tsplib("TYPE: TSP", "DIMENSION: 3", "EDGE_WEIGHT_TYPE: MAN_2D", "EOF"), "EDGE_WEIGHT_TYPE = MAN_2D"},
+		{"нет координат", tsplib(header("EOF")...), "EDGE_WEIGHT_TYPE = EUC_2D требует NODE_COORD_SECTION"},
+		{"координаты не всех вершин", tsplib(header(coords[:3]...)...), "нет координат вершины 3"},
+		{"номер вершины 0", tsplib(header("NODE_COORD_SECTION", "0 1 1")...), `строка 6: номер вершины "0" вне 1..3`},
+		{"номер вершины больше DIMENSION", tsplib(header("NODE_COORD_SECTION", "4 1 1")...), `строка 6: номер вершины "4" вне 1..3`},
+		{"строка координат без y", tsplib(header("NODE_COORD_SECTION", "1 0")...), "строка 6: ожидается «номер x y»"},
+		{"координата не число", tsplib(header("NODE_COORD_SECTION", "1 0 x")...), "строка 6: координаты"},
+		{"данные вне секции", tsplib(header("1 0 0")...), "строка 5: данные вне секции"},
+		{"неподдерживаемая секция", tsplib(header("FIXED_EDGES_SECTION")...), "секция FIXED_EDGES_SECTION не поддерживается"},
+		{"NODE_COORD_TYPE", tsplib(header("NODE_COORD_TYPE: THREED_COORDS")...), "NODE_COORD_TYPE = THREED_COORDS"},
+		{"EXPLICIT без формата", tsplib("TYPE: TSP", "DIMENSION: 3", "EDGE_WEIGHT_TYPE: EXPLICIT", "EDGE_WEIGHT_SECTION", "1 2 3", "EOF"), "требует EDGE_WEIGHT_FORMAT"},
+		{"неизвестный формат", tsplib("TYPE: TSP", "DIMENSION: 3", "EDGE_WEIGHT_TYPE: EXPLICIT", "EDGE_WEIGHT_FORMAT: FUNCTION", "EOF"), "EDGE_WEIGHT_FORMAT = FUNCTION не поддерживается"},
+		{"весов меньше", tsplib("TYPE: TSP", "DIMENSION: 3", "EDGE_WEIGHT_TYPE: EXPLICIT", "EDGE_WEIGHT_FORMAT: UPPER_ROW", "EDGE_WEIGHT_SECTION", "1 2", "EOF"), "2 весов, для UPPER_ROW на 3 вершинах нужно больше"},
+		{"весов больше", tsplib("TYPE: TSP", "DIMENSION: 3", "EDGE_WEIGHT_TYPE: EXPLICIT", "EDGE_WEIGHT_FORMAT: UPPER_ROW", "EDGE_WEIGHT_SECTION", "1 2 3 4", "EOF"), "4 весов, для UPPER_ROW на 3 вершинах нужно 3"},
+		{"вес не число", tsplib("TYPE: TSP", "DIMENSION: 3", "EDGE_WEIGHT_TYPE: EXPLICIT", "EDGE_WEIGHT_FORMAT: UPPER_ROW", "EDGE_WEIGHT_SECTION", "1 x 3"), `строка 6: вес "x" не число`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := ParseTSPLIB(strings.NewReader(test.text))
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Errorf("ошибка %v, ожидалась %q", err, test.want)
+			}
+		})
+	}
+}
+
+func TestParseDIMACS(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		directed bool
+		edges    []Edge
+	}{
+		{"sp с обратными дугами — неориентированный", "c дороги\np sp 3 4\na 1 2 5\na 2 1 5\na 2 3 7\na 3 2 7\n", false,
+			[]Edge{{0, 1, 5}, {1, 2, 7}}},
+		{"sp без обратной дуги — ориентированный", "p sp 3 3\na 1 2 5\na 2 1 5\na 2 3 7\n", true,
+			[]Edge{{0, 1, 5}, {1, 0, 5}, {1, 2, 7}}},
+		{"edge без веса", "p edge 3 2\ne 1 2\ne 3 2 4\n", false, []Edge{{0, 1, 1}, {1, 2, 4}}},
+		{"петли и кратные ребра", "p edge 3 4\ne 1 1 9\ne 1 2 3\ne 2 1 2\ne 2 3\n", false, []Edge{{0, 1, 2}, {1, 2, 1}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			inst, err := ParseDIMACS(strings.NewReader(test.text))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if inst.Directed != test.directed || !reflect.DeepEqual(inst.Edges, test.edges) {
+				t.Errorf("directed = %v, ребра %v; ожидалось %v, %v", inst.Directed, inst.Edges, test.directed, test.edges)
+			}
+		})
+	}
+}
+
+func TestParseDIMACSErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"нет строки p", "c только комментарий\n", "нет строки p"},
+		{"повторная строка p", "p sp 2 0\np sp 2 0\n", "строка 2: повторная строка p"},
+		{"неизвестная задача", "p max 2 1\n", `строка 1: задача "max" не поддерживается`},
+		{"одна вершина", "p sp 1 0\n", "строка 1: 1 вершин и 0 ребер"},
+		{"вершина 0", "p sp 2 1\na 0 1 1\n", "строка 2: вершины 0 и 1 вне 1..2"},
+		{"вершина больше n", "p edge 2 1\ne 1 3\n", "строка 2: вершины 1 и 3 вне 1..2"},
+		{"дуга в задаче edge", "p edge 2 1\na 1 2 1\n", "строка 2: строка a не подходит"},
+		{"дуга без веса", "p sp 2 1\na 1 2\n", "строка 2: ожидается «a u v w»"},
+		{"отрицательный вес", "p sp 2 1\na 1 2 -1\n", `строка 2: вес "-1"`},
+		{"число ребер", "p sp 2 2\na 1 2 1\n", "заявлено 2 ребер, прочитано 1"},
+		{"неизвестная строка", "p sp 2 0\nx 1\n", `строка 2: неизвестная строка "x"`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := ParseDIMACS(strings.NewReader(test.text))
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Errorf("ошибка %v, ожидалась %q", err, test.want)
+			}
+		})
+	}
+}
+
+func TestReadDIMACSCoordinates(t *testing.T) {
+	inst, err := ParseDIMACS(strings.NewReader("p sp 2 2\na 1 2 1\na 2 1 1\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"вершина вне графа", "v 3 0 0\n", "строка 1: вершина 3 вне 1..2"},
+		{"нет вершины", "c\nv 1 0 0\n", "нет координат вершины 2"},
+		{"не строка v", "x 1 0 0\n", "строка 1: ожидается «v id x y»"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := ReadDIMACSCoordinates(strings.NewReader(test.text), inst)
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Errorf("ошибка %v, ожидалась %q", err, test.want)
+			}
+		})
+	}
+
+	if err := ReadDIMACSCoordinates(strings.NewReader("p aux sp co 2\nv 2 3 4\nv 1 1 2\n"), inst); err != nil {
+		t.Fatal(err)
+	}
+	if want := []Point{{1, 2}, {3, 4}}; inst.Layout || !reflect.DeepEqual(inst.Nodes, want) {
+		t.Errorf("layout = %v, вершины %v; ожидалось %v", inst.Layout, inst.Nodes, want)
+	}
+}
+
+func TestParseCSV(t *testing.T) {
+	tests := []struct {
+		name       string
+		text       string
+		directed   bool
+		edges      []Edge
+		start, end int
+	}{
+		{"полный евклидов граф", "kind,id,x,y\nnode,a,0,0\nnode,b,3,4\nnode,c,0,4\n", false,
+			[]Edge{{0, 1, 5}, {0, 2, 4}, {1, 2, 3}}, 0, 1},
+		{"ребра с весом и без", "# граф\nnode,a,0,0\nnode,b,3,4\nnode,c,0,4\nedge,a,b,10\nedge,b,c\nstart,c\nend,a\n", false,
+			[]Edge{{0, 1, 10}, {1, 2, 3}}, 2, 0},
+		{"дуга делает граф ориентированным", "edge,x,y,2\narc,y,z,3\n", true,
+			[]Edge{{0, 1, 2}, {1, 0, 2}, {1, 2, 3}}, 0, 2},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			inst, err := ParseCSV(strings.NewReader(test.text))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if inst.Directed != test.directed || !reflect.DeepEqual(inst.Edges, test.edges) {
+				t.Errorf("directed = %v, ребра %v; ожидалось %v, %v", inst.Directed, inst.Edges, test.directed, test.edges)
+			}
+			if inst.Start != test.start || inst.End != test.end {
+				t.Errorf("старт %d, цель %d; ожидалось %d, %d", inst.Start, inst.End, test.start, test.end)
+			}
+		})
+	}
+}
+
+func TestParseCSVErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"неизвестный вид строки", "vertex,a,0,0\n", `строка 1: неизвестный вид строки "vertex"`},
+		{"node без y", "node,a,0\n", "строка 1: ожидается node,id,x,y"},
+		{"координата не число", "node,a,0,y\n", "строка 1: координаты"},
+		{"повтор вершины", "node,a,0,0\nnode,a,1,1\n", `строка 2: вершина "a" описана повторно`},
+		{"вес не число", "edge,a,b,w\n", `строка 1: вес "w", ожидается конечное неотрицательное число`},
+		{"ребро без веса и координат", "edge,a,b\n", "требуют координат у всех вершин"},
+		{"start вне графа", "edge,a,b,1\nstart,z\n", `вершина "z" из start или end не встречается`},
+		{"одна вершина", "node,a,0,0\n", "нужно не меньше двух вершин"},
+		{"отрицательный вес", "edge,a,b,-1\n", `строка 1: вес "-1"`},
+		{"бесконечный вес", "edge,a,b,inf\n", `строка 1: вес "inf"`},
+		{"optimum не число", "optimum,x\n", `строка 1: optimum "x" не число`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := ParseCSV(strings.NewReader(test.text))
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Errorf("ошибка %v, ожидалась %q", err, test.want)
+			}
+		})
+	}
+}
+
+func TestKnownOptimum(t *testing.T) {
+	tests := []struct {
+		name    string
+		optimum float64
+		ok      bool
+	}{
+		{"burma14", 3323, true},
+		{"burma14.tsp", 3323, true},
+		{"br17.atsp", 39, true},
+		{"ulysses16.TSP", 6859, true},
+		{"burma14.csv", 0, false},
+		{"Burma14", 0, false},
+		{"", 0, false},
+	}
+	for _, test := range tests {
+		if optimum, ok := KnownOptimum(test.name); optimum != test.optimum || ok != test.ok {
+			t.Errorf("KnownOptimum(%q) = %v, %v; ожидалось %v, %v", test.name, optimum, ok, test.optimum, test.ok)
+		}
+	}
+}
+
+func TestLoadInstanceFiles(t *testing.T) {
+	entries, err := os.ReadDir("../static/graphs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		if strings.HasSuffix(entry.Name(), ".co") {
+			continue
+		}
+		if _, err := LoadInstance("../static/graphs/" + entry.Name()); err != nil {
+			t.Errorf("%s: %v", entry.Name(), err)
+		}
+	}
+}
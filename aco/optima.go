@@ -0,0 +1,119 @@
+package aco
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// knownOptima — длины оптимальных туров задач TSPLIB до MaxInstanceNodes вершин
+// из опубликованного списка решений (симметричные задачи и ATSP)
+var knownOptima = map[string]float64{
+	"a280":      2579,
+	"ali535":    202339,
+	"att48":     10628,
+	"att532":    27686,
+	"bayg29":    1610,
+	"bays29":    2020,
+	"berlin52":  7542,
+	"bier127":   118282,
+	"brazil58":  25395,
+	"brg180":    1950,
+	"burma14":   3323,
+	"ch130":     6110,
+	"ch150":     6528,
+	"d198":      15780,
+	"d493":      35002,
+	"d657":      48912,
+	"dantzig42": 699,
+	"dsj1000":   18659688,
+	"eil101":    629,
+	"eil51":     426,
+	"eil76":     538,
+	"fl417":     11861,
+	"fri26":     937,
+	"gil262":    2378,
+	"gr120":     6942,
+	"gr137":     69853,
+	"gr17":      2085,
+	"gr202":     40160,
+	"gr21":      2707,
+	"gr229":     134602,
+	"gr24":      1272,
+	"gr431":     171414,
+	"gr48":      5046,
+	"gr666":     294358,
+	"gr96":      55209,
+	"hk48":      11461,
+	"kroA100":   21282,
+	"kroA150":   26524,
+	"kroA200":   29368,
+	"kroB100":   22141,
+	"kroB150":   26130,
+	"kroB200":   29437,
+	"kroC100":   20749,
+	"kroD100":   21294,
+	"kroE100":   22068,
+	"lin105":    14379,
+	"lin318":    42029,
+	"p654":      34643,
+	"pa561":     2763,
+	"pcb442":    50778,
+	"pr107":     44303,
+	"pr124":     59030,
+	"pr136":     96772,
+	"pr144":     58537,
+	"pr152":     73682,
+	"pr226":     80369,
+	"pr264":     49135,
+	"pr299":     48191,
+	"pr439":     107217,
+	"pr76":      108159,
+	"rat195":    2323,
+	"rat575":    6773,
+	"rat783":    8806,
+	"rat99":     1211,
+	"rd100":     7910,
+	"rd400":     15281,
+	"si175":     21407,
+	"si535":     48450,
+	"st70":      675,
+	"swiss42":   1273,
+	"ts225":     126643,
+	"tsp225":    3916,
+	"u159":      42080,
+	"u574":      36905,
+	"u724":      41910,
+	"ulysses16": 6859,
+	"ulysses22": 7013,
+
+	"br17":    39,
+	"ft53":    6905,
+	"ft70":    38673,
+	"ftv33":   1286,
+	"ftv35":   1473,
+	"ftv38":   1530,
+	"ftv44":   1613,
+	"ftv47":   1776,
+	"ftv55":   1608,
+	"ftv64":   1839,
+	"ftv70":   1950,
+	"ftv170":  2755,
+	"kro124p": 36230,
+	"p43":     5620,
+	"rbg323":  1326,
+	"rbg358":  1163,
+	"rbg403":  2465,
+	"rbg443":  2720,
+	"ry48p":   14422,
+}
+
+// KnownOptimum возвращает длину оптимального тура задачи TSPLIB по ее имени;
+// расширение .tsp или .atsp в имени допускается, как в NAME некоторых файлов
+func KnownOptimum(name string) (float64, bool) {
+	switch ext := filepath.Ext(name); strings.ToLower(ext) {
+	case ".tsp", ".atsp":
+		name = strings.TrimSuffix(name, ext)
+	}
+	optimum, ok := knownOptima[name]
+	return optimum, ok
+}
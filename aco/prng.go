@@ -0,0 +1,26 @@
+package aco
+
+import "math"
+
+// PRNG — линейный конгруэнтный генератор из aco.js: seed = (seed*9301 + 49297) mod 233280.
+// Состояние хранится в float64, как число в JavaScript, поэтому последовательности совпадают
+// и для отрицательных или дробных начальных значений
+type PRNG struct {
+	seed float64
+}
+
+// NewPRNG создает генератор с начальным значением seed
+func NewPRNG(seed float64) *PRNG {
+	return &PRNG{seed: seed}
+}
+
+// Float64 возвращает следующее число из [0, 1)
+func (p *PRNG) Float64() float64 {
+	p.seed = math.Mod(float64(p.seed*9301)+49297, 233280)
+	return p.seed / 233280
+}
+
+// Seed возвращает текущее состояние генератора
+func (p *PRNG) Seed() float64 {
+	return p.seed
+}
@@ -0,0 +1,123 @@
+// Записывает трассы static/js/aco.js для проверки aco.Trace.Verify и манифесты,
+// сохраненные страницей, для проверки manifest.Manifest.Replay.
+//
+//   node aco/testdata/record.mjs [каталог]
+//
+// Скрипт подключает модуль страницы без изменений в логике: к возвращаемому объекту
+// добавляется только функция snapshot, читающая состояние алгоритма. Холст и DOM заменены
+// заглушками, поэтому draw и updateInfo ничего не рисуют и не трогают генератор
+import { mkdirSync, readFileSync, writeFileSync } from 'node:fs';
+import { dirname, join } from 'node:path';
+import { fileURLToPath } from 'node:url';
+
+const here = dirname(fileURLToPath(import.meta.url));
+const outDir = process.argv[2] ?? here;
+
+const source = readFileSync(join(here, '../../static/js/aco.js'), 'utf8');
+const marker = /return \{\s*\n\s*params,\s*\n\s*updateNodeCount,\s*\n\s*updateParams,\s*\n\s*createUI,/;
+if (!marker.test(source)) {
+  throw new Error('aco.js: не найден объект, который возвращает initAnts');
+}
+const instrumented = source.replace(marker, (match) => `return {
+    snapshot: () => ({
+      nodes: nodes.map(({ x, y }) => ({ x, y })),
+      start: startNode,
+      end: endNode,
+      iteration,
+      bestPath: bestPath && bestPath.slice(),
+      bestLength,
+      currentBestPath: currentBestPath && currentBestPath.slice(),
+      currentBestLength,
+      pheromones: pheromones.map((row) => row.slice()),
+    }),` + match.slice('return {'.length));
+
+globalThis.document = { getElementById: () => null };
+globalThis.window = { addEventListener() {} };
+
+const { initAnts } = await import('data:text/javascript;base64,' + Buffer.from(instrumented).toString('base64'));
+
+function canvasStub(width, height) {
+  const ctx = new Proxy({}, {
+    get: (target, key) => (key in target ? target[key] : () => ctx),
+    set: (target, key, value) => { target[key] = value; return true; },
+  });
+  return { clientWidth: width, clientHeight: height, width: 0, height: 0, getContext: () => ctx };
+}
+
+// Параметры страницы templates/aco.html
+const pageDefaults = {
+  nodeCount: 10, alpha: 1, beta: 2, rho: 0.5, Q: 1, colonySize: 10, maxIterations: 100,
+  tau0: 1, graphType: 'undirected', startDist: 'fixed', seed: 42, width: 1280, height: 720,
+};
+
+const scenarios = {
+  defaults: { ...pageDefaults },
+  directed_uniform: {
+    ...pageDefaults, graphType: 'directed', startDist: 'uniform',
+    alpha: 1.3, beta: 3.7, rho: 0.15, Q: 2.5, tau0: 0.4, seed: 1234, width: 1024, height: 640,
+  },
+  large: {
+    ...pageDefaults, nodeCount: 25, colonySize: 40, maxIterations: 60,
+    alpha: 2.2, beta: 0.6, rho: 0.03, seed: 9999, width: 1920, height: 1080,
+  },
+  no_bias: { ...pageDefaults, alpha: 0, beta: 0, rho: 1, seed: 0, maxIterations: 30 },
+  strong_evaporation: { ...pageDefaults, nodeCount: 15, alpha: 5, beta: 10, rho: 0.99, Q: 1000, seed: 7, tau0: 1e-6 },
+  tour: { ...pageDefaults, objective: 'tour', nodeCount: 12, startDist: 'uniform', rho: 0.1, Q: 100, seed: 5, maxIterations: 60 },
+};
+
+for (const [name, params] of Object.entries(scenarios)) {
+  const { width, height, ...options } = params;
+  const colony = initAnts(canvasStub(width, height), { ...options, isPreview: true });
+  colony.reset();
+
+  const first = colony.snapshot();
+  const steps = [];
+  for (let i = 0; i < params.maxIterations; i++) {
+    colony.step();
+    const { iteration, bestPath, bestLength, currentBestPath, currentBestLength } = colony.snapshot();
+    steps.push({ iteration, bestPath, bestLength, currentBestPath, currentBestLength });
+  }
+
+  const trace = {
+    algorithm: 'aco',
+    params,
+    nodes: first.nodes,
+    start: first.start,
+    end: first.end,
+    steps,
+    pheromones: colony.snapshot().pheromones,
+  };
+  writeFileSync(join(outDir, `${name}.json`), JSON.stringify(trace) + '\n');
+  console.log(`${name}: ${steps.length} итераций, лучший путь ${steps.at(-1).bestLength}`);
+}
+
+// Манифесты страницы: посреди прогона окно меняет размер, вершины на холсте масштабируются,
+// а манифест должен хранить граф и холст на момент построения
+mkdirSync(join(outDir, 'manifests'), { recursive: true });
+for (const name of ['defaults', 'directed_uniform']) {
+  const { width, height, ...options } = scenarios[name];
+  const colony = initAnts(canvasStub(width, height), { ...options, isPreview: true });
+  colony.reset();
+  for (let i = 0; i < 25; i++) colony.step();
+  Object.assign(globalThis.window, { innerWidth: Math.round(width * 0.75), innerHeight: Math.round(height * 0.6) });
+  colony.handleResize();
+  for (let i = 0; i < 10; i++) colony.step();
+
+  // Время сохранения не пишется, чтобы повторная запись не меняла файлы
+  const { created, ...manifest } = colony.exportManifest();
+  writeFileSync(join(outDir, 'manifests', `${name}.json`), JSON.stringify(manifest, null, 2) + '\n');
+  console.log(`manifests/${name}: ${manifest.steps} итераций, лучший путь ${manifest.result.bestLength}`);
+}
+
+// Манифест прогона на графе из файла: граф записан в instance целиком. Файл
+// instances/burma14.json — ответ GET /api/aco/graphs/burma14
+{
+  const { width, height, ...options } = scenarios.tour;
+  const colony = initAnts(canvasStub(width, height), { ...options, isPreview: true });
+  colony.loadGraph(JSON.parse(readFileSync(join(here, 'instances', 'burma14.json'), 'utf8')));
+  for (let i = 0; i < 30; i++) colony.step();
+
+  const { created, ...manifest } = colony.exportManifest();
+  writeFileSync(join(outDir, 'manifests', 'burma14.json'), JSON.stringify(manifest, null, 2) + '\n');
+  console.log(`manifests/burma14: ${manifest.steps} итераций, лучший путь ${manifest.result.bestLength}`);
+}
@@ -0,0 +1,171 @@
+package aco
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"slices"
+)
+
+// Algorithm — имя алгоритма в трассах
+const Algorithm = "aco"
+
+// Length — длина пути в трассе. Бесконечность записывается в JSON как null,
+// потому что JSON.stringify в браузере поступает с Infinity так же
+type Length float64
+
+func (l Length) MarshalJSON() ([]byte, error) {
+	if math.IsInf(float64(l), 0) || math.IsNaN(float64(l)) {
+		return []byte("null"), nil
+	}
+	return json.Marshal(float64(l))
+}
+
+func (l *Length) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*l = Length(math.Inf(1))
+		return nil
+	}
+	return json.Unmarshal(data, (*float64)(l))
+}
+
+// Snapshot — состояние колонии после одной итерации
+type Snapshot struct {
+	Iteration         int    `json:"iteration"`
+	BestPath          []int  `json:"bestPath"`
+	BestLength        Length `json:"bestLength"`
+	CurrentBestPath   []int  `json:"currentBestPath"`
+	CurrentBestLength Length `json:"currentBestLength"`
+}
+
+// Trace — запись прогона: граф, состояние после каждой итерации и итоговые феромоны.
+// Трассы браузера записывает aco/testdata/record.mjs
+type Trace struct {
+	Algorithm  string      `json:"algorithm"`
+	Params     Params      `json:"params"`
+	Nodes      []Point     `json:"nodes"`
+	Start      int         `json:"start"`
+	End        int         `json:"end"`
+	Steps      []Snapshot  `json:"steps"`
+	Pheromones [][]float64 `json:"pheromones"`
+}
+
+// Record выполняет steps итераций с параметрами p и возвращает трассу прогона
+func Record(p Params, steps int) (Trace, error) {
+	c, err := New(p)
+	if err != nil {
+		return Trace{}, err
+	}
+	return record(c, steps), nil
+}
+
+// RecordOnInstance выполняет steps итераций на графе из файла. В трассу попадают
+// параметры с числом вершин и типом графа из inst
+func RecordOnInstance(p Params, inst *Instance, steps int) (Trace, error) {
+	c, err := NewOnInstance(p, inst)
+	if err != nil {
+		return Trace{}, err
+	}
+	return record(c, steps), nil
+}
+
+func record(c *Colony, steps int) Trace {
+	trace := Trace{Algorithm: Algorithm, Params: c.params, Nodes: slices.Clone(c.nodes), Start: c.start, End: c.end}
+	for range steps {
+		if !c.Step() {
+			break
+		}
+		trace.Steps = append(trace.Steps, Snapshot{
+			Iteration:         c.iteration,
+			BestPath:          slices.Clone(c.bestPath),
+			BestLength:        Length(c.bestLength),
+			CurrentBestPath:   slices.Clone(c.currentBestPath),
+			CurrentBestLength: Length(c.currentBestLength),
+		})
+	}
+	trace.Pheromones = make([][]float64, len(c.pheromones))
+	for i, row := range c.pheromones {
+		trace.Pheromones[i] = slices.Clone(row)
+	}
+
+	return trace
+}
+
+// LoadTrace читает трассу из JSON файла
+func LoadTrace(path string) (Trace, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Trace{}, fmt.Errorf("чтение трассы: %w", err)
+	}
+
+	// Параметры, которых нет в трассе (objective в трассах до режима туров), берутся по умолчанию
+	trace := Trace{Params: DefaultParams()}
+	if err := json.Unmarshal(data, &trace); err != nil {
+		return Trace{}, fmt.Errorf("разбор трассы %s: %w", path, err)
+	}
+	if trace.Algorithm != Algorithm {
+		return Trace{}, fmt.Errorf("трасса %s записана для %q, а не для %q", path, trace.Algorithm, Algorithm)
+	}
+	return trace, nil
+}
+
+// Verify повторяет прогон с параметрами трассы и возвращает первое расхождение.
+// Числа сравниваются точно, без допуска
+func (t Trace) Verify() error {
+	got, err := Record(t.Params, len(t.Steps))
+	if err != nil {
+		return err
+	}
+
+	if len(got.Nodes) != len(t.Nodes) {
+		return fmt.Errorf("число вершин: %d, ожидалось %d", len(got.Nodes), len(t.Nodes))
+	}
+	for i := range t.Nodes {
+		if got.Nodes[i] != t.Nodes[i] {
+			return fmt.Errorf("вершина %d: %v, ожидалось %v", i, got.Nodes[i], t.Nodes[i])
+		}
+	}
+	if got.Start != t.Start || got.End != t.End {
+		return fmt.Errorf("старт и цель: %d→%d, ожидалось %d→%d", got.Start, got.End, t.Start, t.End)
+	}
+
+	if len(got.Steps) != len(t.Steps) {
+		return fmt.Errorf("число итераций: %d, ожидалось %d", len(got.Steps), len(t.Steps))
+	}
+	for i, want := range t.Steps {
+		step := got.Steps[i]
+		switch {
+		case step.Iteration != want.Iteration:
+			return fmt.Errorf("шаг %d: номер итерации %d, ожидался %d", i+1, step.Iteration, want.Iteration)
+		case !slices.Equal(step.BestPath, want.BestPath) || !sameLength(step.BestLength, want.BestLength):
+			return fmt.Errorf("итерация %d: лучший путь %v длины %v, ожидался %v длины %v",
+				want.Iteration, step.BestPath, float64(step.BestLength), want.BestPath, float64(want.BestLength))
+		case !slices.Equal(step.CurrentBestPath, want.CurrentBestPath) || !sameLength(step.CurrentBestLength, want.CurrentBestLength):
+			return fmt.Errorf("итерация %d: путь итерации %v длины %v, ожидался %v длины %v",
+				want.Iteration, step.CurrentBestPath, float64(step.CurrentBestLength), want.CurrentBestPath, float64(want.CurrentBestLength))
+		}
+	}
+
+	if t.Pheromones != nil {
+		if len(t.Pheromones) != len(got.Pheromones) {
+			return fmt.Errorf("размер матрицы феромонов: %d, ожидался %d", len(got.Pheromones), len(t.Pheromones))
+		}
+		for i, row := range t.Pheromones {
+			if len(row) != len(got.Pheromones[i]) {
+				return fmt.Errorf("строка %d матрицы феромонов: %d элементов, ожидалось %d", i, len(got.Pheromones[i]), len(row))
+			}
+			for j, want := range row {
+				if got.Pheromones[i][j] != want {
+					return fmt.Errorf("феромон на ребре (%d, %d): %v, ожидалось %v", i, j, got.Pheromones[i][j], want)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func sameLength(a, b Length) bool {
+	return a == b || math.IsInf(float64(a), 1) && math.IsInf(float64(b), 1)
+}
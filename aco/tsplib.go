@@ -0,0 +1,324 @@
+package aco
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Разбор формата TSPLIB 95: заголовок из строк «КЛЮЧ : значение» и секции с данными.
+// Поддерживаются задачи TSP и ATSP с весами EUC_2D, CEIL_2D, ATT, GEO и EXPLICIT;
+// формулы расстояний и округление взяты из описания формата, так что длины туров
+// совпадают с опубликованными оптимумами (см. KnownOptimum)
+
+// tsplibHeader — ключи заголовка, от которых зависит разбор секций
+type tsplibHeader struct {
+	problem      string
+	dimension    int
+	weightType   string
+	weightFormat string
+	displayType  string
+}
+
+// ParseTSPLIB читает задачу TSPLIB. Координаты GEO — широта и долгота в градусах с минутами
+// после точки; для отрисовки x берется из долготы, y — из широты
+func ParseTSPLIB(r io.Reader) (*Instance, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	inst := &Instance{Format: "tsplib"}
+	var (
+		h        tsplibHeader
+		coords   []Point
+		display  []Point
+		explicit []float64
+		section  string
+	)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		// Строка с буквы — ключ заголовка или начало секции, иначе данные текущей секции
+		if c := text[0]; c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' {
+			key, value := splitTSPLIBKey(text)
+			section = ""
+			switch key {
+			case "EOF":
+				return finishTSPLIB(inst, h, coords, display, explicit)
+			case "NAME":
+				inst.Name = value
+			case "COMMENT":
+				inst.Comment = strings.TrimSpace(inst.Comment + " " + value)
+			case "TYPE":
+				h.problem = strings.ToUpper(strings.Fields(value + " ")[0])
+			case "DIMENSION":
+				n, err := strconv.Atoi(value)
+				if err != nil || n < 2 || n > MaxInstanceNodes {
+					return nil, fmt.Errorf("строка %d: DIMENSION = %q, ожидается целое от 2 до %d", line, value, MaxInstanceNodes)
+				}
+				h.dimension = n
+			case "EDGE_WEIGHT_TYPE":
+				h.weightType = strings.ToUpper(value)
+			case "EDGE_WEIGHT_FORMAT":
+				h.weightFormat = strings.ToUpper(value)
+			case "DISPLAY_DATA_TYPE":
+				h.displayType = strings.ToUpper(value)
+			case "NODE_COORD_TYPE":
+				if t := strings.ToUpper(value); t != "TWOD_COORDS" {
+					return nil, fmt.Errorf("строка %d: NODE_COORD_TYPE = %s, поддерживаются только TWOD_COORDS", line, t)
+				}
+			case "NODE_COORD_SECTION", "EDGE_WEIGHT_SECTION", "DISPLAY_DATA_SECTION":
+				if h.dimension == 0 {
+					return nil, fmt.Errorf("строка %d: секция %s до DIMENSION", line, key)
+				}
+				section = key
+			default:
+				if strings.HasSuffix(key, "_SECTION") {
+					return nil, fmt.Errorf("строка %d: секция %s не поддерживается", line, key)
+				}
+			}
+			continue
+		}
+
+		fields := strings.Fields(text)
+		switch section {
+		case "NODE_COORD_SECTION", "DISPLAY_DATA_SECTION":
+			if len(fields) != 3 {
+				return nil, fmt.Errorf("строка %d: ожидается «номер x y»", line)
+			}
+			id, err := strconv.Atoi(fields[0])
+			if err != nil || id < 1 || id > h.dimension {
+				return nil, fmt.Errorf("строка %d: номер вершины %q вне 1..%d", line, fields[0], h.dimension)
+			}
+			x, errX := strconv.ParseFloat(fields[1], 64)
+			y, errY := strconv.ParseFloat(fields[2], 64)
+			if errX != nil || errY != nil {
+				return nil, fmt.Errorf("строка %d: координаты %q %q не числа", line, fields[1], fields[2])
+			}
+			target := &coords
+			if section == "DISPLAY_DATA_SECTION" {
+				target = &display
+			}
+			if *target == nil {
+				*target = make([]Point, h.dimension)
+				for i := range *target {
+					(*target)[i] = Point{math.NaN(), math.NaN()}
+				}
+			}
+			(*target)[id-1] = Point{X: x, Y: y}
+		case "EDGE_WEIGHT_SECTION":
+			for _, field := range fields {
+				w, err := strconv.ParseFloat(field, 64)
+				if err != nil {
+					return nil, fmt.Errorf("строка %d: вес %q не число", line, field)
+				}
+				explicit = append(explicit, w)
+			}
+		default:
+			return nil, fmt.Errorf("строка %d: данные вне секции", line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return finishTSPLIB(inst, h, coords, display, explicit)
+}
+
+// splitTSPLIBKey делит строку заголовка на ключ и значение; двоеточие после ключа необязательно
+func splitTSPLIBKey(text string) (key, value string) {
+	if i := strings.IndexByte(text, ':'); i >= 0 {
+		return strings.ToUpper(strings.TrimSpace(text[:i])), strings.TrimSpace(text[i+1:])
+	}
+	key, value, _ = strings.Cut(text, " ")
+	return strings.ToUpper(key), strings.TrimSpace(value)
+}
+
+// finishTSPLIB собирает граф из прочитанных секций
+func finishTSPLIB(inst *Instance, h tsplibHeader, coords, display []Point, explicit []float64) (*Instance, error) {
+	switch h.problem {
+	case "TSP", "ATSP":
+	case "":
+		return nil, fmt.Errorf("нет ключа TYPE")
+	default:
+		return nil, fmt.Errorf("TYPE = %s: поддерживаются только TSP и ATSP", h.problem)
+	}
+	if h.dimension == 0 {
+		return nil, fmt.Errorf("нет ключа DIMENSION")
+	}
+	n := h.dimension
+	for _, points := range [][]Point{coords, display} {
+		for i, p := range points {
+			if math.IsNaN(p.X) {
+				return nil, fmt.Errorf("нет координат вершины %d", i+1)
+			}
+		}
+	}
+
+	var m [][]float64
+	switch h.weightType {
+	case "EUC_2D", "CEIL_2D", "ATT", "GEO":
+		if coords == nil {
+			return nil, fmt.Errorf("EDGE_WEIGHT_TYPE = %s требует NODE_COORD_SECTION", h.weightType)
+		}
+		m = make([][]float64, n)
+		for i := range m {
+			m[i] = make([]float64, n)
+			for j := range m[i] {
+				if i != j {
+					m[i][j] = tsplibDistance(h.weightType, coords[i], coords[j])
+				}
+			}
+		}
+	case "EXPLICIT":
+		var err error
+		if m, err = explicitMatrix(h.weightFormat, n, explicit); err != nil {
+			return nil, err
+		}
+	case "":
+		return nil, fmt.Errorf("нет ключа EDGE_WEIGHT_TYPE")
+	default:
+		return nil, fmt.Errorf("EDGE_WEIGHT_TYPE = %s: поддерживаются EUC_2D, CEIL_2D, ATT, GEO и EXPLICIT", h.weightType)
+	}
+
+	inst.Directed = h.problem == "ATSP" || !symmetric(m)
+	inst.Edges = matrixEdges(m, inst.Directed)
+	inst.Optimum, _ = KnownOptimum(inst.Name)
+
+	// Отрисовка: DISPLAY_DATA_SECTION, затем координаты узлов, иначе окружность
+	inst.Nodes = make([]Point, n)
+	hasCoordinates := true
+	switch {
+	case display != nil && h.displayType != "NO_DISPLAY":
+		copy(inst.Nodes, display)
+	case coords != nil && h.weightType == "GEO":
+		for i, p := range coords {
+			inst.Nodes[i] = Point{X: p.Y, Y: p.X}
+		}
+	case coords != nil:
+		copy(inst.Nodes, coords)
+	default:
+		hasCoordinates = false
+	}
+	if err := inst.finish(hasCoordinates); err != nil {
+		return nil, err
+	}
+	return inst, nil
+}
+
+// nint — округление до ближайшего целого из описания TSPLIB
+func nint(x float64) float64 { return math.Floor(x + 0.5) }
+
+// tsplibDistance вычисляет целочисленный вес ребра по координатам из файла
+func tsplibDistance(weightType string, a, b Point) float64 {
+	dx, dy := a.X-b.X, a.Y-b.Y
+	switch weightType {
+	case "CEIL_2D":
+		return math.Ceil(math.Sqrt(dx*dx + dy*dy))
+	case "ATT":
+		r := math.Sqrt((dx*dx + dy*dy) / 10)
+		t := nint(r)
+		if t < r {
+			t++
+		}
+		return t
+	case "GEO":
+		latA, lonA := geoRadians(a.X), geoRadians(a.Y)
+		latB, lonB := geoRadians(b.X), geoRadians(b.Y)
+		q1 := math.Cos(lonA - lonB)
+		q2 := math.Cos(latA - latB)
+		q3 := math.Cos(latA + latB)
+		const earthRadius = 6378.388
+		return math.Trunc(earthRadius*math.Acos(0.5*((1+q1)*q2-(1-q1)*q3)) + 1)
+	default:
+		return nint(math.Sqrt(dx*dx + dy*dy))
+	}
+}
+
+// geoRadians переводит «градусы.минуты» в радианы. Целая часть отбрасывается, а не
+// округляется, и число пи урезано до 3.141592, как в эталонных решателях: иначе длины
+// туров расходятся с опубликованными оптимумами
+func geoRadians(x float64) float64 {
+	const pi = 3.141592
+	deg := math.Trunc(x)
+	return pi * (deg + 5*(x-deg)/3) / 180
+}
+
+// explicitMatrix раскладывает веса EDGE_WEIGHT_SECTION в матрицу. Форматы *_COL
+// симметричной матрицы совпадают с *_ROW противоположного треугольника
+func explicitMatrix(format string, n int, values []float64) ([][]float64, error) {
+	var inRow func(i, j int) bool
+	switch format {
+	case "FULL_MATRIX":
+		inRow = func(i, j int) bool { return true }
+	case "UPPER_ROW", "LOWER_COL":
+		inRow = func(i, j int) bool { return j > i }
+	case "LOWER_ROW", "UPPER_COL":
+		inRow = func(i, j int) bool { return j < i }
+	case "UPPER_DIAG_ROW", "LOWER_DIAG_COL":
+		inRow = func(i, j int) bool { return j >= i }
+	case "LOWER_DIAG_ROW", "UPPER_DIAG_COL":
+		inRow = func(i, j int) bool { return j <= i }
+	case "":
+		return nil, fmt.Errorf("EDGE_WEIGHT_TYPE = EXPLICIT требует EDGE_WEIGHT_FORMAT")
+	default:
+		return nil, fmt.Errorf("EDGE_WEIGHT_FORMAT = %s не поддерживается", format)
+	}
+
+	m := make([][]float64, n)
+	for i := range m {
+		m[i] = make([]float64, n)
+	}
+	k := 0
+	for i := range n {
+		for j := range n {
+			if !inRow(i, j) {
+				continue
+			}
+			if k == len(values) {
+				return nil, fmt.Errorf("EDGE_WEIGHT_SECTION: %d весов, для %s на %d вершинах нужно больше", len(values), format, n)
+			}
+			m[i][j] = values[k]
+			if format != "FULL_MATRIX" {
+				m[j][i] = values[k]
+			}
+			k++
+		}
+	}
+	if k != len(values) {
+		return nil, fmt.Errorf("EDGE_WEIGHT_SECTION: %d весов, для %s на %d вершинах нужно %d", len(values), format, n, k)
+	}
+	for i := range m {
+		m[i][i] = 0
+	}
+	return m, nil
+}
+
+func symmetric(m [][]float64) bool {
+	for i := range m {
+		for j := range i {
+			if m[i][j] != m[j][i] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// matrixEdges превращает полную матрицу в список ребер: в неориентированном графе
+// по одному на пару i < j, в ориентированном — все дуги
+func matrixEdges(m [][]float64, directed bool) []Edge {
+	var edges []Edge
+	for i := range m {
+		for j := range m[i] {
+			if i != j && (directed || j > i) {
+				edges = append(edges, Edge{From: i, To: j, Weight: m[i][j]})
+			}
+		}
+	}
+	return edges
+}
@@ -0,0 +1,172 @@
+package aco
+
+import (
+	"cmp"
+	"math"
+	"slices"
+)
+
+// Variant — правило обновления феромона
+type Variant string
+
+const (
+	// AntSystem — исходная Ant System: испарение и подкрепление Q/L_k каждым муравьем,
+	// решившим задачу. Так работает страница aco.js
+	AntSystem Variant = "as"
+	// Elitist — элитная AS: к подкреплению AntSystem добавляется e·Q/L_bs на лучшем пути
+	Elitist Variant = "eas"
+	// RankBased — AS_rank: подкрепляют только w − 1 лучших муравьев итерации с весами
+	// w − r по рангу r и лучший путь с весом w
+	RankBased Variant = "rank"
+	// MaxMin — MAX–MIN AS: подкрепляет только лучший муравей итерации, феромон удерживается
+	// в [tauMin, tauMax], а после restartAfter итераций без улучшения сбрасывается до tauMax
+	MaxMin Variant = "mmas"
+	// ColonySystem — Ant Colony System: псевдослучайно-пропорциональный выбор с долей жадных
+	// переходов q0, локальное испарение на пройденных ребрах и глобальное — только на лучшем пути
+	ColonySystem Variant = "acs"
+)
+
+// Variants перечисляет варианты в порядке описания в aco.tex
+var Variants = []Variant{AntSystem, Elitist, RankBased, MaxMin, ColonySystem}
+
+// updatePheromones обновляет феромон после того, как все муравьи итерации построили пути
+func (c *Colony) updatePheromones(paths [][]int, lengths []float64) {
+	switch c.params.Variant {
+	case RankBased:
+		c.evaporate()
+		c.depositRanked(paths, lengths)
+	case MaxMin:
+		c.evaporate()
+		c.depositIterationBest(paths, lengths)
+		c.bound()
+	case ColonySystem:
+		c.depositGlobalBest()
+	default:
+		c.evaporate()
+		for ant, path := range paths {
+			if c.rewarded(path, lengths[ant]) {
+				c.deposit(path, c.params.Q/lengths[ant])
+			}
+		}
+		if c.params.Variant == Elitist && c.rewarded(c.bestPath, c.bestLength) {
+			c.deposit(c.bestPath, c.params.ElitistWeight*c.params.Q/c.bestLength)
+		}
+	}
+}
+
+// depositRanked подкрепляет пути w − 1 лучших муравьев итерации с весами w − 1, ..., 1
+// и лучший путь за все итерации с весом w. Муравьи с равной длиной идут по номеру
+func (c *Colony) depositRanked(paths [][]int, lengths []float64) {
+	var ranked []int
+	for ant, path := range paths {
+		if c.rewarded(path, lengths[ant]) {
+			ranked = append(ranked, ant)
+		}
+	}
+	slices.SortStableFunc(ranked, func(a, b int) int { return cmp.Compare(lengths[a], lengths[b]) })
+
+	w := c.params.Ranks
+	for r, ant := range ranked[:min(len(ranked), w-1)] {
+		c.deposit(paths[ant], float64(w-1-r)*c.params.Q/lengths[ant])
+	}
+	if c.rewarded(c.bestPath, c.bestLength) {
+		c.deposit(c.bestPath, float64(w)*c.params.Q/c.bestLength)
+	}
+}
+
+// depositIterationBest подкрепляет только лучший путь итерации, как в MMAS
+func (c *Colony) depositIterationBest(paths [][]int, lengths []float64) {
+	best := -1
+	for ant, path := range paths {
+		if c.rewarded(path, lengths[ant]) && (best == -1 || lengths[ant] < lengths[best]) {
+			best = ant
+		}
+	}
+	if best != -1 {
+		c.deposit(paths[best], c.params.Q/lengths[best])
+	}
+}
+
+// pheromoneBounds возвращает границы MMAS. Без явных tauMax и tauMin берутся
+// τmax = Q / (ρ·L_bs), предел феромона на лучшем пути, и τmin = τmax / (2n);
+// пока путь не найден, τmax = +Inf
+func (c *Colony) pheromoneBounds() (lo, hi float64) {
+	hi = c.params.TauMax
+	if hi == 0 {
+		hi = math.Inf(1)
+		if c.rewarded(c.bestPath, c.bestLength) {
+			hi = c.params.Q / (c.params.Rho * c.bestLength)
+		}
+	}
+	lo = c.params.TauMin
+	if lo == 0 && !math.IsInf(hi, 1) {
+		lo = hi / float64(2*c.params.NodeCount)
+	}
+	return max(lo, minPheromone), hi
+}
+
+// bound удерживает феромон MMAS в границах, а после restartAfter итераций без улучшения
+// лучшего пути заново заполняет его верхней границей (tau0, пока граница бесконечна).
+// Первое же ограничение сверху приводит начальный tau0 к τmax, как в исходном MMAS
+func (c *Colony) bound() {
+	lo, hi := c.pheromoneBounds()
+	restart := c.params.RestartAfter > 0 && c.iteration+1-c.improved >= c.params.RestartAfter
+	if restart {
+		c.improved = c.iteration + 1
+	}
+	for _, row := range c.pheromones {
+		for j := range row {
+			switch {
+			case restart && math.IsInf(hi, 1):
+				row[j] = c.params.Tau0
+			case restart:
+				row[j] = hi
+			default:
+				row[j] = min(max(row[j], lo), hi)
+			}
+		}
+	}
+}
+
+// depositGlobalBest — глобальное обновление ACS: τ ← (1 − ρ)·τ + ρ·Q/L_bs только на ребрах
+// лучшего пути, остальные ребра не испаряются
+func (c *Colony) depositGlobalBest() {
+	if !c.rewarded(c.bestPath, c.bestLength) {
+		return
+	}
+	deltaTau := c.params.Q / c.bestLength
+	c.blend(c.bestPath, c.params.Rho, deltaTau)
+}
+
+// localUpdate — локальное испарение ACS на ребре, по которому только что прошел муравей:
+// τ ← (1 − ξ)·τ + ξ·τ0. В остальных вариантах ничего не делает
+func (c *Colony) localUpdate(from, to int) {
+	if c.params.Variant != ColonySystem || c.params.Xi == 0 {
+		return
+	}
+	c.blend([]int{from, to}, c.params.Xi, c.params.Tau0)
+}
+
+// blend сдвигает феромон на ребрах пути к target с долей rate, в неориентированном
+// графе в обе стороны
+func (c *Colony) blend(path []int, rate, target float64) {
+	for i := 0; i < len(path)-1; i++ {
+		from, to := path[i], path[i+1]
+		c.pheromones[from][to] = (1-rate)*c.pheromones[from][to] + rate*target
+		if c.params.GraphType == Undirected {
+			c.pheromones[to][from] = (1-rate)*c.pheromones[to][from] + rate*target
+		}
+	}
+}
+
+// greedyCandidate возвращает кандидата с наибольшим τ^α·η^β — жадный переход ACS;
+// при равенстве берется первый
+func (c *Colony) greedyCandidate() int {
+	best := 0
+	for i, probability := range c.probabilities {
+		if probability > c.probabilities[best] {
+			best = i
+		}
+	}
+	return c.candidates[best]
+}
@@ -0,0 +1,199 @@
+package aco
+
+import (
+	"math"
+	"testing"
+)
+
+func newTestColony(t *testing.T, p Params) *Colony {
+	t.Helper()
+	c, err := New(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestValidateVariantFields(t *testing.T) {
+	tests := []struct {
+		name  string
+		apply func(p *Params)
+		ok    bool
+	}{
+		{"AS не читает ranks", func(p *Params) { p.Ranks = 0 }, true},
+		{"ACS не читает ranks и tauMax", func(p *Params) { p.Variant, p.Ranks, p.TauMax = ColonySystem, 1, -1 }, true},
+		{"AS не читает q0", func(p *Params) { p.Q0 = 2 }, true},
+		{"AS_rank с одним рангом", func(p *Params) { p.Variant, p.Ranks = RankBased, 1 }, false},
+		{"элитная AS с отрицательным весом", func(p *Params) { p.Variant, p.ElitistWeight = Elitist, -1 }, false},
+		{"MMAS с tauMin не меньше tauMax", func(p *Params) { p.Variant, p.TauMin, p.TauMax = MaxMin, 2, 1 }, false},
+		{"MMAS с отрицательным restartAfter", func(p *Params) { p.Variant, p.RestartAfter = MaxMin, -1 }, false},
+		{"ACS с q0 больше 1", func(p *Params) { p.Variant, p.Q0 = ColonySystem, 1.5 }, false},
+		{"ACS с отрицательным xi", func(p *Params) { p.Variant, p.Xi = ColonySystem, -0.1 }, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p := DefaultParams()
+			test.apply(&p)
+			if err := p.Validate(); (err == nil) != test.ok {
+				t.Errorf("Validate() = %v", err)
+			}
+		})
+	}
+}
+
+func TestMaxMinBounds(t *testing.T) {
+	p := DefaultParams()
+	p.Variant, p.TauMin, p.TauMax, p.RestartAfter = MaxMin, 0.05, 2, 0
+	p.Q, p.Rho, p.NodeCount = 100, 0.3, 8
+	c := newTestColony(t, p)
+
+	for range 30 {
+		c.Step()
+		for i, row := range c.pheromones {
+			for j, tau := range row {
+				if tau < p.TauMin || tau > p.TauMax {
+					t.Fatalf("итерация %d: τ[%d][%d] = %v вне [%v, %v]", c.iteration, i, j, tau, p.TauMin, p.TauMax)
+				}
+			}
+		}
+	}
+}
+
+func TestMaxMinRestart(t *testing.T) {
+	// Из двух вершин есть единственный путь: лучший найден на первой итерации и больше
+	// не улучшается, поэтому перезапуск происходит ровно на итерации restartAfter + 1
+	p := DefaultParams()
+	p.Variant, p.TauMin, p.TauMax, p.RestartAfter = MaxMin, 0.01, 5, 3
+	p.NodeCount = 2
+	c := newTestColony(t, p)
+
+	for step := 1; step <= 4; step++ {
+		c.Step()
+		restarted := true
+		for _, row := range c.pheromones {
+			for _, tau := range row {
+				restarted = restarted && tau == p.TauMax
+			}
+		}
+		if restarted != (step == 4) {
+			t.Errorf("итерация %d: феромон заполнен τmax = %v, ожидалось %v: %v", step, restarted, step == 4, c.pheromones)
+		}
+	}
+
+	// Без tauMax граница бесконечна, пока путь не найден, и перезапуск возвращает tau0
+	p.TauMax = 0
+	c = newTestColony(t, p)
+	c.iteration, c.improved = 3, 0
+	c.pheromones[c.start][c.end] = 42
+	c.bound()
+	if got := c.pheromones[c.start][c.end]; got != p.Tau0 {
+		t.Errorf("после перезапуска без границы τ = %v, ожидалось tau0 = %v", got, p.Tau0)
+	}
+	if c.improved != 4 {
+		t.Errorf("improved = %d, ожидалось 4: отсчет застоя начинается заново", c.improved)
+	}
+}
+
+func TestColonySystemGreedy(t *testing.T) {
+	p := DefaultParams()
+	p.Variant, p.Q0, p.Xi = ColonySystem, 1, 0
+	p.NodeCount, p.Objective = 12, Tour
+	c := newTestColony(t, p)
+
+	// Неравный феромон, чтобы жадный выбор зависел и от τ, а не только от расстояния
+	for i, row := range c.pheromones {
+		for j := range row {
+			row[j] = 0.5 + float64((i*7+j*3)%5)
+		}
+	}
+
+	for from := range p.NodeCount {
+		path := c.constructPath(from)
+		visited := make([]bool, p.NodeCount)
+		visited[from] = true
+		for k := 1; k < p.NodeCount; k++ {
+			current, want, best := path[k-1], -1, 0.0
+			for j := range p.NodeCount {
+				if visited[j] {
+					continue
+				}
+				score := math.Pow(c.pheromones[current][j], p.Alpha) * math.Pow(1/c.distances[current][j], p.Beta)
+				if want == -1 || score > best {
+					want, best = j, score
+				}
+			}
+			if path[k] != want {
+				t.Fatalf("из %d: шаг %d ведет в %d, жадный выбор — %d (путь %v)", from, k, path[k], want, path)
+			}
+			visited[want] = true
+		}
+	}
+}
+
+func TestColonySystemLocalUpdate(t *testing.T) {
+	for _, graphType := range []GraphType{Undirected, Directed} {
+		p := DefaultParams()
+		p.Variant, p.Xi, p.Tau0, p.GraphType = ColonySystem, 0.3, 1, graphType
+		c := newTestColony(t, p)
+		c.pheromones[0][1], c.pheromones[1][0] = 5, 5
+
+		c.localUpdate(0, 1)
+		back := 5.0
+		if graphType == Undirected {
+			back = 3.8
+		}
+		if got := c.pheromones[0][1]; math.Abs(got-3.8) > 1e-12 {
+			t.Errorf("%s: τ[0][1] = %v, ожидалось (1 − ξ)·5 + ξ·τ0 = 3.8", graphType, got)
+		}
+		if got := c.pheromones[1][0]; math.Abs(got-back) > 1e-12 {
+			t.Errorf("%s: τ[1][0] = %v, ожидалось %v", graphType, got, back)
+		}
+	}
+
+	// В остальных вариантах локального испарения нет
+	c := newTestColony(t, DefaultParams())
+	c.pheromones[0][1] = 5
+	c.localUpdate(0, 1)
+	if got := c.pheromones[0][1]; got != 5 {
+		t.Errorf("AS: τ[0][1] = %v после localUpdate, ожидалось 5", got)
+	}
+}
+
+func TestRankBasedDeposit(t *testing.T) {
+	p := DefaultParams()
+	p.Variant, p.Ranks, p.Q, p.NodeCount = RankBased, 3, 10, 5
+	c := newTestColony(t, p)
+
+	s, e := c.start, c.end
+	var others []int
+	for i := range p.NodeCount {
+		if i != s && i != e {
+			others = append(others, i)
+		}
+	}
+	a, b, d := others[0], others[1], others[2]
+
+	// Муравей [s, a] не дошел до цели и не подкрепляет путь, хотя его длина меньше всех
+	paths := [][]int{{s, b, e}, {s, a}, {s, d, e}, {s, a, e}}
+	lengths := []float64{20, 1, 40, 10}
+	c.bestPath, c.bestLength = []int{s, e}, 5
+	c.depositRanked(paths, lengths)
+
+	tau0 := p.Tau0
+	tests := []struct {
+		from, to int
+		want     float64
+	}{
+		{s, a, tau0 + 2*p.Q/10}, // ранг 1: (w − 1)·Q/L
+		{a, e, tau0 + 2*p.Q/10},
+		{s, b, tau0 + 1*p.Q/20}, // ранг 2: (w − 2)·Q/L
+		{s, d, tau0},            // ранг 3 не подкрепляет
+		{s, e, tau0 + 3*p.Q/5},  // лучший путь: w·Q/L_bs
+		{e, s, tau0 + 3*p.Q/5},  // неориентированный граф
+	}
+	for _, test := range tests {
+		if got := c.pheromones[test.from][test.to]; math.Abs(got-test.want) > 1e-12 {
+			t.Errorf("τ[%d][%d] = %v, ожидалось %v", test.from, test.to, got, test.want)
+		}
+	}
+}
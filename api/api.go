@@ -0,0 +1,460 @@
+// Package api — HTTP API для прогонов алгоритмов на сервере.
+//
+//	POST /api/{algorithm}/run     прогон с параметрами, метриками и прореженной траекторией
+//	GET  /api/{algorithm}/params  принимаемые параметры, значения по умолчанию и ограничения
+//
+// Параметры называются ключами из static/latex/params/{algorithm}.tex, например m и T для aco,
+// либо именами полей JSON движка. Объявленные там ограничения вида !!! \in (0,1] !!! проверяются
+// до запуска, затем параметры проверяет сам движок. Тело запроса на прогон:
+//
+//	{
+//	  "params": {"alpha": 1.5, "m": 20},
+//	  "steps": 200,
+//	  "seeds": [1, 2, 3],
+//	  "trajectory": {"every": 10, "agents": 50}
+//	}
+//
+// seeds запускает прогон для каждого зерна, без него используется seed из params.
+// trajectory добавляет к прогону кадры после каждого every-го шага; agents ограничивает
+// число агентов или особей в кадре, при 0 кадр содержит только показатели.
+//
+// Муравьиный алгоритм может идти по графу из файла вместо случайного: "graph": "burma14"
+// в запросе на прогон или сеанс берет число вершин, веса ребер и ориентированность из файла
+// каталога Config.GraphsDir (TSPLIB .tsp и .atsp, DIMACS .gr с координатами в .co, CSV):
+//
+//	GET /api/aco/graphs         список графов
+//	GET /api/aco/graphs/{name}  граф целиком: вершины, ребра [from, to, weight], старт и цель
+//
+// Сеанс потока шагает симуляцию на сервере и рассылает кадры через Server-Sent Events;
+// страницы boids.html и sds.html с ?server показывают его вместо собственного расчета:
+//
+//	POST   /api/{algorithm}/sessions              создать сеанс: {"params", "graph", "fps", "stepsPerFrame", "agents", "paused"}
+//	GET    /api/{algorithm}/sessions/{id}         состояние сеанса
+//	GET    /api/{algorithm}/sessions/{id}/events  поток событий frame
+//	POST   /api/{algorithm}/sessions/{id}         команда: {"action": "pause"}, "resume", {"action": "step", "steps": 1},
+//	                                              {"action": "seek", "step": 500}, {"action": "params", "params": {...}},
+//	                                              "reset" или {"action": "speed", "fps": 30, "stepsPerFrame": 2}
+//	DELETE /api/{algorithm}/sessions/{id}         закрыть сеанс
+//
+// Кадр содержит шаг, показатели и положения, округленные до точности отрисовки, для aco —
+// граф и матрицу феромона в опорном кадре и изменившиеся ребра [i, j, v] в остальных.
+// Параметры меняются на ходу; параметры начального состояния (число агентов, зерно,
+// размер области) начинают прогон заново. Перемотка назад повторяет прогон с начала
+// вместе с записанными сменами параметров
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/RiddlerXenon/roi/aco"
+	"github.com/RiddlerXenon/roi/boids"
+	"github.com/RiddlerXenon/roi/internal/paramspec"
+	"github.com/RiddlerXenon/roi/sds"
+)
+
+// Config — настройки сервера
+type Config struct {
+	// ParamsDir — каталог описаний параметров {algorithm}.tex; алгоритм без описания
+	// принимает только имена полей JSON
+	ParamsDir string
+
+	// GraphsDir — каталог графов для aco; без него муравьи идут только по случайным графам
+	GraphsDir string
+
+	// Timeout ограничивает время одного запроса на прогон вместе со всеми зернами
+	Timeout time.Duration
+
+	// MaxSteps, MaxSeeds и MaxFrames ограничивают объем работы и ответа одного запроса.
+	// MaxSteps ограничивает и шаг, к которому перематывается сеанс потока
+	MaxSteps  int
+	MaxSeeds  int
+	MaxFrames int
+
+	// MaxNodes, MaxColony, MaxBoids и MaxAgents ограничивают размер модели: вершины
+	// и муравьев aco, особей boids и агентов sds. Память aco растет как квадрат числа
+	// вершин, а построение модели не прерывается по Timeout, поэтому размер проверяется
+	// до него
+	MaxNodes  int
+	MaxColony int
+	MaxBoids  int
+	MaxAgents int
+
+	// MaxSessions — число одновременно открытых сеансов потока; сеанс без зрителей
+	// и команд закрывается через SessionIdle
+	MaxSessions int
+	SessionIdle time.Duration
+}
+
+// DefaultConfig возвращает настройки для запуска из корня репозитория
+func DefaultConfig() Config {
+	return Config{
+		ParamsDir: filepath.Join("static", "latex", "params"),
+		GraphsDir: filepath.Join("static", "graphs"),
+		Timeout:   10 * time.Second,
+		MaxSteps:  100000,
+		MaxSeeds:  100,
+		MaxFrames: 2000,
+
+		MaxNodes:  aco.MaxInstanceNodes,
+		MaxColony: 1000,
+		MaxBoids:  5000,
+		MaxAgents: 10000,
+
+		MaxSessions: 16,
+		SessionIdle: 2 * time.Minute,
+	}
+}
+
+// maxBody — предельный размер тела запроса
+const maxBody = 1 << 20
+
+// Server обслуживает запросы API
+type Server struct {
+	config   Config
+	specs    map[string][]paramspec.Spec
+	graphs   map[string]*aco.Instance
+	mux      *http.ServeMux
+	sessions sessions
+}
+
+// New читает описания параметров и графы и создает сервер
+func New(config Config) (*Server, error) {
+	s := &Server{
+		config:   config,
+		specs:    make(map[string][]paramspec.Spec),
+		mux:      http.NewServeMux(),
+		sessions: sessions{byID: make(map[string]*session)},
+	}
+	for name := range runners {
+		specs, err := paramspec.Load(filepath.Join(config.ParamsDir, name+".tex"))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		s.specs[name] = specs
+	}
+	graphs, err := loadGraphs(config.GraphsDir)
+	if err != nil {
+		return nil, err
+	}
+	s.graphs = graphs
+
+	s.mux.HandleFunc("POST /api/{algorithm}/run", s.handleRun)
+	s.mux.HandleFunc("GET /api/{algorithm}/params", s.handleParams)
+	s.mux.HandleFunc("GET /api/aco/graphs", s.handleGraphs)
+	s.mux.HandleFunc("GET /api/aco/graphs/{name}", s.handleGraph)
+	s.mux.HandleFunc("POST /api/{algorithm}/sessions", s.handleCreateSession)
+	s.mux.HandleFunc("GET /api/{algorithm}/sessions/{id}", s.handleSessionStatus)
+	s.mux.HandleFunc("GET /api/{algorithm}/sessions/{id}/events", s.handleEvents)
+	s.mux.HandleFunc("POST /api/{algorithm}/sessions/{id}", s.handleControl)
+	s.mux.HandleFunc("DELETE /api/{algorithm}/sessions/{id}", s.handleCloseSession)
+	return s, nil
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// runRequest — тело запроса на прогон
+type runRequest struct {
+	Params     map[string]any `json:"params"`
+	Graph      string         `json:"graph"`
+	Steps      int            `json:"steps"`
+	Seeds      []float64      `json:"seeds"`
+	Trajectory *trajectory    `json:"trajectory"`
+}
+
+// trajectory — прореживание траектории: кадр после каждого Every-го шага,
+// в кадре не больше Agents агентов или особей
+type trajectory struct {
+	Every  int `json:"every"`
+	Agents int `json:"agents"`
+}
+
+type runResponse struct {
+	Algorithm     string      `json:"algorithm"`
+	EngineVersion int         `json:"engineVersion"`
+	Params        any         `json:"params"`
+	Graph         string      `json:"graph,omitempty"`
+	Ignored       []string    `json:"ignored,omitempty"`
+	Runs          []runResult `json:"runs"`
+}
+
+// runResult — итог прогона с одним зерном
+type runResult struct {
+	Seed       any     `json:"seed"`
+	Steps      int     `json:"steps"`
+	Metrics    any     `json:"metrics"`
+	Trajectory any     `json:"trajectory,omitempty"`
+	ElapsedMS  float64 `json:"elapsedMs"`
+}
+
+func (s *Server) handleRun(w http.ResponseWriter, r *http.Request) {
+	name, rn, ok := lookupRunner(w, r)
+	if !ok {
+		return
+	}
+
+	var req runRequest
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBody))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("разбор запроса: %w", err))
+		return
+	}
+
+	graph, err := s.lookupGraph(name, req.Graph)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	runs, base, ignored, err := s.prepare(name, rn, req, graph)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), s.config.Timeout)
+	defer cancel()
+	resp := runResponse{Algorithm: name, EngineVersion: rn.engineVersion, Params: base, Graph: req.Graph, Ignored: ignored}
+	for _, params := range runs {
+		started := time.Now()
+		result, err := rn.run(ctx, params, graph, req.Steps, req.Trajectory)
+		switch {
+		case errors.Is(err, context.DeadlineExceeded):
+			writeError(w, http.StatusGatewayTimeout, fmt.Errorf("прогон не уложился в %v", s.config.Timeout))
+			return
+		case errors.Is(err, context.Canceled):
+			return
+		case err != nil:
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		result.Seed = fieldValue(params, "seed")
+		result.ElapsedMS = float64(time.Since(started).Microseconds()) / 1000
+		resp.Runs = append(resp.Runs, result)
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// prepare проверяет запрос и собирает параметры каждого прогона. Все ошибки в параметрах
+// обнаруживаются до запуска, чтобы запрос не тратил время впустую. Граф из файла задает
+// число вершин и тип графа aco поверх параметров запроса
+func (s *Server) prepare(name string, rn runner, req runRequest, graph *aco.Instance) (runs []any, base any, ignored []string, err error) {
+	c := s.config
+	switch {
+	case req.Steps < 0 || req.Steps > c.MaxSteps:
+		return nil, nil, nil, fmt.Errorf("steps = %d: ожидается от 0 до %d", req.Steps, c.MaxSteps)
+	case req.Steps == 0 && rn.defaultSteps == nil:
+		return nil, nil, nil, fmt.Errorf("для %s нужно задать steps", name)
+	case len(req.Seeds) > c.MaxSeeds:
+		return nil, nil, nil, fmt.Errorf("%d зерен: за один запрос не больше %d", len(req.Seeds), c.MaxSeeds)
+	}
+
+	fields, ignored, err := s.translate(name, rn, req.Params)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if base, err = rn.decode(fields, req.Steps); err != nil {
+		return nil, nil, nil, err
+	}
+	base = onGraph(base, graph)
+	// Зерна меняют только seed, так что размер достаточно проверить у base
+	if err := s.checkSize(base); err != nil {
+		return nil, nil, nil, err
+	}
+
+	if t := req.Trajectory; t != nil {
+		steps := req.Steps
+		if steps == 0 {
+			steps = rn.defaultSteps(base)
+		}
+		switch {
+		case t.Every < 1:
+			return nil, nil, nil, fmt.Errorf("trajectory.every = %d: интервал кадров должен быть положительным", t.Every)
+		case t.Agents < 0:
+			return nil, nil, nil, fmt.Errorf("trajectory.agents = %d: число агентов не может быть отрицательным", t.Agents)
+		case steps/t.Every > c.MaxFrames:
+			return nil, nil, nil, fmt.Errorf("%d кадров траектории: не больше %d, увеличьте trajectory.every", steps/t.Every, c.MaxFrames)
+		}
+	}
+
+	if len(req.Seeds) == 0 {
+		return []any{base}, base, ignored, nil
+	}
+	for _, seed := range req.Seeds {
+		fields["seed"] = seed
+		params, err := rn.decode(fields, req.Steps)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		runs = append(runs, onGraph(params, graph))
+	}
+	return runs, base, ignored, nil
+}
+
+// checkSize сверяет размер модели и бюджет итераций aco с ограничениями Config
+func (s *Server) checkSize(params any) error {
+	c := s.config
+	switch p := params.(type) {
+	case *aco.Params:
+		switch {
+		case p.NodeCount > c.MaxNodes:
+			return fmt.Errorf("nodeCount = %d: сервер строит не больше %d вершин", p.NodeCount, c.MaxNodes)
+		case p.ColonySize > c.MaxColony:
+			return fmt.Errorf("colonySize = %d: сервер запускает не больше %d муравьев", p.ColonySize, c.MaxColony)
+		case p.MaxIterations > c.MaxSteps:
+			// Без steps длину прогона задает maxIterations, и она ограничена так же, как steps
+			return fmt.Errorf("maxIterations = %d: за один прогон не больше %d итераций", p.MaxIterations, c.MaxSteps)
+		}
+	case *boids.Params:
+		if p.BoidCount > c.MaxBoids {
+			return fmt.Errorf("boidCount = %d: сервер моделирует не больше %d особей", p.BoidCount, c.MaxBoids)
+		}
+	case *sds.Params:
+		if p.N > c.MaxAgents {
+			return fmt.Errorf("N = %d: сервер моделирует не больше %d агентов", p.N, c.MaxAgents)
+		}
+	}
+	return nil
+}
+
+// translate переводит ключи описаний в имена полей JSON и проверяет объявленные ограничения.
+// Ключи описаний, которые относятся только к отображению, возвращаются в ignored
+func (s *Server) translate(name string, rn runner, params map[string]any) (map[string]any, []string, error) {
+	specs := make(map[string]paramspec.Spec)
+	for _, spec := range s.specs[name] {
+		specs[spec.Name] = spec
+	}
+	known := fieldNames(rn.defaults())
+
+	fields := make(map[string]any, len(params))
+	var ignored []string
+	for key, value := range params {
+		field := key
+		if alias, ok := rn.aliases[key]; ok {
+			field = alias
+		}
+		if !known[field] {
+			if _, ok := specs[key]; ok {
+				ignored = append(ignored, key)
+				continue
+			}
+			return nil, nil, fmt.Errorf("неизвестный параметр %q, доступны: %s", key, strings.Join(s.keys(name, rn), ", "))
+		}
+		if _, dup := fields[field]; dup {
+			return nil, nil, fmt.Errorf("параметр %s задан дважды", field)
+		}
+
+		spec, ok := specs[key]
+		if !ok {
+			spec, ok = specs[rn.keyOf(field)]
+		}
+		if number, isNumber := value.(float64); ok && isNumber {
+			if err := spec.Check(number); err != nil {
+				return nil, nil, err
+			}
+		}
+		fields[field] = value
+	}
+	sort.Strings(ignored)
+	return fields, ignored, nil
+}
+
+// keys возвращает принимаемые ключи: из описаний и имена полей без описаний
+func (s *Server) keys(name string, rn runner) []string {
+	var keys []string
+	described := make(map[string]bool)
+	for _, spec := range s.specs[name] {
+		keys = append(keys, spec.Name)
+		described[spec.Name] = true
+	}
+	for field := range fieldNames(rn.defaults()) {
+		if !described[rn.keyOf(field)] {
+			keys = append(keys, field)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// paramInfo — описание параметра в ответе GET /api/{algorithm}/params
+type paramInfo struct {
+	Key        string `json:"key"`
+	Field      string `json:"field,omitempty"`
+	Default    any    `json:"default"`
+	Constraint string `json:"constraint,omitempty"`
+	Title      string `json:"title,omitempty"`
+	Display    bool   `json:"display,omitempty"`
+}
+
+func (s *Server) handleParams(w http.ResponseWriter, r *http.Request) {
+	name, rn, ok := lookupRunner(w, r)
+	if !ok {
+		return
+	}
+
+	defaults := rn.defaults()
+	known := fieldNames(defaults)
+	described := make(map[string]bool)
+	var infos []paramInfo
+	for _, spec := range s.specs[name] {
+		field := spec.Name
+		if alias, ok := rn.aliases[spec.Name]; ok {
+			field = alias
+		}
+		info := paramInfo{Key: spec.Name, Constraint: spec.Constraint, Title: spec.Title}
+		if known[field] {
+			info.Default = fieldValue(defaults, field)
+			if field != spec.Name {
+				info.Field = field
+			}
+			described[field] = true
+		} else {
+			info.Display = true
+		}
+		infos = append(infos, info)
+	}
+	for _, field := range orderedFields(defaults) {
+		if !described[field] {
+			infos = append(infos, paramInfo{Key: field, Default: fieldValue(defaults, field)})
+		}
+	}
+	writeJSON(w, http.StatusOK, infos)
+}
+
+// lookupRunner находит алгоритм по пути запроса или отвечает 404
+func lookupRunner(w http.ResponseWriter, r *http.Request) (string, runner, bool) {
+	name := r.PathValue("algorithm")
+	rn, ok := runners[name]
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("неизвестный алгоритм %q, доступны: %s", name, strings.Join(Algorithms(), ", ")))
+	}
+	return name, rn, ok
+}
+
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		log.Printf("Ошибка записи ответа: %v", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
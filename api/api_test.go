@@ -0,0 +1,122 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestServer создает сервер с описаниями и графами репозитория и малыми ограничениями
+func newTestServer(t *testing.T, apply func(c *Config)) *Server {
+	t.Helper()
+	config := DefaultConfig()
+	config.ParamsDir = filepath.Join("..", "static", "latex", "params")
+	config.GraphsDir = filepath.Join("..", "static", "graphs")
+	config.MaxSteps = 500
+	config.MaxSeeds = 3
+	config.MaxNodes = 20
+	config.MaxColony = 30
+	config.MaxBoids = 40
+	config.MaxAgents = 500
+	if apply != nil {
+		apply(&config)
+	}
+	s, err := New(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// post отправляет запрос и возвращает код ответа и текст ошибки из {"error": ...}
+func post(t *testing.T, s *Server, path, body string) (int, string) {
+	t.Helper()
+	recorder := httptest.NewRecorder()
+	s.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, path, strings.NewReader(body)))
+
+	var resp struct {
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("%s: ответ не JSON: %v\n%s", path, err, recorder.Body)
+	}
+	return recorder.Code, resp.Error
+}
+
+func TestRun(t *testing.T) {
+	s := newTestServer(t, nil)
+	recorder := httptest.NewRecorder()
+	s.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/api/aco/run",
+		strings.NewReader(`{"params": {"m": 5, "T": 40}, "steps": 25, "seeds": [1, 2]}`)))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("код %d: %s", recorder.Code, recorder.Body)
+	}
+
+	var resp runResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Runs) != 2 {
+		t.Fatalf("%d прогонов, ожидалось 2", len(resp.Runs))
+	}
+	for _, run := range resp.Runs {
+		if run.Steps != 25 {
+			t.Errorf("зерно %v: %d итераций, ожидалось steps = 25", run.Seed, run.Steps)
+		}
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	s := newTestServer(t, nil)
+	tests := []struct {
+		name   string
+		path   string
+		body   string
+		status int
+		want   string
+	}{
+		{"неизвестный алгоритм", "/api/bees/run", `{"steps": 10}`, http.StatusNotFound, `неизвестный алгоритм "bees"`},
+		{"неизвестный параметр", "/api/aco/run", `{"params": {"gamma": 1}}`, http.StatusBadRequest, `неизвестный параметр "gamma"`},
+		{"rho вне ограничения описания", "/api/aco/run", `{"params": {"rho": 0}}`, http.StatusBadRequest, "rho"},
+		{"steps больше MaxSteps", "/api/sds/run", `{"steps": 501}`, http.StatusBadRequest, "steps = 501"},
+		{"steps обязателен для sds", "/api/sds/run", `{}`, http.StatusBadRequest, "нужно задать steps"},
+		{"зерен больше MaxSeeds", "/api/sds/run", `{"steps": 1, "seeds": [1, 2, 3, 4]}`, http.StatusBadRequest, "4 зерен"},
+		{"nodeCount больше MaxNodes", "/api/aco/run", `{"params": {"nodeCount": 21}}`, http.StatusBadRequest, "nodeCount = 21"},
+		{"colonySize больше MaxColony", "/api/aco/run", `{"params": {"m": 31}}`, http.StatusBadRequest, "colonySize = 31"},
+		{"maxIterations больше MaxSteps", "/api/aco/run", `{"params": {"T": 501}}`, http.StatusBadRequest, "maxIterations = 501"},
+		{"boidCount больше MaxBoids", "/api/boids/run", `{"params": {"boidCount": 41}, "steps": 1}`, http.StatusBadRequest, "boidCount = 41"},
+		{"N больше MaxAgents", "/api/sds/run", `{"params": {"N": 501}, "steps": 1}`, http.StatusBadRequest, "N = 501"},
+		{"граф в пределах MaxNodes", "/api/aco/run", `{"graph": "burma14", "params": {"m": 5}}`, http.StatusOK, ""},
+		{"сеанс с boidCount больше MaxBoids", "/api/boids/sessions", `{"params": {"boidCount": 41}, "paused": true}`, http.StatusBadRequest, "boidCount = 41"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			status, message := post(t, s, test.path, test.body)
+			if status != test.status || !strings.Contains(message, test.want) {
+				t.Errorf("код %d, ошибка %q; ожидались %d и %q", status, message, test.status, test.want)
+			}
+		})
+	}
+
+	// Граф из файла проверяется тем же ограничением, что и nodeCount
+	small := newTestServer(t, func(c *Config) { c.MaxNodes = 10 })
+	status, message := post(t, small, "/api/aco/run", `{"graph": "burma14"}`)
+	if status != http.StatusBadRequest || !strings.Contains(message, "nodeCount = 14") {
+		t.Errorf("граф burma14 при MaxNodes = 10: код %d, ошибка %q", status, message)
+	}
+}
+
+func TestRunTimeout(t *testing.T) {
+	s := newTestServer(t, func(c *Config) {
+		c.Timeout = time.Nanosecond
+		c.MaxSteps = 100000
+	})
+	status, message := post(t, s, "/api/sds/run", `{"steps": 100000}`)
+	if status != http.StatusGatewayTimeout || !strings.Contains(message, "не уложился") {
+		t.Errorf("код %d, ошибка %q; ожидался %d", status, message, http.StatusGatewayTimeout)
+	}
+}
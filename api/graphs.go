@@ -0,0 +1,125 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/RiddlerXenon/roi/aco"
+)
+
+// graphExtensions — файлы каталога графов, которые читает loadGraphs; .co DIMACS
+// читается вместе со своим .gr
+var graphExtensions = map[string]bool{".tsp": true, ".atsp": true, ".gr": true, ".csv": true}
+
+// loadGraphs читает графы каталога dir по имени файла без расширения; без каталога графов нет
+func loadGraphs(dir string) (map[string]*aco.Instance, error) {
+	graphs := make(map[string]*aco.Instance)
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return graphs, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if entry.IsDir() || !graphExtensions[ext] {
+			continue
+		}
+		name := strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name()))
+		if _, dup := graphs[name]; dup {
+			return nil, fmt.Errorf("%s: два файла графа с именем %s", dir, name)
+		}
+		inst, err := aco.LoadInstance(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		graphs[name] = inst
+	}
+	return graphs, nil
+}
+
+// graphInfo — краткое описание графа в ответе GET /api/aco/graphs
+type graphInfo struct {
+	Name     string  `json:"name"`
+	Title    string  `json:"title"`
+	Comment  string  `json:"comment,omitempty"`
+	Format   string  `json:"format"`
+	Nodes    int     `json:"nodes"`
+	Edges    int     `json:"edges"`
+	Directed bool    `json:"directed"`
+	Complete bool    `json:"complete"`
+	Optimum  float64 `json:"optimum,omitempty"`
+}
+
+func (s *Server) handleGraphs(w http.ResponseWriter, r *http.Request) {
+	infos := make([]graphInfo, 0, len(s.graphs))
+	for name, inst := range s.graphs {
+		infos = append(infos, graphInfo{
+			Name:     name,
+			Title:    inst.Name,
+			Comment:  inst.Comment,
+			Format:   inst.Format,
+			Nodes:    len(inst.Nodes),
+			Edges:    len(inst.Edges),
+			Directed: inst.Directed,
+			Complete: inst.Complete(),
+			Optimum:  inst.Optimum,
+		})
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	writeJSON(w, http.StatusOK, infos)
+}
+
+// handleGraph отдает граф целиком: вершины в координатах файла, ребра [from, to, weight]
+func (s *Server) handleGraph(w http.ResponseWriter, r *http.Request) {
+	inst, err := s.lookupGraph(aco.Algorithm, r.PathValue("name"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, inst)
+}
+
+// lookupGraph находит граф из запроса; пустое имя означает случайный граф из параметров
+func (s *Server) lookupGraph(algorithm, name string) (*aco.Instance, error) {
+	if name == "" {
+		return nil, nil
+	}
+	if algorithm != aco.Algorithm {
+		return nil, fmt.Errorf("graph задается только для %s", aco.Algorithm)
+	}
+	inst, ok := s.graphs[name]
+	if !ok {
+		names := make([]string, 0, len(s.graphs))
+		for name := range s.graphs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("неизвестный граф %q, доступны: %s", name, strings.Join(names, ", "))
+	}
+	return inst, nil
+}
+
+// onGraph подставляет в параметры aco число вершин и тип графа из файла
+func onGraph(params any, graph *aco.Instance) any {
+	if graph == nil {
+		return params
+	}
+	p := graph.Apply(*params.(*aco.Params))
+	return &p
+}
+
+// newColony создает колонию на графе из файла или, без него, на случайном графе
+func newColony(params any, graph *aco.Instance) (*aco.Colony, error) {
+	if graph != nil {
+		return aco.NewOnInstance(*params.(*aco.Params), graph)
+	}
+	return aco.New(*params.(*aco.Params))
+}
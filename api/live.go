@@ -0,0 +1,155 @@
+package api
+
+import (
+	"math"
+
+	"github.com/RiddlerXenon/roi/aco"
+	"github.com/RiddlerXenon/roi/boids"
+	"github.com/RiddlerXenon/roi/sds"
+)
+
+// simulation — движок, которым управляет сеанс потока
+type simulation interface {
+	// step выполняет шаг; false — шагать больше нельзя (бюджет итераций aco исчерпан)
+	step() bool
+	steps() int
+
+	// setParams меняет параметры без перезапуска; ошибка означает, что изменение
+	// затрагивает начальное состояние и движок нужно создать заново
+	setParams(params any) error
+
+	// state снимает состояние; agents ограничивает число агентов или особей, 0 — все
+	state(agents int) simState
+}
+
+// simState — снимок движка для кадра. Срезы принадлежат снимку и не меняются после него
+type simState struct {
+	Metrics   any
+	Agents    any
+	Tracked   [][2]float64
+	Graph     *aco.Graph
+	Pheromone [][]float64
+}
+
+// Точность координат в кадрах: пиксели стаи до сотых, точки sds до 1e-4
+const (
+	boidsScale = 1e2
+	sdsScale   = 1e4
+)
+
+// round округляет v до 1/scale; деление на целое дает ближайшее к десятичной записи число
+func round(v, scale float64) float64 {
+	return math.Round(v*scale) / scale
+}
+
+type acoLive struct{ c *aco.Colony }
+
+func liveACO(params any, graph *aco.Instance) (simulation, error) {
+	c, err := newColony(params, graph)
+	return acoLive{c}, err
+}
+
+func (l acoLive) step() bool { return l.c.Step() }
+func (l acoLive) steps() int { return l.c.Iteration() }
+
+func (l acoLive) setParams(params any) error { return l.c.SetParams(*params.(*aco.Params)) }
+
+// acoLiveMetrics — показатели итерации вместе с путями для отрисовки
+type acoLiveMetrics struct {
+	Iteration         int        `json:"iteration"`
+	BestPath          []int      `json:"bestPath"`
+	BestLength        aco.Length `json:"bestLength"`
+	CurrentBestPath   []int      `json:"currentBestPath"`
+	CurrentBestLength aco.Length `json:"currentBestLength"`
+	GapPercent        *float64   `json:"gapPercent,omitempty"`
+}
+
+func (l acoLive) state(int) simState {
+	best, bestLength := l.c.Best()
+	current, currentLength := l.c.CurrentBest()
+	graph := l.c.Graph()
+	n := len(graph.Nodes)
+	pheromone := make([][]float64, n)
+	for i := range pheromone {
+		pheromone[i] = make([]float64, n)
+		for j := range pheromone[i] {
+			pheromone[i][j] = l.c.Pheromone(i, j)
+		}
+	}
+	return simState{
+		Metrics: acoLiveMetrics{
+			Iteration:         l.c.Iteration(),
+			BestPath:          append([]int(nil), best...),
+			BestLength:        aco.Length(bestLength),
+			CurrentBestPath:   append([]int(nil), current...),
+			CurrentBestLength: aco.Length(currentLength),
+			GapPercent:        gapOf(l.c),
+		},
+		Graph:     &graph,
+		Pheromone: pheromone,
+	}
+}
+
+type boidsLive struct{ f *boids.Flock }
+
+func liveBoids(params any, _ *aco.Instance) (simulation, error) {
+	f, err := boids.New(*params.(*boids.Params))
+	return boidsLive{f}, err
+}
+
+func (l boidsLive) step() bool { l.f.Step(); return true }
+func (l boidsLive) steps() int { return l.f.Steps() }
+
+func (l boidsLive) setParams(params any) error { return l.f.SetParams(*params.(*boids.Params)) }
+
+// state возвращает особи как [x, y, vx, vy]: скорость нужна странице для поворота треугольника
+func (l boidsLive) state(agents int) simState {
+	all := l.f.Boids()
+	if agents == 0 {
+		agents = len(all)
+	}
+	frame := make([][4]float64, 0, min(agents, len(all)))
+	for _, i := range sample(len(all), agents) {
+		b := all[i]
+		frame = append(frame, [4]float64{
+			round(b.X.X, boidsScale), round(b.X.Y, boidsScale),
+			round(b.V.X, boidsScale), round(b.V.Y, boidsScale),
+		})
+	}
+	return simState{Metrics: boidsMetrics{l.f.Steps(), l.f.Order()}, Agents: frame}
+}
+
+type sdsLive struct{ s *sds.Swarm }
+
+func liveSDS(params any, _ *aco.Instance) (simulation, error) {
+	s, err := sds.New(*params.(*sds.Params))
+	return sdsLive{s}, err
+}
+
+func (l sdsLive) step() bool { l.s.Step(); return true }
+func (l sdsLive) steps() int { return l.s.Metrics().T }
+
+func (l sdsLive) setParams(params any) error { return l.s.SetParams(*params.(*sds.Params)) }
+
+// state возвращает агентов как [x, y, success] с success 0 или 1 и положения
+// отслеживаемых агентов, из которых страница наращивает траектории
+func (l sdsLive) state(agents int) simState {
+	all := l.s.Agents()
+	if agents == 0 {
+		agents = len(all)
+	}
+	frame := make([][3]float64, 0, min(agents, len(all)))
+	for _, i := range sample(len(all), agents) {
+		a := all[i]
+		success := 0.0
+		if a.Success {
+			success = 1
+		}
+		frame = append(frame, [3]float64{round(a.X, sdsScale), round(a.Y, sdsScale), success})
+	}
+	tracked := make([][2]float64, 0, len(l.s.Tracked()))
+	for _, i := range l.s.Tracked() {
+		tracked = append(tracked, [2]float64{round(all[i].X, sdsScale), round(all[i].Y, sdsScale)})
+	}
+	return simState{Metrics: l.s.Metrics(), Agents: frame, Tracked: tracked}
+}
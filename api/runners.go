@@ -0,0 +1,313 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/RiddlerXenon/roi/aco"
+	"github.com/RiddlerXenon/roi/boids"
+	"github.com/RiddlerXenon/roi/sds"
+)
+
+// runner — алгоритм, доступный через API
+type runner struct {
+	engineVersion int
+
+	// defaults возвращает указатель на параметры страницы по умолчанию
+	defaults func() any
+
+	// aliases сопоставляет ключам описаний, которые отличаются от имен полей JSON, имена полей
+	aliases map[string]string
+
+	// defaultSteps возвращает число шагов, если steps в запросе не задан; nil — steps обязателен
+	defaultSteps func(params any) int
+
+	// run выполняет steps шагов и проверяет ctx после каждого; graph не nil только у aco
+	run func(ctx context.Context, params any, graph *aco.Instance, steps int, t *trajectory) (runResult, error)
+
+	// live создает движок для сеанса потока
+	live func(params any, graph *aco.Instance) (simulation, error)
+}
+
+var runners = map[string]runner{
+	aco.Algorithm: {
+		engineVersion: aco.EngineVersion,
+		defaults:      func() any { p := aco.DefaultParams(); return &p },
+		aliases:       aco.ParamAliases,
+		defaultSteps:  func(params any) int { return params.(*aco.Params).MaxIterations },
+		run:           runACO,
+		live:          liveACO,
+	},
+	boids.Algorithm: {
+		engineVersion: boids.EngineVersion,
+		defaults:      func() any { p := boids.DefaultParams(); return &p },
+		run:           runBoids,
+		live:          liveBoids,
+	},
+	sds.Algorithm: {
+		engineVersion: sds.EngineVersion,
+		defaults:      func() any { p := sds.DefaultParams(); return &p },
+		run:           runSDS,
+		live:          liveSDS,
+	},
+}
+
+// Algorithms возвращает отсортированные имена алгоритмов API
+func Algorithms() []string {
+	names := make([]string, 0, len(runners))
+	for name := range runners {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// keyOf возвращает ключ описания для поля JSON
+func (rn runner) keyOf(field string) string {
+	for key, alias := range rn.aliases {
+		if alias == field {
+			return key
+		}
+	}
+	return field
+}
+
+// decode накладывает поля на параметры по умолчанию и проверяет их движком.
+// Для aco ненулевой steps заменяет бюджет итераций, как в cmd/sweep
+func (rn runner) decode(fields map[string]any, steps int) (any, error) {
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+	params := rn.defaults()
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(params); err != nil {
+		return nil, fmt.Errorf("параметры: %w", err)
+	}
+	if p, ok := params.(*aco.Params); ok && steps > 0 {
+		p.MaxIterations = steps
+	}
+	if err := params.(interface{ Validate() error }).Validate(); err != nil {
+		return nil, err
+	}
+	return params, nil
+}
+
+// merge накладывает поля на текущие параметры сеанса и проверяет результат
+func (rn runner) merge(current any, fields map[string]any) (any, error) {
+	data, err := json.Marshal(current)
+	if err != nil {
+		return nil, err
+	}
+	// json.Number сохраняет зерно boids: uint64 не помещается в float64 без потерь
+	merged := make(map[string]any)
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&merged); err != nil {
+		return nil, err
+	}
+	maps.Copy(merged, fields)
+	return rn.decode(merged, 0)
+}
+
+// acoMetrics — итог муравьиного алгоритма; длины без найденного пути записываются как null.
+// optimum и gapPercent есть только у тура на графе с известным оптимумом
+type acoMetrics struct {
+	Iterations        int        `json:"iterations"`
+	BestPath          []int      `json:"bestPath"`
+	BestLength        aco.Length `json:"bestLength"`
+	BestIteration     int        `json:"bestIteration"`
+	CurrentBestLength aco.Length `json:"currentBestLength"`
+	Optimum           *float64   `json:"optimum,omitempty"`
+	GapPercent        *float64   `json:"gapPercent,omitempty"`
+}
+
+type acoFrame struct {
+	Iteration         int        `json:"iteration"`
+	BestLength        aco.Length `json:"bestLength"`
+	CurrentBestLength aco.Length `json:"currentBestLength"`
+	GapPercent        *float64   `json:"gapPercent,omitempty"`
+}
+
+// gapOf возвращает отставание лучшего тура от оптимума или nil, если оно не определено
+func gapOf(c *aco.Colony) *float64 {
+	if gap, ok := c.Gap(); ok {
+		return &gap
+	}
+	return nil
+}
+
+func runACO(ctx context.Context, params any, graph *aco.Instance, steps int, t *trajectory) (runResult, error) {
+	c, err := newColony(params, graph)
+	if err != nil {
+		return runResult{}, err
+	}
+	if steps == 0 {
+		steps = c.Params().MaxIterations
+	}
+
+	var frames []acoFrame
+	bestIteration := 0
+	_, best := c.Best()
+	for c.Iteration() < steps && c.Step() {
+		if err := ctx.Err(); err != nil {
+			return runResult{}, err
+		}
+		_, length := c.Best()
+		if length < best {
+			best, bestIteration = length, c.Iteration()
+		}
+		if t != nil && c.Iteration()%t.Every == 0 {
+			_, current := c.CurrentBest()
+			frames = append(frames, acoFrame{c.Iteration(), aco.Length(length), aco.Length(current), gapOf(c)})
+		}
+	}
+
+	path, _ := c.Best()
+	_, current := c.CurrentBest()
+	metrics := acoMetrics{
+		Iterations:        c.Iteration(),
+		BestPath:          path,
+		BestLength:        aco.Length(best),
+		BestIteration:     bestIteration,
+		CurrentBestLength: aco.Length(current),
+		GapPercent:        gapOf(c),
+	}
+	if optimum, ok := c.Optimum(); ok && c.Params().Objective == aco.Tour {
+		metrics.Optimum = &optimum
+	}
+	result := runResult{Steps: c.Iteration(), Metrics: metrics}
+	if t != nil {
+		result.Trajectory = frames
+	}
+	return result, nil
+}
+
+type boidsMetrics struct {
+	Step int `json:"step"`
+	boids.Order
+}
+
+// boidsFrame — параметры порядка и положения со скоростями [x, y, vx, vy] части особей
+type boidsFrame struct {
+	boidsMetrics
+	Boids [][4]float64 `json:"boids,omitempty"`
+}
+
+func runBoids(ctx context.Context, params any, _ *aco.Instance, steps int, t *trajectory) (runResult, error) {
+	f, err := boids.New(*params.(*boids.Params))
+	if err != nil {
+		return runResult{}, err
+	}
+
+	var frames []boidsFrame
+	for step := 1; step <= steps; step++ {
+		if err := ctx.Err(); err != nil {
+			return runResult{}, err
+		}
+		f.Step()
+		if t == nil || step%t.Every != 0 {
+			continue
+		}
+		frame := boidsFrame{boidsMetrics: boidsMetrics{step, f.Order()}}
+		all := f.Boids()
+		for _, i := range sample(len(all), t.Agents) {
+			b := all[i]
+			frame.Boids = append(frame.Boids, [4]float64{b.X.X, b.X.Y, b.V.X, b.V.Y})
+		}
+		frames = append(frames, frame)
+	}
+
+	result := runResult{Steps: steps, Metrics: boidsMetrics{steps, f.Order()}}
+	if t != nil {
+		result.Trajectory = frames
+	}
+	return result, nil
+}
+
+// sdsFrame — показатели шага и гипотезы части агентов
+type sdsFrame struct {
+	sds.Metrics
+	Agents [][2]float64 `json:"agents,omitempty"`
+}
+
+func runSDS(ctx context.Context, params any, _ *aco.Instance, steps int, t *trajectory) (runResult, error) {
+	s, err := sds.New(*params.(*sds.Params))
+	if err != nil {
+		return runResult{}, err
+	}
+
+	var frames []sdsFrame
+	for step := 1; step <= steps; step++ {
+		if err := ctx.Err(); err != nil {
+			return runResult{}, err
+		}
+		s.Step()
+		if t == nil || step%t.Every != 0 {
+			continue
+		}
+		frame := sdsFrame{Metrics: s.Metrics()}
+		agents := s.Agents()
+		for _, i := range sample(len(agents), t.Agents) {
+			frame.Agents = append(frame.Agents, [2]float64{agents[i].X, agents[i].Y})
+		}
+		frames = append(frames, frame)
+	}
+
+	result := runResult{Steps: steps, Metrics: s.Metrics()}
+	if t != nil {
+		result.Trajectory = frames
+	}
+	return result, nil
+}
+
+// sample возвращает до k равномерно расставленных номеров из n; одни и те же номера
+// во всех кадрах позволяют проследить за агентом
+func sample(n, k int) []int {
+	k = min(k, n)
+	indices := make([]int, k)
+	for i := range indices {
+		indices[i] = i * n / k
+	}
+	return indices
+}
+
+// fieldNames возвращает множество имен полей JSON структуры параметров
+func fieldNames(params any) map[string]bool {
+	names := make(map[string]bool)
+	for _, name := range orderedFields(params) {
+		names[name] = true
+	}
+	return names
+}
+
+// orderedFields возвращает имена полей JSON в порядке объявления
+func orderedFields(params any) []string {
+	t := reflect.TypeOf(params).Elem()
+	names := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// fieldValue возвращает значение поля с именем JSON name или nil
+func fieldValue(params any, name string) any {
+	v := reflect.ValueOf(params).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		if tag, _, _ := strings.Cut(v.Type().Field(i).Tag.Get("json"), ","); tag == name {
+			return v.Field(i).Interface()
+		}
+	}
+	return nil
+}
@@ -0,0 +1,654 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/RiddlerXenon/roi/aco"
+)
+
+// Пределы скорости сеанса: кадров в секунду и шагов движка на кадр
+const (
+	defaultFPS       = 30
+	maxFPS           = 60
+	maxStepsPerFrame = 100
+)
+
+// keepAlive — интервал комментариев в потоке, чтобы прокси не закрывали молчащее соединение
+const keepAlive = 15 * time.Second
+
+// pheromoneTolerance — относительное изменение феромона, после которого ребро попадает
+// в pheromoneDelta; меньшие изменения копятся до следующего превышения
+const pheromoneTolerance = 0.01
+
+// sessionRequest — тело запроса на создание сеанса
+type sessionRequest struct {
+	Params        map[string]any `json:"params"`
+	Graph         string         `json:"graph"`
+	FPS           int            `json:"fps"`
+	StepsPerFrame int            `json:"stepsPerFrame"`
+	Agents        int            `json:"agents"`
+	Paused        bool           `json:"paused"`
+}
+
+// control — команда сеансу: pause, resume, step, seek, params, reset или speed
+type control struct {
+	Action        string         `json:"action"`
+	Steps         int            `json:"steps"`
+	Step          int            `json:"step"`
+	Params        map[string]any `json:"params"`
+	FPS           int            `json:"fps"`
+	StepsPerFrame int            `json:"stepsPerFrame"`
+}
+
+// sessionStatus — состояние сеанса в ответах на создание и команды
+type sessionStatus struct {
+	ID            string   `json:"id"`
+	Algorithm     string   `json:"algorithm"`
+	EngineVersion int      `json:"engineVersion"`
+	Step          int      `json:"step"`
+	Paused        bool     `json:"paused"`
+	FPS           int      `json:"fps"`
+	StepsPerFrame int      `json:"stepsPerFrame"`
+	Agents        int      `json:"agents"`
+	Params        any      `json:"params"`
+	Graph         string   `json:"graph,omitempty"`
+	Ignored       []string `json:"ignored,omitempty"`
+}
+
+// command — команда с параметрами, уже проверенными обработчиком запроса
+type command struct {
+	control
+	params any
+	reply  chan commandReply
+}
+
+type commandReply struct {
+	status sessionStatus
+	err    error
+}
+
+// change — смена параметров перед шагом step. По журналу смен перемотка назад
+// повторяет прогон с начала, а воспроизведение после нее — записанные смены
+type change struct {
+	step   int
+	params any
+}
+
+// published — снимок сеанса для потоков. changed закрывается, когда появляется следующий
+type published struct {
+	status  sessionStatus
+	epoch   int
+	state   simState
+	changed chan struct{}
+}
+
+// session — симуляция, которую шагает собственная горутина. Потоки читают только
+// последний снимок, поэтому медленный клиент пропускает кадры, а не тормозит сеанс
+type session struct {
+	id   string
+	name string
+	rn   runner
+
+	// graph — граф из файла, на котором идет сеанс aco, в том числе после перезапусков
+	graph     *aco.Instance
+	graphName string
+
+	commands chan command
+	done     chan struct{}
+	cancel   context.CancelFunc
+
+	mu     sync.Mutex
+	latest *published
+
+	viewers  atomic.Int32
+	lastUsed atomic.Int64
+
+	// Поля ниже меняет только горутина сеанса
+	sim     simulation
+	params  any
+	initial any
+	changes []change
+	applied int
+	paused  bool
+	fps     int
+	perTick int
+	agents  int
+
+	// epoch растет, когда состояние перестроено (перемотка, сброс, перезапуск):
+	// следующий кадр каждого потока опорный
+	epoch int
+}
+
+// sessions — открытые сеансы сервера
+type sessions struct {
+	mu   sync.Mutex
+	byID map[string]*session
+}
+
+func (s *Server) handleCreateSession(w http.ResponseWriter, r *http.Request) {
+	name, rn, ok := lookupRunner(w, r)
+	if !ok {
+		return
+	}
+
+	var req sessionRequest
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBody))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("разбор запроса: %w", err))
+		return
+	}
+	if req.FPS == 0 {
+		req.FPS = defaultFPS
+	}
+	if req.StepsPerFrame == 0 {
+		req.StepsPerFrame = 1
+	}
+	if err := checkSpeed(req.FPS, req.StepsPerFrame); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if req.Agents < 0 {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("agents = %d: число агентов не может быть отрицательным", req.Agents))
+		return
+	}
+
+	graph, err := s.lookupGraph(name, req.Graph)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	fields, ignored, err := s.translate(name, rn, req.Params)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	params, err := rn.decode(fields, 0)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	params = onGraph(params, graph)
+	if err := s.checkSize(params); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	sim, err := rn.live(params, graph)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	sess := &session{
+		id:        newSessionID(),
+		name:      name,
+		rn:        rn,
+		graph:     graph,
+		graphName: req.Graph,
+		commands:  make(chan command),
+		done:      make(chan struct{}),
+		sim:       sim,
+		params:    params,
+		initial:   params,
+		paused:    req.Paused,
+		fps:       req.FPS,
+		perTick:   req.StepsPerFrame,
+		agents:    req.Agents,
+	}
+	sess.touch()
+	sess.publish()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	sess.cancel = cancel
+	s.sessions.mu.Lock()
+	if len(s.sessions.byID) >= s.config.MaxSessions {
+		s.sessions.mu.Unlock()
+		cancel()
+		writeError(w, http.StatusServiceUnavailable, fmt.Errorf("открыто %d сеансов: больше сервер не ведет", s.config.MaxSessions))
+		return
+	}
+	s.sessions.byID[sess.id] = sess
+	s.sessions.mu.Unlock()
+
+	go func() {
+		sess.run(ctx, s.config.SessionIdle, s.config.MaxSteps)
+		s.sessions.mu.Lock()
+		delete(s.sessions.byID, sess.id)
+		s.sessions.mu.Unlock()
+	}()
+
+	status := sess.snapshot().status
+	status.Ignored = ignored
+	writeJSON(w, http.StatusCreated, status)
+}
+
+func (s *Server) handleSessionStatus(w http.ResponseWriter, r *http.Request) {
+	if sess, ok := s.lookupSession(w, r); ok {
+		writeJSON(w, http.StatusOK, sess.snapshot().status)
+	}
+}
+
+func (s *Server) handleControl(w http.ResponseWriter, r *http.Request) {
+	sess, ok := s.lookupSession(w, r)
+	if !ok {
+		return
+	}
+	sess.touch()
+
+	var c control
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBody))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&c); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("разбор команды: %w", err))
+		return
+	}
+
+	cmd := command{control: c, reply: make(chan commandReply, 1)}
+	if c.Action == "params" {
+		fields, _, err := s.translate(sess.name, sess.rn, c.Params)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		if cmd.params, err = sess.rn.merge(sess.snapshot().status.Params, fields); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		// Число вершин и тип графа из файла не меняются командой
+		cmd.params = onGraph(cmd.params, sess.graph)
+		if err := s.checkSize(cmd.params); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	select {
+	case sess.commands <- cmd:
+	case <-sess.done:
+		writeError(w, http.StatusGone, errors.New("сеанс закрыт"))
+		return
+	case <-r.Context().Done():
+		return
+	}
+	select {
+	case reply := <-cmd.reply:
+		if reply.err != nil {
+			writeError(w, http.StatusBadRequest, reply.err)
+			return
+		}
+		writeJSON(w, http.StatusOK, reply.status)
+	case <-r.Context().Done():
+	}
+}
+
+func (s *Server) handleCloseSession(w http.ResponseWriter, r *http.Request) {
+	sess, ok := s.lookupSession(w, r)
+	if !ok {
+		return
+	}
+	sess.cancel()
+	<-sess.done
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleEvents отправляет кадры событиями frame в формате text/event-stream.
+// Первый кадр потока и кадр после перестройки состояния опорные: в них граф aco и вся
+// матрица феромона, в остальных — только заметно изменившиеся ребра
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	sess, ok := s.lookupSession(w, r)
+	if !ok {
+		return
+	}
+	sess.viewers.Add(1)
+	defer func() {
+		sess.touch()
+		sess.viewers.Add(-1)
+	}()
+
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	ping := time.NewTicker(keepAlive)
+	defer ping.Stop()
+	var v viewer
+	var sent *published
+	for {
+		p := sess.snapshot()
+		if p != sent {
+			data, err := json.Marshal(v.frame(p))
+			if err != nil {
+				log.Printf("Ошибка кодирования кадра: %v", err)
+				return
+			}
+			if _, err := fmt.Fprintf(w, "event: frame\ndata: %s\n\n", data); err != nil {
+				return
+			}
+			if err := rc.Flush(); err != nil {
+				return
+			}
+			sent = p
+		}
+
+		select {
+		case <-p.changed:
+		case <-ping.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		case <-sess.done:
+			fmt.Fprint(w, "event: end\ndata: {}\n\n")
+			rc.Flush()
+			return
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// lookupSession находит сеанс по пути запроса или отвечает 404
+func (s *Server) lookupSession(w http.ResponseWriter, r *http.Request) (*session, bool) {
+	id := r.PathValue("id")
+	s.sessions.mu.Lock()
+	sess, ok := s.sessions.byID[id]
+	s.sessions.mu.Unlock()
+	if !ok || sess.name != r.PathValue("algorithm") {
+		writeError(w, http.StatusNotFound, fmt.Errorf("сеанс %s %q не найден", r.PathValue("algorithm"), id))
+		return nil, false
+	}
+	return sess, true
+}
+
+func newSessionID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func checkSpeed(fps, perTick int) error {
+	switch {
+	case fps < 1 || fps > maxFPS:
+		return fmt.Errorf("fps = %d: ожидается от 1 до %d", fps, maxFPS)
+	case perTick < 1 || perTick > maxStepsPerFrame:
+		return fmt.Errorf("stepsPerFrame = %d: ожидается от 1 до %d", perTick, maxStepsPerFrame)
+	}
+	return nil
+}
+
+// run шагает симуляцию по таймеру и выполняет команды, пока сеанс не закроют
+// или он не простоит без потоков и команд дольше idle
+func (s *session) run(ctx context.Context, idle time.Duration, maxSteps int) {
+	defer close(s.done)
+	ticker := time.NewTicker(s.interval())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case cmd := <-s.commands:
+			err := s.apply(cmd, maxSteps)
+			if err == nil {
+				ticker.Reset(s.interval())
+				s.publish()
+			}
+			cmd.reply <- commandReply{s.snapshot().status, err}
+		case <-ticker.C:
+			if s.viewers.Load() == 0 && time.Since(time.Unix(0, s.lastUsed.Load())) > idle {
+				return
+			}
+			if !s.paused {
+				s.advance(s.perTick)
+				s.publish()
+			}
+		}
+	}
+}
+
+func (s *session) interval() time.Duration {
+	return time.Second / time.Duration(s.fps)
+}
+
+// apply выполняет команду в горутине сеанса
+func (s *session) apply(cmd command, maxSteps int) error {
+	switch cmd.Action {
+	case "pause":
+		s.paused = true
+	case "resume":
+		s.paused = false
+	case "step":
+		steps := cmd.Steps
+		if steps == 0 {
+			steps = 1
+		}
+		if steps < 1 || steps > maxSteps {
+			return fmt.Errorf("steps = %d: ожидается от 1 до %d", steps, maxSteps)
+		}
+		s.paused = true
+		s.advance(steps)
+	case "seek":
+		if cmd.Step < 0 || cmd.Step > maxSteps {
+			return fmt.Errorf("step = %d: ожидается от 0 до %d", cmd.Step, maxSteps)
+		}
+		return s.seek(cmd.Step)
+	case "params":
+		if err := s.sim.setParams(cmd.params); err != nil {
+			// Параметр начального состояния: прогон начинается заново
+			return s.restart(cmd.params)
+		}
+		s.changes = append(s.changes[:s.applied], change{s.sim.steps(), cmd.params})
+		s.applied = len(s.changes)
+		s.params = cmd.params
+	case "reset":
+		return s.restart(s.params)
+	case "speed":
+		fps, perTick := orCurrent(cmd.FPS, s.fps), orCurrent(cmd.StepsPerFrame, s.perTick)
+		if err := checkSpeed(fps, perTick); err != nil {
+			return err
+		}
+		s.fps, s.perTick = fps, perTick
+	default:
+		return fmt.Errorf("неизвестная команда %q: ожидается pause, resume, step, seek, params, reset или speed", cmd.Action)
+	}
+	return nil
+}
+
+// orCurrent возвращает v, если оно задано, иначе текущее значение
+func orCurrent(v, current int) int {
+	if v == 0 {
+		return current
+	}
+	return v
+}
+
+// advance выполняет до steps шагов, применяя записанные смены параметров. Исчерпанный
+// бюджет итераций ставит сеанс на паузу
+func (s *session) advance(steps int) {
+	for range steps {
+		s.catchUp()
+		if !s.sim.step() {
+			s.paused = true
+			break
+		}
+	}
+	s.catchUp()
+}
+
+// catchUp применяет смены параметров, записанные для текущего шага
+func (s *session) catchUp() {
+	for s.applied < len(s.changes) && s.changes[s.applied].step <= s.sim.steps() {
+		params := s.changes[s.applied].params
+		if err := s.sim.setParams(params); err != nil {
+			// Смена уже проходила на этом прогоне; ошибка означала бы расхождение с журналом
+			log.Printf("Сеанс %s: повтор смены параметров: %v", s.id, err)
+		}
+		s.params = params
+		s.applied++
+	}
+}
+
+// seek переходит к шагу target. Назад движок строится заново и повторяет прогон
+// с журналом смен: ход симуляции детерминирован, поэтому состояние совпадает с пройденным
+func (s *session) seek(target int) error {
+	if target < s.sim.steps() {
+		sim, err := s.rn.live(s.initial, s.graph)
+		if err != nil {
+			return err
+		}
+		s.sim, s.params, s.applied = sim, s.initial, 0
+	}
+	paused := s.paused
+	s.advance(target - s.sim.steps())
+	s.paused = paused || s.paused
+	s.epoch++
+	return nil
+}
+
+// restart начинает прогон заново с параметрами params и пустым журналом смен
+func (s *session) restart(params any) error {
+	sim, err := s.rn.live(params, s.graph)
+	if err != nil {
+		return err
+	}
+	s.sim, s.params, s.initial = sim, params, params
+	s.changes, s.applied = nil, 0
+	s.epoch++
+	return nil
+}
+
+func (s *session) status() sessionStatus {
+	return sessionStatus{
+		ID:            s.id,
+		Algorithm:     s.name,
+		EngineVersion: s.rn.engineVersion,
+		Step:          s.sim.steps(),
+		Paused:        s.paused,
+		FPS:           s.fps,
+		StepsPerFrame: s.perTick,
+		Agents:        s.agents,
+		Params:        s.params,
+		Graph:         s.graphName,
+	}
+}
+
+// publish заменяет снимок сеанса и будит потоки
+func (s *session) publish() {
+	p := &published{status: s.status(), epoch: s.epoch, state: s.sim.state(s.agents), changed: make(chan struct{})}
+	s.mu.Lock()
+	old := s.latest
+	s.latest = p
+	s.mu.Unlock()
+	if old != nil {
+		close(old.changed)
+	}
+}
+
+func (s *session) snapshot() *published {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.latest
+}
+
+func (s *session) touch() {
+	s.lastUsed.Store(time.Now().UnixNano())
+}
+
+// frame — кадр потока. Params приходит в опорном кадре и после смены параметров
+type frame struct {
+	Step           int              `json:"step"`
+	Paused         bool             `json:"paused"`
+	Keyframe       bool             `json:"keyframe,omitempty"`
+	Params         any              `json:"params,omitempty"`
+	Metrics        any              `json:"metrics"`
+	Agents         any              `json:"agents,omitempty"`
+	Tracked        [][2]float64     `json:"tracked,omitempty"`
+	Graph          *aco.Graph       `json:"graph,omitempty"`
+	Pheromone      [][]significant  `json:"pheromone,omitempty"`
+	PheromoneDelta []pheromoneDelta `json:"pheromoneDelta,omitempty"`
+}
+
+// significant — число, которое кодируется четырьмя значащими цифрами
+type significant float64
+
+func (v significant) MarshalJSON() ([]byte, error) {
+	return strconv.AppendFloat(nil, float64(v), 'g', 4, 64), nil
+}
+
+// pheromoneDelta — новое значение феромона на ребре, кодируется как [i, j, v]
+type pheromoneDelta struct {
+	i, j int
+	v    float64
+}
+
+func (d pheromoneDelta) MarshalJSON() ([]byte, error) {
+	b := []byte{'['}
+	b = strconv.AppendInt(b, int64(d.i), 10)
+	b = append(b, ',')
+	b = strconv.AppendInt(b, int64(d.j), 10)
+	b = append(b, ',')
+	b = strconv.AppendFloat(b, d.v, 'g', 4, 64)
+	return append(b, ']'), nil
+}
+
+// viewer помнит, что получил один поток: эпоху, параметры и отправленный феромон
+type viewer struct {
+	started   bool
+	epoch     int
+	params    any
+	pheromone [][]float64
+}
+
+func (v *viewer) frame(p *published) frame {
+	f := frame{
+		Step:    p.status.Step,
+		Paused:  p.status.Paused,
+		Metrics: p.state.Metrics,
+		Agents:  p.state.Agents,
+		Tracked: p.state.Tracked,
+	}
+	keyframe := !v.started || p.epoch != v.epoch
+	if keyframe || p.status.Params != v.params {
+		f.Params = p.status.Params
+	}
+	v.started, v.epoch, v.params = true, p.epoch, p.status.Params
+
+	if keyframe {
+		f.Keyframe = true
+		f.Graph = p.state.Graph
+		v.pheromone = make([][]float64, len(p.state.Pheromone))
+		for i, row := range p.state.Pheromone {
+			v.pheromone[i] = append([]float64(nil), row...)
+			f.Pheromone = append(f.Pheromone, make([]significant, len(row)))
+			for j, value := range row {
+				f.Pheromone[i][j] = significant(value)
+			}
+		}
+		return f
+	}
+
+	for i, row := range p.state.Pheromone {
+		for j, value := range row {
+			if old := v.pheromone[i][j]; math.Abs(value-old) > pheromoneTolerance*old {
+				f.PheromoneDelta = append(f.PheromoneDelta, pheromoneDelta{i, j, value})
+				v.pheromone[i][j] = value
+			}
+		}
+	}
+	return f
+}
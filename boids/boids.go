@@ -0,0 +1,407 @@
+// Package boids — модель стаи Рейнольдса без браузера.
+//
+// Правила повторяют static/js/boids.js: выравнивание, центрирование и разделение заданы
+// ускорениями с временами релаксации tauMatch, tauCenter и tauSep, соседи для первых двух
+// выбираются в секторе обзора fovDeg по радиусу r или как kTopo ближайших, разделение
+// действует в изотропной зоне r_sep. Особи обновляются по очереди, как в цикле animate:
+// каждая видит уже сдвинутых предшественников. Вместо Math.random начальное состояние
+// задает генератор с зерном Seed, поэтому прогон воспроизводим
+package boids
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"slices"
+)
+
+// Algorithm — имя алгоритма в спецификациях и манифестах прогонов
+const Algorithm = "boids"
+
+// EngineVersion — версия модели, записываемая в манифесты прогонов
+const EngineVersion = 1
+
+// NeighborMode — схема соседства для выравнивания и центрирования
+type NeighborMode string
+
+const (
+	// Metric — все особи в секторе обзора не дальше r
+	Metric NeighborMode = "metric"
+	// Topological — kTopo ближайших особей в секторе обзора на любом расстоянии
+	Topological NeighborMode = "topo"
+)
+
+// eps — порог малых величин из boids.js
+const eps = 1e-6
+
+// Params — параметры модели; имена JSON совпадают с static/latex/params/boids.tex
+type Params struct {
+	Dt    float64 `json:"dt"`
+	VMax  float64 `json:"v_max"`
+	AMax  float64 `json:"a_max"`
+	VPref float64 `json:"v_pref"`
+
+	TauMatch  float64 `json:"tauMatch"`
+	TauCenter float64 `json:"tauCenter"`
+	TauSep    float64 `json:"tauSep"`
+	KSep      float64 `json:"k_sep"`
+
+	DampMode bool    `json:"dampMode"`
+	Gamma    float64 `json:"gamma"`
+
+	NeighborMode NeighborMode `json:"neighborMode"`
+	R            float64      `json:"r"`
+	RSep         float64      `json:"r_sep"`
+	FovDeg       float64      `json:"fovDeg"`
+	KTopo        int          `json:"kTopo"`
+
+	WMatch  float64 `json:"w.match"`
+	WCenter float64 `json:"w.center"`
+	WSep    float64 `json:"w.sep"`
+
+	BoidCount int `json:"boidCount"`
+
+	// Width и Height — размер области; Walls включает отражающие границы вместо тора
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+	Walls  bool    `json:"walls"`
+
+	Seed uint64 `json:"seed"`
+}
+
+// DefaultParams возвращает параметры страницы templates/boids.html на холсте 1280×720
+func DefaultParams() Params {
+	return Params{
+		Dt:           1.0,
+		VMax:         2.5,
+		AMax:         0.05,
+		VPref:        1.5,
+		TauMatch:     1.0,
+		TauCenter:    1.2,
+		TauSep:       0.6,
+		KSep:         1.0,
+		DampMode:     false,
+		Gamma:        0.0,
+		NeighborMode: Metric,
+		R:            60,
+		RSep:         30,
+		FovDeg:       135,
+		KTopo:        7,
+		WMatch:       1.0,
+		WCenter:      0.8,
+		WSep:         1.2,
+		BoidCount:    100,
+		Width:        1280,
+		Height:       720,
+		Walls:        true,
+		Seed:         42,
+	}
+}
+
+// Validate проверяет, что параметры задают корректную модель
+func (p Params) Validate() error {
+	switch {
+	case !(p.Dt > 0):
+		return fmt.Errorf("dt = %v: шаг интегрирования должен быть положительным", p.Dt)
+	case !(p.VMax > 0):
+		return fmt.Errorf("v_max = %v: ограничение скорости должно быть положительным", p.VMax)
+	case !(p.AMax >= 0):
+		return fmt.Errorf("a_max = %v: ограничение ускорения не может быть отрицательным", p.AMax)
+	case !(p.R >= 0) || !(p.RSep >= 0):
+		return fmt.Errorf("r = %v, r_sep = %v: радиусы не могут быть отрицательными", p.R, p.RSep)
+	case !(p.FovDeg > 0 && p.FovDeg <= 360):
+		return fmt.Errorf("fovDeg = %v: угол обзора должен лежать в (0, 360]", p.FovDeg)
+	case p.NeighborMode != Metric && p.NeighborMode != Topological:
+		return fmt.Errorf("neighborMode = %q: ожидается %q или %q", p.NeighborMode, Metric, Topological)
+	case p.KTopo < 0:
+		return fmt.Errorf("kTopo = %d: число соседей не может быть отрицательным", p.KTopo)
+	case p.BoidCount < 0:
+		return fmt.Errorf("boidCount = %d: число особей не может быть отрицательным", p.BoidCount)
+	case !(p.Width > 0 && p.Height > 0):
+		return fmt.Errorf("размер области %v×%v должен быть положительным", p.Width, p.Height)
+	}
+	return nil
+}
+
+// Boid — положение и скорость особи
+type Boid struct {
+	X Vec `json:"x"`
+	V Vec `json:"v"`
+}
+
+// Flock — состояние стаи
+type Flock struct {
+	params Params
+	boids  []Boid
+	step   int
+
+	// cosHalf — косинус половины угла обзора; при обзоре 360° сектор не проверяется
+	cosHalf float64
+	fullFov bool
+
+	grid *grid
+
+	// Буферы для поиска соседей
+	nearby    []int
+	neighbors []int
+	distances []float64
+}
+
+// New создает стаю со случайными положениями и направлениями, как конструктор Boid в boids.js:
+// особи равномерно распределены по области и движутся со скоростью v_max/2
+func New(p Params) (*Flock, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+
+	rng := rand.New(rand.NewPCG(p.Seed, 0))
+	phi := p.FovDeg * math.Pi / 180
+	f := &Flock{
+		params:  p,
+		boids:   make([]Boid, p.BoidCount),
+		cosHalf: math.Cos(phi / 2),
+		fullFov: phi >= 2*math.Pi,
+	}
+	for i := range f.boids {
+		f.boids[i].X = Vec{rng.Float64() * p.Width, rng.Float64() * p.Height}
+		dir := Vec{rng.Float64()*2 - 1, rng.Float64()*2 - 1}.Norm()
+		f.boids[i].V = dir.Scale(0.5 * p.VMax)
+	}
+
+	// Метрическое соседство и разделение ищут соседей в сетке; топологическому нужен полный обход
+	if p.NeighborMode == Metric {
+		f.grid = newGrid(p.Width, p.Height, max(p.R, p.RSep), f.boids)
+	}
+	return f, nil
+}
+
+// Step сдвигает все особи на один шаг dt
+func (f *Flock) Step() {
+	for i := range f.boids {
+		a := f.acceleration(i)
+
+		b := &f.boids[i]
+		old := b.X
+		b.V = b.V.Add(a.Scale(f.params.Dt)).Clip(f.params.VMax)
+		b.X = b.X.Add(b.V.Scale(f.params.Dt))
+		f.edges(b)
+
+		if f.grid != nil {
+			f.grid.move(i, old, b.X)
+		}
+	}
+	f.step++
+}
+
+// Run выполняет steps шагов
+func (f *Flock) Run(steps int) {
+	for range steps {
+		f.Step()
+	}
+}
+
+// acceleration суммирует ускорения правил с весами и демпфированием и ограничивает результат a_max
+func (f *Flock) acceleration(i int) Vec {
+	p := f.params
+	self := f.boids[i]
+	neighbors := f.neighborsForMatchCenter(i)
+
+	var match, center Vec
+	if len(neighbors) > 0 {
+		var avgV, sum Vec
+		for _, j := range neighbors {
+			avgV = avgV.Add(f.boids[j].V)
+			sum = sum.Add(f.boids[j].X)
+		}
+		avgV = avgV.Div(float64(len(neighbors)))
+		speed := min(p.VPref, p.VMax)
+
+		// Если средняя скорость почти нулевая, особь держит текущую скорость
+		desired := self.V
+		if avgV.Len() >= eps {
+			desired = avgV.WithLen(speed)
+		}
+		match = desired.Sub(self.V).Scale(1 / max(p.TauMatch, eps))
+
+		toC := sum.Div(float64(len(neighbors))).Sub(self.X)
+		if toC.Len() >= eps {
+			center = toC.WithLen(speed).Sub(self.V).Scale(1 / max(p.TauCenter, eps))
+		}
+	}
+
+	sep := f.separation(i)
+
+	var damp Vec
+	if p.DampMode {
+		damp = self.V.Scale(-p.Gamma)
+	}
+
+	total := sep.Scale(p.WSep).Add(match.Scale(p.WMatch).Add(center.Scale(p.WCenter))).Add(damp)
+	return total.Clip(p.AMax)
+}
+
+// separation возвращает ускорение разделения: отталкивание с весом r_sep/d − 1 от всех в зоне d < r_sep
+func (f *Flock) separation(i int) Vec {
+	p := f.params
+	self := f.boids[i].X
+
+	var force Vec
+	for _, j := range f.candidates(i, p.RSep) {
+		d := f.boids[j].X.Sub(self)
+		dist := d.Len()
+		if dist > 0 && dist < p.RSep {
+			w := max(0, p.RSep/dist-1)
+			force = force.Add(d.Norm().Scale(-1).Scale(w))
+		}
+	}
+	if force.X == 0 && force.Y == 0 {
+		return Vec{}
+	}
+	return force.Scale(p.KSep / max(p.TauSep, eps))
+}
+
+// neighborsForMatchCenter возвращает соседей особи i для выравнивания и центрирования:
+// при метрическом соседстве в порядке номеров, при топологическом — по возрастанию расстояния,
+// в том же порядке, в каком boids.js суммирует их скорости и положения
+func (f *Flock) neighborsForMatchCenter(i int) []int {
+	f.neighbors = f.neighbors[:0]
+	if f.params.NeighborMode == Metric {
+		for _, j := range f.candidates(i, f.params.R) {
+			if f.inFOV(i, j, f.params.R) {
+				f.neighbors = append(f.neighbors, j)
+			}
+		}
+		return f.neighbors
+	}
+
+	// kTopo ближайших: вставка с сохранением порядка номеров при равных расстояниях,
+	// как устойчивая сортировка в boids.js
+	k := f.params.KTopo
+	f.distances = f.distances[:0]
+	for j := range f.boids {
+		if j == i || !f.inFOV(i, j, math.Inf(1)) {
+			continue
+		}
+		d := f.boids[j].X.Sub(f.boids[i].X).Len()
+		if len(f.neighbors) == k && (k == 0 || d >= f.distances[k-1]) {
+			continue
+		}
+		pos := len(f.distances)
+		for pos > 0 && f.distances[pos-1] > d {
+			pos--
+		}
+		if len(f.neighbors) < k {
+			f.neighbors = append(f.neighbors, 0)
+			f.distances = append(f.distances, 0)
+		}
+		copy(f.neighbors[pos+1:], f.neighbors[pos:])
+		copy(f.distances[pos+1:], f.distances[pos:])
+		f.neighbors[pos], f.distances[pos] = j, d
+	}
+	return f.neighbors
+}
+
+// candidates возвращает в порядке номеров особи, которые могут оказаться не дальше radius от i
+func (f *Flock) candidates(i int, radius float64) []int {
+	f.nearby = f.nearby[:0]
+	if f.grid == nil || radius > f.grid.cell {
+		for j := range f.boids {
+			if j != i {
+				f.nearby = append(f.nearby, j)
+			}
+		}
+		return f.nearby
+	}
+
+	f.nearby = f.grid.around(f.boids[i].X, f.nearby)
+	f.nearby = slices.DeleteFunc(f.nearby, func(j int) bool { return j == i })
+	slices.Sort(f.nearby)
+	return f.nearby
+}
+
+// inFOV проверяет, видит ли особь i особь j в секторе обзора не дальше radius
+func (f *Flock) inFOV(i, j int, radius float64) bool {
+	self := f.boids[i]
+	toOther := f.boids[j].X.Sub(self.X)
+	d := toOther.Len()
+	if d == 0 || d > radius {
+		return false
+	}
+	if f.fullFov {
+		return true
+	}
+
+	// При нулевой скорости обзор изотропный
+	speed := self.V.Len()
+	if speed == 0 {
+		return true
+	}
+	return self.V.Dot(toOther) >= speed*d*f.cosHalf
+}
+
+// edges отражает особь от стенок или переносит на противоположный край тора
+func (f *Flock) edges(b *Boid) {
+	w, h := f.params.Width, f.params.Height
+	if !f.params.Walls {
+		switch {
+		case b.X.X < 0:
+			b.X.X = w
+		case b.X.X > w:
+			b.X.X = 0
+		}
+		switch {
+		case b.X.Y < 0:
+			b.X.Y = h
+		case b.X.Y > h:
+			b.X.Y = 0
+		}
+		return
+	}
+
+	if b.X.X < 0 {
+		b.X.X, b.V.X = 0, -b.V.X
+	}
+	if b.X.X > w {
+		b.X.X, b.V.X = w, -b.V.X
+	}
+	if b.X.Y < 0 {
+		b.X.Y, b.V.Y = 0, -b.V.Y
+	}
+	if b.X.Y > h {
+		b.X.Y, b.V.Y = h, -b.V.Y
+	}
+}
+
+// Params возвращает параметры стаи
+func (f *Flock) Params() Params { return f.params }
+
+// SetParams меняет правила и восприятие стаи на ходу; особи сохраняют положения
+// и скорости. boidCount, seed и размер области задают начальное состояние, их смена
+// требует новой стаи
+func (f *Flock) SetParams(p Params) error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	old := f.params
+	if p.BoidCount != old.BoidCount || p.Seed != old.Seed || p.Width != old.Width || p.Height != old.Height {
+		return errors.New("boidCount, seed, width и height задают начальное состояние: их смена требует новой стаи")
+	}
+
+	f.params = p
+	phi := p.FovDeg * math.Pi / 180
+	f.cosHalf, f.fullFov = math.Cos(phi/2), phi >= 2*math.Pi
+	if p.NeighborMode != old.NeighborMode || p.R != old.R || p.RSep != old.RSep {
+		f.grid = nil
+		if p.NeighborMode == Metric {
+			f.grid = newGrid(p.Width, p.Height, max(p.R, p.RSep), f.boids)
+		}
+	}
+	return nil
+}
+
+// Boids возвращает текущее состояние особей; срез нельзя изменять
+func (f *Flock) Boids() []Boid { return f.boids }
+
+// Steps возвращает число выполненных шагов
+func (f *Flock) Steps() int { return f.step }
@@ -0,0 +1,131 @@
+package boids
+
+import (
+	"math/rand/v2"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+// gridTestParams — тор 300×200 с ячейкой сетки 30: особи часто лежат на границах ячеек
+func gridTestParams() Params {
+	p := DefaultParams()
+	p.Width, p.Height = 300, 200
+	p.R, p.RSep = 30, 15
+	p.FovDeg = 270
+	p.BoidCount = 60
+	p.Walls = false
+	p.Seed = 7
+	return p
+}
+
+// placeOnBorders ставит особей на границы ячеек, края области и в углы, где тор переносит
+// особь на противоположную сторону, и перестраивает сетку
+func placeOnBorders(f *Flock) {
+	w, h, cell := f.params.Width, f.params.Height, f.grid.cell
+	rng := rand.New(rand.NewPCG(1, 2))
+	points := []Vec{{0, 0}, {w, h}, {w, 0}, {0, h}, {cell, cell}, {2 * cell, cell}, {cell - 1e-9, cell},
+		{w - cell, h / 2}, {w, h / 2}, {0, h / 2}, {cell * 3, 0}, {cell * 3, h}}
+	for i := range f.boids {
+		if i < len(points) {
+			f.boids[i].X = points[i]
+			continue
+		}
+		// Остальные — на вертикальной или горизонтальной границе ячейки
+		x, y := rng.Float64()*w, rng.Float64()*h
+		if i%2 == 0 {
+			x = float64(rng.IntN(int(w/cell)+1)) * cell
+		} else {
+			y = float64(rng.IntN(int(h/cell)+1)) * cell
+		}
+		f.boids[i].X = Vec{x, y}
+	}
+	f.grid = newGrid(w, h, max(f.params.R, f.params.RSep), f.boids)
+}
+
+func TestGridMatchesBruteForce(t *testing.T) {
+	f, err := New(gridTestParams())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.grid.cell != 30 {
+		t.Fatalf("ячейка сетки %v, тест рассчитан на 30", f.grid.cell)
+	}
+	placeOnBorders(f)
+
+	for i := range f.boids {
+		var want []int
+		for j := range f.boids {
+			if j != i && f.inFOV(i, j, f.params.R) {
+				want = append(want, j)
+			}
+		}
+		got := slices.Clone(f.neighborsForMatchCenter(i))
+		if !slices.Equal(got, want) {
+			t.Errorf("особь %d в %v: соседи по сетке %v, полным перебором %v", i, f.boids[i].X, got, want)
+		}
+
+		withGrid := f.separation(i)
+		grid := f.grid
+		f.grid = nil
+		brute := f.separation(i)
+		f.grid = grid
+		if withGrid != brute {
+			t.Errorf("особь %d в %v: разделение по сетке %v, полным перебором %v", i, f.boids[i].X, withGrid, brute)
+		}
+	}
+}
+
+func TestGridStepsMatchBruteForce(t *testing.T) {
+	// Стая без сетки ищет соседей полным перебором, как boids.js; траектории должны совпасть
+	// бит в бит, в том числе после переноса через края тора
+	withGrid, err := New(gridTestParams())
+	if err != nil {
+		t.Fatal(err)
+	}
+	brute, err := New(gridTestParams())
+	if err != nil {
+		t.Fatal(err)
+	}
+	placeOnBorders(withGrid)
+	placeOnBorders(brute)
+	brute.grid = nil
+
+	for step := 1; step <= 300; step++ {
+		withGrid.Step()
+		brute.Step()
+		if !slices.Equal(withGrid.Boids(), brute.Boids()) {
+			t.Fatalf("шаг %d: состояния по сетке и полным перебором разошлись", step)
+		}
+	}
+}
+
+func TestDeterministic(t *testing.T) {
+	for _, mode := range []NeighborMode{Metric, Topological} {
+		p := DefaultParams()
+		p.NeighborMode, p.Seed = mode, 12345
+		a, err := New(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := New(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		a.Run(200)
+		b.Run(200)
+		if !reflect.DeepEqual(a.Snapshot(), b.Snapshot()) {
+			t.Errorf("%s: две стаи с зерном %d разошлись за 200 шагов", mode, p.Seed)
+		}
+
+		p.Seed++
+		c, err := New(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c.Run(200)
+		if reflect.DeepEqual(a.Snapshot().Boids, c.Snapshot().Boids) {
+			t.Errorf("%s: стаи с разными зернами совпали", mode)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package boids
+
+import (
+	"math"
+	"slices"
+)
+
+// grid — равномерная сетка над областью для поиска соседей за время, не зависящее от числа особей.
+// Сторона ячейки не меньше радиуса поиска, поэтому соседи лежат в ячейке особи и восьми соседних
+type grid struct {
+	cell       float64
+	cols, rows int
+	cells      [][]int
+}
+
+// newGrid раскладывает особи по ячейкам со стороной cell. При нулевом радиусе сетка не нужна
+func newGrid(width, height, cell float64, boids []Boid) *grid {
+	if !(cell > 0) {
+		return nil
+	}
+	// При малом радиусе ячейки укрупняются, чтобы их было не больше нескольких на особь
+	cell = max(cell, math.Sqrt(width*height/float64(4*max(len(boids), 1))))
+	g := &grid{
+		cell: cell,
+		cols: max(1, int(width/cell)+1),
+		rows: max(1, int(height/cell)+1),
+	}
+	g.cells = make([][]int, g.cols*g.rows)
+	for i, b := range boids {
+		k := g.index(b.X)
+		g.cells[k] = append(g.cells[k], i)
+	}
+	return g
+}
+
+// index возвращает номер ячейки точки; точки на границе области относятся к крайним ячейкам
+func (g *grid) index(x Vec) int {
+	col := min(max(int(x.X/g.cell), 0), g.cols-1)
+	row := min(max(int(x.Y/g.cell), 0), g.rows-1)
+	return row*g.cols + col
+}
+
+// move переносит особь i из ячейки точки from в ячейку точки to
+func (g *grid) move(i int, from, to Vec) {
+	a, b := g.index(from), g.index(to)
+	if a == b {
+		return
+	}
+	g.cells[a] = slices.DeleteFunc(g.cells[a], func(j int) bool { return j == i })
+	g.cells[b] = append(g.cells[b], i)
+}
+
+// around дописывает в dst особи из ячейки точки x и соседних с ней
+func (g *grid) around(x Vec, dst []int) []int {
+	k := g.index(x)
+	col, row := k%g.cols, k/g.cols
+	for r := max(row-1, 0); r <= min(row+1, g.rows-1); r++ {
+		for c := max(col-1, 0); c <= min(col+1, g.cols-1); c++ {
+			dst = append(dst, g.cells[r*g.cols+c]...)
+		}
+	}
+	return dst
+}
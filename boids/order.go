@@ -0,0 +1,47 @@
+package boids
+
+import "math"
+
+// Order — параметры порядка стаи
+type Order struct {
+	// Polarization — модуль среднего направления движения: 1 у стаи, летящей в одну сторону,
+	// около 0 у беспорядочного движения
+	Polarization float64 `json:"polarization"`
+
+	// Milling — модуль среднего углового момента направлений относительно центра стаи:
+	// близок к 1 при вращении вокруг центра
+	Milling float64 `json:"milling"`
+
+	// MeanSpeed — средняя скорость особей
+	MeanSpeed float64 `json:"meanSpeed"`
+}
+
+// Order вычисляет параметры порядка текущего состояния. Особи с нулевой скоростью
+// учитываются в знаменателе, но не задают направления
+func (f *Flock) Order() Order {
+	n := float64(len(f.boids))
+	if n == 0 {
+		return Order{}
+	}
+
+	var center Vec
+	for _, b := range f.boids {
+		center = center.Add(b.X)
+	}
+	center = center.Div(n)
+
+	var heading Vec
+	var momentum, speed float64
+	for _, b := range f.boids {
+		dir := b.V.Norm()
+		heading = heading.Add(dir)
+		momentum += b.X.Sub(center).Norm().Cross(dir)
+		speed += b.V.Len()
+	}
+
+	return Order{
+		Polarization: heading.Len() / n,
+		Milling:      math.Abs(momentum) / n,
+		MeanSpeed:    speed / n,
+	}
+}
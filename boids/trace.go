@@ -0,0 +1,55 @@
+package boids
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Snapshot — состояние стаи после шага Step
+type Snapshot struct {
+	Step  int    `json:"step"`
+	Order Order  `json:"order"`
+	Boids []Boid `json:"boids"`
+}
+
+// Trace — запись прогона: снимки после каждого Every-го шага. Страница boids.js
+// задает начальное состояние через Math.random, поэтому трассы браузера для
+// стаи не записываются и сверять их не с чем
+type Trace struct {
+	Algorithm string     `json:"algorithm"`
+	Params    Params     `json:"params"`
+	Every     int        `json:"every"`
+	Steps     []Snapshot `json:"steps"`
+}
+
+// Record выполняет steps шагов с параметрами p и сохраняет снимок после каждого every-го
+func Record(p Params, steps, every int) (Trace, error) {
+	trace, _, err := RecordFinal(p, steps, every)
+	return trace, err
+}
+
+// RecordFinal работает как Record и возвращает еще снимок после последнего шага,
+// даже если он не попал в трассу
+func RecordFinal(p Params, steps, every int) (Trace, Snapshot, error) {
+	if every < 1 {
+		return Trace{}, Snapshot{}, fmt.Errorf("every = %d: интервал снимков должен быть положительным", every)
+	}
+	f, err := New(p)
+	if err != nil {
+		return Trace{}, Snapshot{}, err
+	}
+
+	trace := Trace{Algorithm: Algorithm, Params: p, Every: every}
+	for step := 1; step <= steps; step++ {
+		f.Step()
+		if step%every == 0 {
+			trace.Steps = append(trace.Steps, f.Snapshot())
+		}
+	}
+	return trace, f.Snapshot(), nil
+}
+
+// Snapshot возвращает копию текущего состояния стаи
+func (f *Flock) Snapshot() Snapshot {
+	return Snapshot{Step: f.step, Order: f.Order(), Boids: slices.Clone(f.boids)}
+}
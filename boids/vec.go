@@ -0,0 +1,35 @@
+package boids
+
+import "math"
+
+// Vec — вектор на плоскости
+type Vec struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+func (a Vec) Add(b Vec) Vec         { return Vec{a.X + b.X, a.Y + b.Y} }
+func (a Vec) Sub(b Vec) Vec         { return Vec{a.X - b.X, a.Y - b.Y} }
+func (a Vec) Scale(s float64) Vec   { return Vec{a.X * s, a.Y * s} }
+func (a Vec) Div(s float64) Vec     { return Vec{a.X / s, a.Y / s} }
+func (a Vec) Dot(b Vec) float64     { return a.X*b.X + a.Y*b.Y }
+func (a Vec) Cross(b Vec) float64   { return a.X*b.Y - a.Y*b.X }
+func (a Vec) Len() float64          { return math.Hypot(a.X, a.Y) }
+func (a Vec) WithLen(m float64) Vec { return a.Norm().Scale(m) }
+
+// Norm возвращает единичный вектор того же направления; нулевой вектор остается нулевым
+func (a Vec) Norm() Vec {
+	m := a.Len()
+	if m == 0 {
+		return Vec{}
+	}
+	return a.Div(m)
+}
+
+// Clip укорачивает вектор до длины m, если он длиннее
+func (a Vec) Clip(m float64) Vec {
+	if l := a.Len(); l > m {
+		return a.Scale(m / l)
+	}
+	return a
+}
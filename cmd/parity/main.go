@@ -0,0 +1,57 @@
+// Команда parity сверяет Go-реализации алгоритмов с трассами и манифестами прогонов страниц,
+// записанными скриптами aco/testdata/record.mjs и sds/testdata/record.mjs:
+//
+//	go run ./cmd/parity aco/testdata/*.json sds/testdata/manifests/*.json
+//
+// Без аргументов проверяются все трассы и манифесты из каталогов testdata. То же
+// сверяет go test: см. TestParity и TestReplay в пакете manifest
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/RiddlerXenon/roi/manifest"
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Использование: parity [trace.json...]")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	paths := flag.Args()
+	if len(paths) == 0 {
+		for _, algorithm := range manifest.Algorithms() {
+			found, _ := filepath.Glob(filepath.Join(algorithm, "testdata", "*.json"))
+			paths = append(paths, found...)
+			found, _ = filepath.Glob(filepath.Join(algorithm, "testdata", "manifests", "*.json"))
+			paths = append(paths, found...)
+		}
+		slices.Sort(paths)
+		if len(paths) == 0 {
+			flag.Usage()
+			os.Exit(2)
+		}
+	}
+
+	failed := 0
+	for _, path := range paths {
+		report, err := manifest.Verify(path)
+		if err != nil {
+			log.Printf("%s: %v", path, err)
+			failed++
+			continue
+		}
+		fmt.Printf("%s: %s\n", path, report)
+	}
+
+	if failed > 0 {
+		log.Fatalf("Расхождения в %d из %d трасс", failed, len(paths))
+	}
+}
@@ -0,0 +1,122 @@
+// Команда replay повторяет прогон по манифесту, сверяет его с записанным итогом
+// и пишет трассу в JSON:
+//
+//	go run ./cmd/replay -o trace.json run.json
+//
+// С флагом -new команда записывает манифест прогона, выполненного в Go:
+//
+//	go run ./cmd/replay -new sds -params params.json -steps 500 -o run.json
+//
+// Прогон aco на графе из файла записывается с флагом -graph, и граф попадает в манифест:
+//
+//	go run ./cmd/replay -new aco -graph static/graphs/burma14.tsp -steps 100 -o run.json
+//
+// Манифесты aco и sds сохраняет и страница в браузере кнопкой «Сохранить прогон»
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/RiddlerXenon/roi/aco"
+	"github.com/RiddlerXenon/roi/manifest"
+)
+
+func main() {
+	output := flag.String("o", "", "файл трассы или нового манифеста; по умолчанию стандартный вывод")
+	every := flag.Int("every", 0, "интервал снимков в трассе sds и boids; 0 — только последний шаг")
+	algorithm := flag.String("new", "", "записать манифест нового прогона алгоритма: aco, boids или sds")
+	paramsPath := flag.String("params", "", "параметры нового прогона в JSON; недостающие берутся по умолчанию")
+	steps := flag.Int("steps", 0, "число шагов нового прогона")
+	graphPath := flag.String("graph", "", "граф aco из файла .tsp, .atsp, .gr или .csv для нового прогона")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Использование: replay [-every N] [-o trace.json] manifest.json")
+		fmt.Fprintln(os.Stderr, "               replay -new algorithm [-params params.json] [-graph file] -steps N [-o manifest.json]")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if *algorithm != "" {
+		if flag.NArg() != 0 {
+			flag.Usage()
+			os.Exit(2)
+		}
+		record(*algorithm, *paramsPath, *graphPath, *steps, *output)
+		return
+	}
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	m, err := manifest.Load(flag.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+	if m.Modified {
+		log.Printf("Параметры меняли посреди прогона: повтор с начала пройдет с последними значениями и может не совпасть с итогом")
+	}
+
+	trace, replayErr := m.Replay(*every)
+	if trace != nil {
+		if err := writeJSON(*output, trace); err != nil {
+			log.Fatalf("Ошибка записи трассы: %v", err)
+		}
+	}
+	if replayErr != nil {
+		log.Fatalf("%s: %v", flag.Arg(0), replayErr)
+	}
+	if m.Result != nil {
+		log.Printf("%s: %s, %d шагов, итог совпадает с записанным", flag.Arg(0), m.Algorithm, m.Steps)
+	}
+}
+
+// record выполняет прогон в Go и записывает его манифест
+func record(algorithm, paramsPath, graphPath string, steps int, output string) {
+	params := json.RawMessage("{}")
+	if paramsPath != "" {
+		data, err := os.ReadFile(paramsPath)
+		if err != nil {
+			log.Fatalf("Ошибка чтения параметров: %v", err)
+		}
+		params = data
+	}
+
+	var inst *aco.Instance
+	if graphPath != "" {
+		var err error
+		if inst, err = aco.LoadInstance(graphPath); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	m, err := manifest.NewOnInstance(algorithm, params, inst, steps)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if output == "" {
+		if err := writeJSON("", m); err != nil {
+			log.Fatalf("Ошибка записи манифеста: %v", err)
+		}
+		return
+	}
+	if err := m.Save(output); err != nil {
+		log.Fatalf("Ошибка записи манифеста: %v", err)
+	}
+}
+
+func writeJSON(path string, value any) error {
+	if path == "" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(value)
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
@@ -0,0 +1,78 @@
+// Команда server запускает HTTP API прогонов (пакет api) и раздает страницы репозитория:
+//
+//	go run ./cmd/server -addr :8080
+//	curl -d '{"params": {"m": 20}, "seeds": [1, 2, 3]}' localhost:8080/api/aco/run
+//	curl -d '{"graph": "burma14", "steps": 300}' localhost:8080/api/aco/run
+//
+// Страницы открываются по адресу /templates/aco.html, а /templates/boids.html?server
+// показывает стаю, которую шагает сервер. Графы для aco читаются из static/graphs.
+// Раздаются только каталоги static и templates; пути с сегментами, начинающимися
+// с точки (.., .git), отклоняются. Запускать команду нужно из корня репозитория
+// или указать его в -root
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/RiddlerXenon/roi/api"
+)
+
+func main() {
+	config := api.DefaultConfig()
+	addr := flag.String("addr", "localhost:8080", "адрес сервера")
+	root := flag.String("root", ".", "корень репозитория со static и templates")
+	flag.DurationVar(&config.Timeout, "timeout", config.Timeout, "предельное время одного запроса на прогон")
+	flag.IntVar(&config.MaxSteps, "max-steps", config.MaxSteps, "наибольшее число шагов прогона")
+	flag.IntVar(&config.MaxSeeds, "max-seeds", config.MaxSeeds, "наибольшее число зерен в запросе")
+	flag.IntVar(&config.MaxFrames, "max-frames", config.MaxFrames, "наибольшее число кадров траектории")
+	flag.IntVar(&config.MaxNodes, "max-nodes", config.MaxNodes, "наибольшее число вершин aco")
+	flag.IntVar(&config.MaxColony, "max-colony", config.MaxColony, "наибольшее число муравьев aco")
+	flag.IntVar(&config.MaxBoids, "max-boids", config.MaxBoids, "наибольшее число особей boids")
+	flag.IntVar(&config.MaxAgents, "max-agents", config.MaxAgents, "наибольшее число агентов sds")
+	flag.IntVar(&config.MaxSessions, "max-sessions", config.MaxSessions, "наибольшее число открытых сеансов потока")
+	flag.DurationVar(&config.SessionIdle, "session-idle", config.SessionIdle, "через сколько закрывается сеанс без зрителей и команд")
+	flag.Parse()
+
+	config.ParamsDir = filepath.Join(*root, config.ParamsDir)
+	config.GraphsDir = filepath.Join(*root, config.GraphsDir)
+	apiServer, err := api.New(config)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/api/", apiServer)
+	for _, dir := range []string{"static", "templates"} {
+		prefix := "/" + dir + "/"
+		mux.Handle(prefix, files(prefix, filepath.Join(*root, dir)))
+	}
+	mux.Handle("/{$}", http.RedirectHandler("/templates/index.html", http.StatusFound))
+
+	server := &http.Server{
+		Addr:              *addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	log.Printf("Сервер слушает %s", *addr)
+	log.Fatal(server.ListenAndServe())
+}
+
+// files раздает файлы каталога dir по адресам с префиксом prefix. Сегмент пути,
+// начинающийся с точки, дает 404: так не видны ни скрытые файлы, ни выход из каталога
+func files(prefix, dir string) http.Handler {
+	fileServer := http.StripPrefix(prefix, http.FileServer(http.Dir(dir)))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, segment := range strings.Split(r.URL.Path, "/") {
+			if strings.HasPrefix(segment, ".") {
+				http.NotFound(w, r)
+				return
+			}
+		}
+		fileServer.ServeHTTP(w, r)
+	})
+}
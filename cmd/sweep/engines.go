@@ -0,0 +1,201 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/RiddlerXenon/roi/aco"
+	"github.com/RiddlerXenon/roi/boids"
+	"github.com/RiddlerXenon/roi/sds"
+)
+
+// engine — алгоритм, доступный для перебора параметров
+type engine struct {
+	// defaults возвращает указатель на параметры страницы по умолчанию
+	defaults func() any
+
+	// metrics перечисляет показатели, которые возвращает run, в порядке вывода
+	metrics []string
+
+	// aliases и metricAliases сопоставляют другим написаниям имена полей JSON и показателей
+	aliases       map[string]string
+	metricAliases map[string]string
+
+	// run выполняет прогон; steps = 0 означает число шагов по умолчанию для алгоритма.
+	// graph — граф из файла, только для aco
+	run func(params any, graph *aco.Instance, steps int) (map[string]float64, error)
+}
+
+var engines = map[string]engine{
+	aco.Algorithm: {
+		defaults: func() any { p := aco.DefaultParams(); return &p },
+		metrics:  []string{"bestLength", "currentBestLength", "bestIteration", "gapPercent"},
+		aliases:  aco.ParamAliases,
+		run:      runACO,
+	},
+	boids.Algorithm: {
+		defaults:      func() any { p := boids.DefaultParams(); return &p },
+		metrics:       []string{"polarization", "milling", "meanSpeed"},
+		metricAliases: map[string]string{"polarisation": "polarization"},
+		run:           runBoids,
+	},
+	sds.Algorithm: {
+		defaults: func() any { p := sds.DefaultParams(); return &p },
+		metrics:  []string{"winnersFrac", "bestF", "meanF"},
+		run:      runSDS,
+	},
+}
+
+// runACO выполняет итерации муравьиного алгоритма; steps заменяет maxIterations.
+// bestIteration — итерация, на которой найден лучший путь, или 0, если путь не найден;
+// gapPercent — отставание тура от известного оптимума, NaN вне режима tour или без оптимума
+func runACO(params any, graph *aco.Instance, steps int) (map[string]float64, error) {
+	p := *params.(*aco.Params)
+	if steps > 0 {
+		p.MaxIterations = steps
+	}
+	var c *aco.Colony
+	var err error
+	if graph != nil {
+		c, err = aco.NewOnInstance(p, graph)
+	} else {
+		c, err = aco.New(p)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	bestIteration := 0
+	_, best := c.Best()
+	for c.Step() {
+		if _, length := c.Best(); length < best {
+			best, bestIteration = length, c.Iteration()
+		}
+	}
+	_, current := c.CurrentBest()
+	gap, ok := c.Gap()
+	if !ok {
+		gap = math.NaN()
+	}
+	return map[string]float64{
+		"bestLength":        best,
+		"currentBestLength": current,
+		"bestIteration":     float64(bestIteration),
+		"gapPercent":        gap,
+	}, nil
+}
+
+// runBoids возвращает параметры порядка стаи после steps шагов
+func runBoids(params any, _ *aco.Instance, steps int) (map[string]float64, error) {
+	if steps <= 0 {
+		return nil, fmt.Errorf("для boids нужно задать steps")
+	}
+	f, err := boids.New(*params.(*boids.Params))
+	if err != nil {
+		return nil, err
+	}
+	f.Run(steps)
+	order := f.Order()
+	return map[string]float64{
+		"polarization": order.Polarization,
+		"milling":      order.Milling,
+		"meanSpeed":    order.MeanSpeed,
+	}, nil
+}
+
+// runSDS возвращает показатели последнего из steps шагов поиска
+func runSDS(params any, _ *aco.Instance, steps int) (map[string]float64, error) {
+	if steps <= 0 {
+		return nil, fmt.Errorf("для sds нужно задать steps")
+	}
+	s, err := sds.New(*params.(*sds.Params))
+	if err != nil {
+		return nil, err
+	}
+	s.Run(steps)
+	metrics := s.Metrics()
+	return map[string]float64{
+		"winnersFrac": metrics.WinnersFrac,
+		"bestF":       metrics.BestF,
+		"meanF":       metrics.MeanF,
+	}, nil
+}
+
+// setParam записывает значение в поле структуры params с тегом JSON name.
+// Дробные числа в целочисленных полях округляются: так случайная выборка
+// из диапазона подходит и для nodeCount или kTopo
+func setParam(params any, name string, value any) error {
+	v := reflect.ValueOf(params).Elem()
+	field, ok := paramField(v, name)
+	if !ok {
+		return fmt.Errorf("неизвестный параметр %q, доступны: %s", name, strings.Join(paramNames(v.Type()), ", "))
+	}
+
+	switch field.Kind() {
+	case reflect.Float64:
+		number, ok := value.(float64)
+		if !ok {
+			return fmt.Errorf("параметр %s: ожидается число, получено %v", name, value)
+		}
+		field.SetFloat(number)
+	case reflect.Int:
+		number, ok := value.(float64)
+		if !ok {
+			return fmt.Errorf("параметр %s: ожидается число, получено %v", name, value)
+		}
+		field.SetInt(int64(math.Round(number)))
+	case reflect.Uint32, reflect.Uint64:
+		number, ok := value.(float64)
+		if !ok || number < 0 || math.Round(number) >= math.Ldexp(1, field.Type().Bits()) {
+			return fmt.Errorf("параметр %s: ожидается неотрицательное целое, получено %v", name, value)
+		}
+		field.SetUint(uint64(math.Round(number)))
+	case reflect.Bool:
+		flag, ok := value.(bool)
+		if !ok {
+			return fmt.Errorf("параметр %s: ожидается true или false, получено %v", name, value)
+		}
+		field.SetBool(flag)
+	case reflect.String:
+		text, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("параметр %s: ожидается строка, получено %v", name, value)
+		}
+		field.SetString(text)
+	default:
+		return fmt.Errorf("параметр %s не поддерживает перебор", name)
+	}
+	return nil
+}
+
+func paramField(v reflect.Value, name string) (reflect.Value, bool) {
+	for i := 0; i < v.NumField(); i++ {
+		if jsonName(v.Type().Field(i)) == name {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// paramNames возвращает отсортированные имена параметров структуры
+func paramNames(t reflect.Type) []string {
+	names := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		if name := jsonName(t.Field(i)); name != "" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "-" {
+		return ""
+	}
+	return name
+}
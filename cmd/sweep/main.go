@@ -0,0 +1,319 @@
+// Команда sweep перебирает параметры алгоритмов aco, boids и sds без браузера и пишет
+// итоговые показатели каждого прогона в CSV или JSON Lines, по строке на прогон:
+//
+//	go run ./cmd/sweep -o sds.csv sweep.yaml
+//
+// Спецификация перебора пишется в YAML или JSON:
+//
+//	algorithm: sds
+//	mode: grid          # или random: samples случайных точек с зерном sampleSeed
+//	steps: 500
+//	seeds: {from: 1, count: 10}
+//	metric: [winnersFrac, bestF]
+//	base:
+//	  func: rastrigin
+//	params:
+//	  sigma: {min: 0.01, max: 0.5, steps: 8, log: true}
+//	  restartProb: {values: [0.1, 0.5, 0.9]}
+//
+// Параметры называются как поля JSON или как ключи описаний static/latex/params: для aco
+// m, T, e и w, как и в API. Показатель стаи polarization можно писать и как polarisation.
+//
+// Для aco ключ graph: burma14.tsp запускает прогоны на графе из файла вместо случайного;
+// число вершин и тип графа тогда берутся из файла. С base: {objective: tour} муравьи
+// строят замкнутый тур, а показатель gapPercent сравнивает его с оптимумом TSPLIB.
+//
+// Каждая точка сетки запускается с каждым зерном из seeds; прогоны распределяются
+// по -j процессам, а строки результата идут в порядке номеров прогонов, так что
+// вывод не зависит от числа процессов
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/RiddlerXenon/roi/aco"
+)
+
+// job — один прогон: точка перебора и зерно
+type job struct {
+	run    int
+	values []any
+	seed   any
+}
+
+// result — итог прогона вместе с полными параметрами для повторения
+type result struct {
+	job
+	metrics map[string]float64
+	steps   int
+	elapsed time.Duration
+	params  json.RawMessage
+	err     error
+}
+
+func main() {
+	output := flag.String("o", "", "файл результатов; по умолчанию стандартный вывод")
+	format := flag.String("format", "", "формат результатов: csv или jsonl; по умолчанию по расширению -o, иначе csv")
+	workers := flag.Int("j", runtime.NumCPU(), "число одновременных прогонов")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Использование: sweep [-o results.csv] [-format csv|jsonl] [-j N] spec.yaml")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *workers < 1 {
+		log.Fatalf("-j = %d: нужен хотя бы один процесс", *workers)
+	}
+
+	spec, err := loadSpec(flag.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *format == "" {
+		*format = "csv"
+		if ext := strings.ToLower(filepath.Ext(*output)); ext == ".jsonl" || ext == ".ndjson" {
+			*format = "jsonl"
+		}
+	}
+	var write func(io.Writer, Spec, []result) error
+	switch *format {
+	case "csv":
+		write = writeCSV
+	case "jsonl":
+		write = writeJSONL
+	default:
+		log.Fatalf("-format = %q: ожидается csv или jsonl", *format)
+	}
+
+	jobs := spec.jobs()
+	log.Printf("%s: %d прогонов в %d процессах", spec.Algorithm, len(jobs), min(*workers, len(jobs)))
+	started := time.Now()
+	results := runAll(spec, jobs, *workers)
+
+	out := io.Writer(os.Stdout)
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			log.Fatalf("Ошибка создания файла результатов: %v", err)
+		}
+		defer file.Close()
+		out = file
+	}
+	if err := write(out, spec, results); err != nil {
+		log.Fatalf("Ошибка записи результатов: %v", err)
+	}
+
+	failed := 0
+	for _, r := range results {
+		if r.err != nil {
+			failed++
+		}
+	}
+	if failed > 0 {
+		log.Printf("Ошибки в %d из %d прогонов, подробности в столбце error", failed, len(results))
+	}
+	log.Printf("Готово за %v", time.Since(started).Round(time.Millisecond))
+}
+
+// jobs раскладывает точки перебора по зернам; без seeds каждая точка
+// запускается один раз с зерном из base или параметров по умолчанию
+func (s *Spec) jobs() []job {
+	var jobs []job
+	for _, values := range s.points() {
+		if len(s.Seeds) == 0 {
+			jobs = append(jobs, job{run: len(jobs) + 1, values: values})
+			continue
+		}
+		for _, seed := range s.Seeds {
+			jobs = append(jobs, job{run: len(jobs) + 1, values: values, seed: seed})
+		}
+	}
+	return jobs
+}
+
+// runAll выполняет прогоны в workers горутинах и возвращает итоги в порядке прогонов
+func runAll(spec Spec, jobs []job, workers int) []result {
+	results := make([]result, len(jobs))
+	queue := make(chan int)
+	var wg sync.WaitGroup
+	for range min(workers, len(jobs)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range queue {
+				results[i] = spec.run(jobs[i])
+			}
+		}()
+	}
+	for i := range jobs {
+		queue <- i
+	}
+	close(queue)
+	wg.Wait()
+	return results
+}
+
+// run собирает параметры прогона поверх параметров по умолчанию: base, затем точка
+// перебора, затем зерно, и выполняет прогон
+func (s *Spec) run(j job) result {
+	eng := engines[s.Algorithm]
+	r := result{job: j, steps: s.Steps}
+
+	params := eng.defaults()
+	for name, value := range s.Base {
+		if r.err = setParam(params, name, value); r.err != nil {
+			return r
+		}
+	}
+	// В результат попадают значения после записи в параметры, например округленные до целого
+	r.values = make([]any, len(j.values))
+	for i, name := range s.paramNames() {
+		if r.err = setParam(params, name, j.values[i]); r.err != nil {
+			return r
+		}
+		r.values[i] = paramValue(params, name)
+	}
+	if j.seed != nil {
+		if r.err = setParam(params, "seed", j.seed); r.err != nil {
+			return r
+		}
+	}
+	r.seed = paramValue(params, "seed")
+	if s.graph != nil {
+		p := s.graph.Apply(*params.(*aco.Params))
+		params = &p
+	}
+	if r.steps == 0 {
+		// aco без steps проходит maxIterations итераций
+		if iterations, ok := paramValue(params, "maxIterations").(int); ok {
+			r.steps = iterations
+		}
+	}
+	if r.params, r.err = json.Marshal(params); r.err != nil {
+		return r
+	}
+
+	started := time.Now()
+	r.metrics, r.err = eng.run(params, s.graph, s.Steps)
+	r.elapsed = time.Since(started)
+	return r
+}
+
+// paramValue возвращает значение поля параметров с тегом JSON name или nil
+func paramValue(params any, name string) any {
+	field, ok := paramField(reflect.ValueOf(params).Elem(), name)
+	if !ok {
+		return nil
+	}
+	return field.Interface()
+}
+
+// writeCSV пишет таблицу по строке на прогон: номер, алгоритм, зерно, перебираемые
+// параметры, показатели, затем шаги, время, полные параметры в JSON и ошибку
+func writeCSV(w io.Writer, spec Spec, results []result) error {
+	out := csv.NewWriter(w)
+	header := []string{"run", "algorithm", "seed"}
+	header = append(header, spec.paramNames()...)
+	header = append(header, spec.Metric...)
+	header = append(header, "steps", "elapsed_ms", "params", "error")
+	if err := out.Write(header); err != nil {
+		return err
+	}
+
+	for _, r := range results {
+		row := []string{strconv.Itoa(r.run), spec.Algorithm, csvValue(r.seed)}
+		for _, value := range r.values {
+			row = append(row, csvValue(value))
+		}
+		for _, metric := range spec.Metric {
+			if value, ok := r.metrics[metric]; ok {
+				row = append(row, csvValue(value))
+			} else {
+				row = append(row, "")
+			}
+		}
+		row = append(row, strconv.Itoa(r.steps), csvValue(float64(r.elapsed.Microseconds())/1000), string(r.params), errorText(r.err))
+		if err := out.Write(row); err != nil {
+			return err
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
+
+// writeJSONL пишет по объекту JSON на прогон; бесконечные и неопределенные
+// значения показателей записываются как null
+func writeJSONL(w io.Writer, spec Spec, results []result) error {
+	encoder := json.NewEncoder(w)
+	names := spec.paramNames()
+	for _, r := range results {
+		swept := make(map[string]any, len(names))
+		for i, name := range names {
+			swept[name] = r.values[i]
+		}
+		var metrics map[string]any
+		if r.metrics != nil {
+			metrics = make(map[string]any, len(spec.Metric))
+			for _, metric := range spec.Metric {
+				if value := r.metrics[metric]; !math.IsInf(value, 0) && !math.IsNaN(value) {
+					metrics[metric] = value
+				} else {
+					metrics[metric] = nil
+				}
+			}
+		}
+		record := struct {
+			Run       int             `json:"run"`
+			Algorithm string          `json:"algorithm"`
+			Seed      any             `json:"seed"`
+			Swept     map[string]any  `json:"swept"`
+			Metrics   map[string]any  `json:"metrics"`
+			Steps     int             `json:"steps"`
+			ElapsedMS float64         `json:"elapsed_ms"`
+			Params    json.RawMessage `json:"params,omitempty"`
+			Error     string          `json:"error,omitempty"`
+		}{r.run, spec.Algorithm, r.seed, swept, metrics, r.steps, float64(r.elapsed.Microseconds()) / 1000, r.params, errorText(r.err)}
+		if err := encoder.Encode(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func csvValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case string:
+		return v
+	}
+	return fmt.Sprint(value)
+}
+
+func errorText(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
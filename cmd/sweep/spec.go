@@ -0,0 +1,323 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/RiddlerXenon/roi/aco"
+)
+
+// Spec — описание перебора параметров
+type Spec struct {
+	Algorithm string `json:"algorithm"`
+
+	// Mode — grid перебирает все сочетания значений, random берет Samples случайных точек
+	Mode       string `json:"mode"`
+	Samples    int    `json:"samples"`
+	SampleSeed uint64 `json:"sampleSeed"`
+
+	// Steps — число шагов каждого прогона; для aco по умолчанию maxIterations
+	Steps int `json:"steps"`
+
+	// Graph — файл графа для aco (TSPLIB, DIMACS или CSV) относительно файла спецификации;
+	// без него каждый прогон строит случайный граф из nodeCount и seed
+	Graph string `json:"graph"`
+	graph *aco.Instance
+
+	Seeds  Seeds            `json:"seeds"`
+	Metric stringList       `json:"metric"`
+	Base   map[string]any   `json:"base"`
+	Params map[string]Range `json:"params"`
+}
+
+// Range — значения одного параметра: перечень values или отрезок [min, max].
+// В режиме grid отрезок делится на steps точек, в режиме random из него берется
+// равномерная выборка, при log — равномерная по логарифму
+type Range struct {
+	Values []any    `json:"values"`
+	Min    *float64 `json:"min"`
+	Max    *float64 `json:"max"`
+	Steps  int      `json:"steps"`
+	Log    bool     `json:"log"`
+}
+
+// Seeds — зерна генератора: список [1, 2, 3] или {from: 1, count: 10}
+type Seeds []float64
+
+func (s *Seeds) UnmarshalJSON(data []byte) error {
+	var list []float64
+	if err := json.Unmarshal(data, &list); err == nil {
+		*s = list
+		return nil
+	}
+
+	var span struct {
+		From  float64 `json:"from"`
+		Count int     `json:"count"`
+	}
+	if err := json.Unmarshal(data, &span); err != nil || span.Count < 1 {
+		return errors.New("seeds: ожидается список чисел или {from, count} с count > 0")
+	}
+	*s = make(Seeds, span.Count)
+	for i := range *s {
+		(*s)[i] = span.From + float64(i)
+	}
+	return nil
+}
+
+// stringList принимает одну строку или список строк
+type stringList []string
+
+func (l *stringList) UnmarshalJSON(data []byte) error {
+	var one string
+	if err := json.Unmarshal(data, &one); err == nil {
+		*l = stringList{one}
+		return nil
+	}
+	return json.Unmarshal(data, (*[]string)(l))
+}
+
+// loadSpec читает спецификацию в JSON или YAML; формат определяется по расширению
+// .json или по первой фигурной скобке
+func loadSpec(path string) (Spec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Spec{}, fmt.Errorf("чтение спецификации: %w", err)
+	}
+
+	text := strings.TrimSpace(string(data))
+	if strings.ToLower(filepath.Ext(path)) != ".json" && !strings.HasPrefix(text, "{") {
+		document, err := parseYAML(string(data))
+		if err != nil {
+			return Spec{}, fmt.Errorf("разбор спецификации %s: %w", path, err)
+		}
+		if data, err = json.Marshal(document); err != nil {
+			return Spec{}, fmt.Errorf("разбор спецификации %s: %w", path, err)
+		}
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(string(data)))
+	decoder.DisallowUnknownFields()
+	var spec Spec
+	if err := decoder.Decode(&spec); err != nil {
+		return Spec{}, fmt.Errorf("разбор спецификации %s: %w", path, err)
+	}
+	if spec.Graph != "" {
+		if spec.Algorithm != aco.Algorithm {
+			return Spec{}, fmt.Errorf("graph задается только для %s", aco.Algorithm)
+		}
+		graphPath := spec.Graph
+		if !filepath.IsAbs(graphPath) {
+			graphPath = filepath.Join(filepath.Dir(path), graphPath)
+		}
+		if spec.graph, err = aco.LoadInstance(graphPath); err != nil {
+			return Spec{}, err
+		}
+	}
+	return spec, spec.validate()
+}
+
+func (s *Spec) validate() error {
+	eng, ok := engines[s.Algorithm]
+	if !ok {
+		return fmt.Errorf("algorithm = %q: ожидается одно из %s", s.Algorithm, strings.Join(algorithmNames(), ", "))
+	}
+	if s.Mode == "" {
+		s.Mode = "grid"
+	}
+	switch {
+	case s.Mode != "grid" && s.Mode != "random":
+		return fmt.Errorf("mode = %q: ожидается grid или random", s.Mode)
+	case s.Mode == "random" && s.Samples < 1:
+		return errors.New("в режиме random нужно задать samples > 0")
+	case s.Steps < 0:
+		return fmt.Errorf("steps = %d: число шагов не может быть отрицательным", s.Steps)
+	}
+
+	if err := s.resolveAliases(eng); err != nil {
+		return err
+	}
+	if len(s.Metric) == 0 {
+		s.Metric = eng.metrics
+	}
+	for _, metric := range s.Metric {
+		if !slices.Contains(eng.metrics, metric) {
+			return fmt.Errorf("metric = %q: для %s доступны %s", metric, s.Algorithm, strings.Join(eng.metrics, ", "))
+		}
+	}
+
+	// Имена параметров проверяются на параметрах по умолчанию, чтобы ошибка в имени
+	// обнаружилась до запуска прогонов
+	for name, value := range s.Base {
+		if err := setParam(eng.defaults(), name, value); err != nil {
+			return fmt.Errorf("base: %w", err)
+		}
+	}
+	for _, seed := range s.Seeds {
+		if err := setParam(eng.defaults(), "seed", seed); err != nil {
+			return fmt.Errorf("seeds: %w", err)
+		}
+	}
+	for _, name := range s.paramNames() {
+		r := s.Params[name]
+		if err := r.validate(s.Mode); err != nil {
+			return fmt.Errorf("params.%s: %w", name, err)
+		}
+		sample := r.Values
+		if sample == nil {
+			sample = []any{*r.Min}
+		}
+		for _, value := range sample {
+			if err := setParam(eng.defaults(), name, value); err != nil {
+				return fmt.Errorf("params: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// resolveAliases заменяет ключи описаний (m, T для aco) и другие написания показателей
+// именами полей JSON и показателей, под которыми они идут в столбцах результата
+func (s *Spec) resolveAliases(eng engine) error {
+	var err error
+	if s.Base, err = renameKeys(s.Base, eng.aliases); err != nil {
+		return fmt.Errorf("base: %w", err)
+	}
+	if s.Params, err = renameKeys(s.Params, eng.aliases); err != nil {
+		return fmt.Errorf("params: %w", err)
+	}
+	for i, metric := range s.Metric {
+		if name, ok := eng.metricAliases[metric]; ok {
+			s.Metric[i] = name
+		}
+	}
+	return nil
+}
+
+func renameKeys[V any](m map[string]V, aliases map[string]string) (map[string]V, error) {
+	if m == nil {
+		return nil, nil
+	}
+	renamed := make(map[string]V, len(m))
+	for key, value := range m {
+		if field, ok := aliases[key]; ok {
+			key = field
+		}
+		if _, dup := renamed[key]; dup {
+			return nil, fmt.Errorf("параметр %s задан дважды", key)
+		}
+		renamed[key] = value
+	}
+	return renamed, nil
+}
+
+func (r Range) validate(mode string) error {
+	switch {
+	case r.Values != nil && (r.Min != nil || r.Max != nil):
+		return errors.New("нужно задать либо values, либо min и max")
+	case r.Values != nil && len(r.Values) == 0:
+		return errors.New("пустой список values")
+	case r.Values != nil:
+		return nil
+	case r.Min == nil || r.Max == nil:
+		return errors.New("нужно задать values или min и max")
+	case *r.Min > *r.Max:
+		return fmt.Errorf("min = %v больше max = %v", *r.Min, *r.Max)
+	case r.Log && !(*r.Min > 0):
+		return errors.New("при log отрезок должен быть положительным")
+	case mode == "grid" && r.Steps < 1:
+		return errors.New("в режиме grid для отрезка нужно задать steps > 0")
+	}
+	return nil
+}
+
+// paramNames возвращает перебираемые параметры по алфавиту: в этом порядке
+// они идут в столбцах результата и во вложенных циклах сетки
+func (s *Spec) paramNames() []string {
+	names := make([]string, 0, len(s.Params))
+	for name := range s.Params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// points возвращает точки перебора: значения параметров в порядке paramNames
+func (s *Spec) points() [][]any {
+	names := s.paramNames()
+	if s.Mode == "random" {
+		rng := rand.New(rand.NewPCG(s.SampleSeed, 0))
+		points := make([][]any, s.Samples)
+		for i := range points {
+			points[i] = make([]any, len(names))
+			for j, name := range names {
+				points[i][j] = s.Params[name].sample(rng)
+			}
+		}
+		return points
+	}
+
+	points := [][]any{{}}
+	for _, name := range names {
+		values := s.Params[name].grid()
+		next := make([][]any, 0, len(points)*len(values))
+		for _, point := range points {
+			for _, value := range values {
+				next = append(next, append(slices.Clip(point), value))
+			}
+		}
+		points = next
+	}
+	return points
+}
+
+// grid возвращает значения для сетки: values или steps равноотстоящих точек отрезка
+func (r Range) grid() []any {
+	if r.Values != nil {
+		return r.Values
+	}
+	if r.Steps == 1 {
+		return []any{*r.Min}
+	}
+	values := make([]any, r.Steps)
+	for i := range values {
+		t := float64(i) / float64(r.Steps-1)
+		if r.Log {
+			values[i] = math.Exp(math.Log(*r.Min) + t*(math.Log(*r.Max)-math.Log(*r.Min)))
+		} else {
+			values[i] = *r.Min + t*(*r.Max-*r.Min)
+		}
+	}
+	// Концы отрезка без ошибок округления
+	values[0], values[len(values)-1] = *r.Min, *r.Max
+	return values
+}
+
+// sample возвращает случайное значение из values или отрезка
+func (r Range) sample(rng *rand.Rand) any {
+	if r.Values != nil {
+		return r.Values[rng.IntN(len(r.Values))]
+	}
+	t := rng.Float64()
+	if r.Log {
+		return math.Exp(math.Log(*r.Min) + t*(math.Log(*r.Max)-math.Log(*r.Min)))
+	}
+	return *r.Min + t*(*r.Max-*r.Min)
+}
+
+func algorithmNames() []string {
+	names := make([]string, 0, len(engines))
+	for name := range engines {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
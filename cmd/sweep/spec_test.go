@@ -0,0 +1,38 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSpecAliases(t *testing.T) {
+	low, high := 5.0, 20.0
+	spec := Spec{
+		Algorithm: "aco",
+		Base:      map[string]any{"T": 30.0, "e": 4.0, "variant": "eas"},
+		Params:    map[string]Range{"m": {Min: &low, Max: &high, Steps: 2}, "w": {Values: []any{3.0}}},
+	}
+	if err := spec.validate(); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"colonySize", "ranks"}; !reflect.DeepEqual(spec.paramNames(), want) {
+		t.Errorf("params: %v, ожидалось %v", spec.paramNames(), want)
+	}
+	if want := map[string]any{"maxIterations": 30.0, "elitistWeight": 4.0, "variant": "eas"}; !reflect.DeepEqual(spec.Base, want) {
+		t.Errorf("base: %v, ожидалось %v", spec.Base, want)
+	}
+
+	spec = Spec{Algorithm: "boids", Metric: stringList{"polarisation", "milling"}}
+	if err := spec.validate(); err != nil {
+		t.Fatal(err)
+	}
+	if want := (stringList{"polarization", "milling"}); !reflect.DeepEqual(spec.Metric, want) {
+		t.Errorf("metric: %v, ожидалось %v", spec.Metric, want)
+	}
+
+	spec = Spec{Algorithm: "aco", Base: map[string]any{"m": 5.0, "colonySize": 6.0}}
+	if err := spec.validate(); err == nil || !strings.Contains(err.Error(), "colonySize задан дважды") {
+		t.Errorf("ошибка %v, ожидался повтор colonySize", err)
+	}
+}
@@ -0,0 +1,334 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Спецификации прогонов пишутся вручную, поэтому здесь разбирается подмножество YAML,
+// которого им хватает: блочные словари и списки с отступами пробелами, однострочные
+// [a, b] и {k: v}, строки в кавычках, числа, true, false, null и комментарии #.
+// Якоря, многострочные строки и несколько документов в одном файле не поддерживаются
+
+// yamlLine — значимая строка файла без комментария
+type yamlLine struct {
+	number int
+	indent int
+	text   string
+}
+
+// parseYAML разбирает документ в значения того же вида, что дает encoding/json:
+// map[string]any, []any, float64, bool, string и nil
+func parseYAML(data string) (any, error) {
+	var lines []yamlLine
+	for i, text := range strings.Split(data, "\n") {
+		text = strings.TrimRight(stripYAMLComment(text), " \t\r")
+		trimmed := strings.TrimLeft(text, " ")
+		if trimmed == "" || trimmed == "---" {
+			continue
+		}
+		if strings.HasPrefix(trimmed, "\t") {
+			return nil, fmt.Errorf("строка %d: отступ табуляцией", i+1)
+		}
+		lines = append(lines, yamlLine{number: i + 1, indent: len(text) - len(trimmed), text: trimmed})
+	}
+	if len(lines) == 0 {
+		return nil, nil
+	}
+
+	value, next, err := parseYAMLBlock(lines, 0, lines[0].indent)
+	if err != nil {
+		return nil, err
+	}
+	if next < len(lines) {
+		return nil, fmt.Errorf("строка %d: неожиданный отступ", lines[next].number)
+	}
+	return value, nil
+}
+
+// stripYAMLComment удаляет комментарий: # в начале строки или после пробела вне кавычек
+func stripYAMLComment(text string) string {
+	quote := byte(0)
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			} else if c == '\\' && quote == '"' {
+				i++
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#' && (i == 0 || text[i-1] == ' ' || text[i-1] == '\t'):
+			return text[:i]
+		}
+	}
+	return text
+}
+
+// parseYAMLBlock разбирает словарь или список, строки которого начинаются с отступом indent
+func parseYAMLBlock(lines []yamlLine, i, indent int) (any, int, error) {
+	if isYAMLSequenceItem(lines[i].text) {
+		return parseYAMLSequence(lines, i, indent)
+	}
+	return parseYAMLMapping(lines, i, indent)
+}
+
+func isYAMLSequenceItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+func parseYAMLSequence(lines []yamlLine, i, indent int) (any, int, error) {
+	items := []any{}
+	for i < len(lines) && lines[i].indent == indent && isYAMLSequenceItem(lines[i].text) {
+		content := strings.TrimLeft(strings.TrimPrefix(lines[i].text, "-"), " ")
+		switch {
+		case content == "":
+			if i+1 < len(lines) && lines[i+1].indent > indent {
+				value, next, err := parseYAMLBlock(lines, i+1, lines[i+1].indent)
+				if err != nil {
+					return nil, 0, err
+				}
+				items = append(items, value)
+				i = next
+			} else {
+				items = append(items, nil)
+				i++
+			}
+		case yamlKeyEnd(content) >= 0:
+			// Словарь, начатый в строке элемента: его ключи выровнены по первому ключу
+			column := indent + len(lines[i].text) - len(content)
+			shifted := append([]yamlLine(nil), lines...)
+			shifted[i] = yamlLine{number: lines[i].number, indent: column, text: content}
+			value, next, err := parseYAMLMapping(shifted, i, column)
+			if err != nil {
+				return nil, 0, err
+			}
+			items = append(items, value)
+			i = next
+		default:
+			value, err := parseYAMLFlow(content, lines[i].number)
+			if err != nil {
+				return nil, 0, err
+			}
+			items = append(items, value)
+			i++
+		}
+	}
+	return items, i, nil
+}
+
+func parseYAMLMapping(lines []yamlLine, i, indent int) (any, int, error) {
+	mapping := map[string]any{}
+	for i < len(lines) && lines[i].indent == indent {
+		line := lines[i]
+		end := yamlKeyEnd(line.text)
+		if end < 0 {
+			return nil, 0, fmt.Errorf("строка %d: ожидается «ключ: значение»", line.number)
+		}
+		key, err := yamlKey(line.text[:end], line.number)
+		if err != nil {
+			return nil, 0, err
+		}
+		if _, ok := mapping[key]; ok {
+			return nil, 0, fmt.Errorf("строка %d: ключ %q повторяется", line.number, key)
+		}
+
+		rest := strings.TrimSpace(line.text[end+1:])
+		i++
+		switch {
+		case rest != "":
+			mapping[key], err = parseYAMLFlow(rest, line.number)
+			if err != nil {
+				return nil, 0, err
+			}
+		case i < len(lines) && lines[i].indent > indent,
+			// Список может стоять на том же отступе, что и его ключ
+			i < len(lines) && lines[i].indent == indent && isYAMLSequenceItem(lines[i].text):
+			mapping[key], i, err = parseYAMLBlock(lines, i, lines[i].indent)
+			if err != nil {
+				return nil, 0, err
+			}
+		default:
+			mapping[key] = nil
+		}
+	}
+	if i < len(lines) && lines[i].indent > indent {
+		return nil, 0, fmt.Errorf("строка %d: неожиданный отступ", lines[i].number)
+	}
+	return mapping, i, nil
+}
+
+// yamlKeyEnd возвращает позицию двоеточия после ключа или -1, если строка не начинается с ключа
+func yamlKeyEnd(text string) int {
+	if text == "" || strings.ContainsRune("[{", rune(text[0])) {
+		return -1
+	}
+	quote := byte(0)
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case i == 0 && (c == '"' || c == '\''):
+			quote = c
+		case c == ':' && (i+1 == len(text) || text[i+1] == ' '):
+			return i
+		}
+	}
+	return -1
+}
+
+func yamlKey(text string, line int) (string, error) {
+	text = strings.TrimSpace(text)
+	if text != "" && (text[0] == '"' || text[0] == '\'') {
+		value, rest, err := yamlQuoted(text, line)
+		if err != nil {
+			return "", err
+		}
+		if strings.TrimSpace(rest) != "" {
+			return "", fmt.Errorf("строка %d: текст после ключа в кавычках", line)
+		}
+		return value, nil
+	}
+	return text, nil
+}
+
+// parseYAMLFlow разбирает значение в одной строке: скаляр, [..] или {..}
+func parseYAMLFlow(text string, line int) (any, error) {
+	value, rest, err := yamlFlowValue(text, line, false)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(rest) != "" {
+		return nil, fmt.Errorf("строка %d: лишний текст %q", line, rest)
+	}
+	return value, nil
+}
+
+// yamlFlowValue читает одно значение с начала text; nested — значение внутри [..] или {..},
+// где запятая и закрывающая скобка завершают скаляр
+func yamlFlowValue(text string, line int, nested bool) (any, string, error) {
+	text = strings.TrimLeft(text, " ")
+	if text == "" {
+		return nil, "", nil
+	}
+
+	switch text[0] {
+	case '"', '\'':
+		return yamlQuoted(text, line)
+	case '[':
+		items := []any{}
+		text = strings.TrimLeft(text[1:], " ")
+		for !strings.HasPrefix(text, "]") {
+			value, rest, err := yamlFlowValue(text, line, true)
+			if err != nil {
+				return nil, "", err
+			}
+			items = append(items, value)
+			if text, err = yamlFlowSeparator(rest, ']', line); err != nil {
+				return nil, "", err
+			}
+		}
+		return items, text[1:], nil
+	case '{':
+		mapping := map[string]any{}
+		text = strings.TrimLeft(text[1:], " ")
+		for !strings.HasPrefix(text, "}") {
+			colon := strings.Index(text, ":")
+			if colon < 0 {
+				return nil, "", fmt.Errorf("строка %d: ожидается «ключ: значение» в {..}", line)
+			}
+			key, err := yamlKey(text[:colon], line)
+			if err != nil {
+				return nil, "", err
+			}
+			value, rest, err := yamlFlowValue(text[colon+1:], line, true)
+			if err != nil {
+				return nil, "", err
+			}
+			mapping[key] = value
+			if text, err = yamlFlowSeparator(rest, '}', line); err != nil {
+				return nil, "", err
+			}
+		}
+		return mapping, text[1:], nil
+	}
+
+	end := len(text)
+	if nested {
+		end = strings.IndexAny(text, ",]}")
+		if end < 0 {
+			return nil, "", fmt.Errorf("строка %d: не закрыта скобка", line)
+		}
+	}
+	return yamlScalar(strings.TrimSpace(text[:end])), text[end:], nil
+}
+
+// yamlFlowSeparator пропускает запятую между элементами; возвращает текст,
+// начинающийся со следующего элемента или с закрывающей скобки
+func yamlFlowSeparator(text string, closing byte, line int) (string, error) {
+	text = strings.TrimLeft(text, " ")
+	switch {
+	case strings.HasPrefix(text, ","):
+		return strings.TrimLeft(text[1:], " "), nil
+	case text != "" && text[0] == closing:
+		return text, nil
+	}
+	return "", fmt.Errorf("строка %d: ожидается «,» или «%c»", line, closing)
+}
+
+// yamlQuoted читает строку в двойных кавычках с экранированием, как в JSON,
+// или в одинарных, где кавычка удваивается
+func yamlQuoted(text string, line int) (string, string, error) {
+	if text[0] == '\'' {
+		var value strings.Builder
+		for i := 1; i < len(text); i++ {
+			if text[i] != '\'' {
+				value.WriteByte(text[i])
+				continue
+			}
+			if i+1 < len(text) && text[i+1] == '\'' {
+				value.WriteByte('\'')
+				i++
+				continue
+			}
+			return value.String(), text[i+1:], nil
+		}
+		return "", "", fmt.Errorf("строка %d: не закрыта кавычка", line)
+	}
+
+	for i := 1; i < len(text); i++ {
+		switch text[i] {
+		case '\\':
+			i++
+		case '"':
+			value, err := strconv.Unquote(text[:i+1])
+			if err != nil {
+				return "", "", fmt.Errorf("строка %d: %w", line, err)
+			}
+			return value, text[i+1:], nil
+		}
+	}
+	return "", "", fmt.Errorf("строка %d: не закрыта кавычка", line)
+}
+
+// yamlScalar распознает null, логические значения и числа; остальное остается строкой
+func yamlScalar(text string) any {
+	switch text {
+	case "", "~", "null", "Null", "NULL":
+		return nil
+	case "true", "True", "TRUE":
+		return true
+	case "false", "False", "FALSE":
+		return false
+	}
+	// ParseFloat понимает и Inf, NaN, 0x1p3, которые в YAML остаются строками
+	if number, err := strconv.ParseFloat(text, 64); err == nil && strings.ContainsAny(text, "0123456789") &&
+		!strings.ContainsAny(text, "xXpPnN_") {
+		return number
+	}
+	return text
+}
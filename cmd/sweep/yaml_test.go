@@ -0,0 +1,82 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseYAML(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want any
+	}{
+		{"пустой документ", "# только комментарий\n---\n", nil},
+		{"скаляры", "a: 1\nb: -2.5e3\nc: true\nd: null\ne: ~\nf: text\ng: 0x10\nh: .inf", map[string]any{
+			"a": 1.0, "b": -2500.0, "c": true, "d": nil, "e": nil, "f": "text", "g": "0x10", "h": ".inf",
+		}},
+		{"кавычки", `a: "x # y"` + "\nb: 'it''s'\n\"c d\": \"\\u0041\\n\"", map[string]any{
+			"a": "x # y", "b": "it's", "c d": "A\n",
+		}},
+		{"комментарии", "a: 1 # число\nb: x#y\n# строка\nc: 2", map[string]any{"a": 1.0, "b": "x#y", "c": 2.0}},
+		{"вложенный словарь", "base:\n  func: rastrigin\n  deep:\n    x: 1\ny: 2", map[string]any{
+			"base": map[string]any{"func": "rastrigin", "deep": map[string]any{"x": 1.0}}, "y": 2.0,
+		}},
+		{"однострочные значения", "seeds: {from: 1, count: 10}\nmetric: [winnersFrac, bestF]\nempty: []\nnone: {}",
+			map[string]any{
+				"seeds":  map[string]any{"from": 1.0, "count": 10.0},
+				"metric": []any{"winnersFrac", "bestF"},
+				"empty":  []any{},
+				"none":   map[string]any{},
+			}},
+		{"вложенные скобки", "a: [[1, 2], {b: [x, 'y, z']}]", map[string]any{
+			"a": []any{[]any{1.0, 2.0}, map[string]any{"b": []any{"x", "y, z"}}},
+		}},
+		{"список с отступом", "values:\n  - 0.1\n  - 0.5", map[string]any{"values": []any{0.1, 0.5}}},
+		{"список на уровне ключа", "values:\n- a\n- b\nnext: 1", map[string]any{"values": []any{"a", "b"}, "next": 1.0}},
+		{"словари в списке", "- name: a\n  value: 1\n- name: b\n-\n  - 2", []any{
+			map[string]any{"name": "a", "value": 1.0}, map[string]any{"name": "b"}, []any{2.0},
+		}},
+		{"пустые значения", "a:\nb:\n  -\n  - 1", map[string]any{"a": nil, "b": []any{nil, 1.0}}},
+		{"перевод строки CRLF", "a: 1\r\nb:\r\n  c: x\r\n", map[string]any{"a": 1.0, "b": map[string]any{"c": "x"}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := parseYAML(test.text)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("получено %#v, ожидалось %#v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestParseYAMLErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"табуляция", "a:\n\tb: 1", "строка 2: отступ табуляцией"},
+		{"повтор ключа", "a: 1\na: 2", "строка 2: ключ \"a\" повторяется"},
+		{"нет двоеточия", "a: 1\nb", "строка 2: ожидается «ключ: значение»"},
+		{"лишний отступ", "a: 1\n  b: 2", "строка 2: неожиданный отступ"},
+		{"отступ после документа", "  a: 1\nb: 2", "строка 2: неожиданный отступ"},
+		{"незакрытая скобка", "a: [1, 2", "строка 1: не закрыта скобка"},
+		{"нет запятой", "a: [1 2]x", "строка 1: лишний текст"},
+		{"незакрытая кавычка", "a: 'x", "строка 1: не закрыта кавычка"},
+		{"текст после кавычек", "a: \"x\" y", "строка 1: лишний текст"},
+		{"ключ в {..}", "a: {b}", "строка 1: ожидается «ключ: значение» в {..}"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := parseYAML(test.text)
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Errorf("ошибка %v, ожидалась %q", err, test.want)
+			}
+		})
+	}
+}
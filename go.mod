@@ -0,0 +1,3 @@
+module github.com/RiddlerXenon/roi
+
+go 1.24
@@ -0,0 +1,93 @@
+package jsmath
+
+import "math"
+
+// Коэффициенты fdlibm e_exp.c
+const (
+	expOThreshold = 7.09782712893383973096e+02
+	expUThreshold = -7.45133219101941108420e+02
+	expInvLn2     = 1.44269504088896338700e+00
+	expP1         = 1.66666666666666019037e-01
+	expP2         = -2.77777777770155933842e-03
+	expP3         = 6.61375632143793436117e-05
+	expP4         = -1.65339022054652515390e-06
+	expP5         = 4.13813679705723846039e-08
+	expTwom1000   = 9.33263618503218878990e-302
+	expTwo1023    = 8.988465674311579539e307
+)
+
+var (
+	expHalf  = [2]float64{0.5, -0.5}
+	expLn2Hi = [2]float64{6.93147180369123816490e-01, -6.93147180369123816490e-01}
+	expLn2Lo = [2]float64{1.90821492927058770002e-10, -1.90821492927058770002e-10}
+)
+
+// Exp — Math.exp: перенос __ieee754_exp. Как и в V8, exp(1) возвращает ровно Math.E
+func Exp(x float64) float64 {
+	hx := uint32(highWord(x))
+	xsb := hx >> 31
+	hx &= 0x7fffffff
+
+	// |x| >= 709.78...: переполнение, исчезновение порядка, бесконечность или NaN
+	if hx >= 0x40862e42 {
+		if hx >= 0x7ff00000 {
+			if hx&0xfffff|lowWord(x) != 0 {
+				return x + x
+			}
+			if xsb == 0 {
+				return x
+			}
+			return 0
+		}
+		if x > expOThreshold {
+			return math.Inf(1)
+		}
+		if x < expUThreshold {
+			return 0
+		}
+	}
+
+	// Приведение аргумента: x = k*ln2 + r, |r| <= 0.5*ln2
+	var hi, lo float64
+	k := int32(0)
+	switch {
+	case hx > 0x3fd62e42:
+		if hx < 0x3ff0a2b2 {
+			if x == 1 {
+				return math.E
+			}
+			hi = x - expLn2Hi[xsb]
+			lo = expLn2Lo[xsb]
+			k = 1 - int32(xsb) - int32(xsb)
+		} else {
+			k = int32(float64(expInvLn2*x) + expHalf[xsb])
+			t := float64(k)
+			hi = x - float64(t*expLn2Hi[0])
+			lo = float64(t * expLn2Lo[0])
+		}
+		x = hi - lo
+	case hx < 0x3e300000:
+		// |x| < 2**-28
+		return 1 + x
+	}
+
+	t := x * x
+	var twopk float64
+	if k >= -1021 {
+		twopk = math.Float64frombits(uint64(0x3ff00000+k<<20) << 32)
+	} else {
+		twopk = math.Float64frombits(uint64(0x3ff00000+(k+1000)<<20) << 32)
+	}
+	c := x - float64(t*(expP1+float64(t*(expP2+float64(t*(expP3+float64(t*(expP4+float64(t*expP5)))))))))
+	if k == 0 {
+		return 1 - (float64(x*c)/(c-2) - x)
+	}
+	y := 1 - ((lo - float64(x*c)/(2-c)) - hi)
+	switch {
+	case k == 1024:
+		return y * 2 * expTwo1023
+	case k >= -1021:
+		return y * twopk
+	}
+	return y * twopk * expTwom1000
+}
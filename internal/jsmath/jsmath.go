@@ -0,0 +1,46 @@
+// Package jsmath повторяет бит в бит математические функции JavaScript в том виде,
+// в каком они собраны в V8. Функции math округляют иначе, и симуляции, перенесенные
+// со страниц, расходились бы с браузером уже через несколько шагов.
+//
+// Pow, Exp, Log и Cos — переносы fdlibm из src/base/ieee754.cc. Преобразования float64(a*b)
+// запрещают компилятору сливать умножение со сложением (FMA на arm64): округление
+// должно идти после каждой операции, как в C
+package jsmath
+
+import "math"
+
+func highWord(x float64) int32 { return int32(math.Float64bits(x) >> 32) }
+func lowWord(x float64) uint32 { return uint32(math.Float64bits(x)) }
+
+func withHighWord(x float64, hi int32) float64 {
+	return math.Float64frombits(uint64(uint32(hi))<<32 | uint64(lowWord(x)))
+}
+
+func clearLowWord(x float64) float64 {
+	return math.Float64frombits(math.Float64bits(x) &^ 0xffffffff)
+}
+
+// Hypot — Math.hypot двух чисел: нормировка на максимум и суммирование Кэхэна, как в V8
+func Hypot(a, b float64) float64 {
+	a, b = math.Abs(a), math.Abs(b)
+	if math.IsInf(a, 0) || math.IsInf(b, 0) {
+		return math.Inf(1)
+	}
+	if math.IsNaN(a) || math.IsNaN(b) {
+		return math.NaN()
+	}
+	largest := max(a, b)
+	if largest == 0 {
+		return 0
+	}
+
+	sum, compensation := 0.0, 0.0
+	for _, value := range [2]float64{a, b} {
+		n := value / largest
+		summand := float64(n*n) - compensation
+		preliminary := sum + summand
+		compensation = (preliminary - sum) - summand
+		sum = preliminary
+	}
+	return math.Sqrt(sum) * largest
+}
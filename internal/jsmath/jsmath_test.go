@@ -0,0 +1,142 @@
+package jsmath
+
+import (
+	"math"
+	"testing"
+)
+
+var (
+	inf     = math.Inf(1)
+	nan     = math.NaN()
+	negZero = math.Copysign(0, -1)
+)
+
+// v8Bits — результаты Math.pow, Math.exp, Math.log и Math.cos в V8 (Node 20), записанные
+// скриптом testdata/bits.mjs. Для функций одного аргумента y не используется
+var v8Bits = []struct {
+	name string
+	x, y float64
+	bits uint64
+}{
+	// pow
+	{"pow", 2, 10, 0x4090000000000000},
+	{"pow", 2, 0.5, 0x3ff6a09e667f3bcd},
+	{"pow", 0.1, 3, 0x3f50624dd2f1a9fd},
+	{"pow", 1.5, 2.5, 0x40060b9fd68a4554},
+	{"pow", 3, 0.1, 0x3ff1dba3f92c888b},
+	{"pow", 7, -0.5, 0x3fd83091e6a7f7e7},
+	{"pow", 2.718281828459045, 3.3, 0x403b1cd5e7807b7a},
+	{"pow", 123.456, 2, 0x40cdc4b124d099e1},
+	{"pow", 1.0000001, 1e7, 0x4005bf0a790ce6f2},
+	{"pow", 10, -308, 0x000730d67819e8d2},
+	{"pow", -2, 3, 0xc020000000000000},
+	{"pow", -2, 4, 0x4030000000000000},
+	{"pow", -2, -3, 0xbfc0000000000000},
+	{"pow", -0.5, 5, 0xbfa0000000000000},
+	{"pow", -1.5, -7, 0xbfadf75680feb65f},
+	{"pow", -8, 1.0 / 3, 0x7ff4000000000000},
+	{"pow", 2, -1074, 0x0000000000000001},
+	{"pow", 2, -1075, 0x0000000000000000},
+	{"pow", 0.5, 1074, 0x0000000000000001},
+	{"pow", 5e-324, 0.5, 0x1e60000000000000},
+	{"pow", 5e-324, 1, 0x0000000000000001},
+	{"pow", 2.2250738585072014e-308, 0.5, 0x2000000000000000},
+	{"pow", 1e-160, 2, 0x00000000000007e8},
+	{"pow", 1e308, 2, 0x7ff0000000000000},
+	{"pow", 0, -1, 0x7ff0000000000000},
+	{"pow", negZero, -1, 0xfff0000000000000},
+	{"pow", negZero, 3, 0x8000000000000000},
+	{"pow", negZero, 0.5, 0x0000000000000000},
+	{"pow", inf, -1, 0x0000000000000000},
+	{"pow", -inf, 3, 0xfff0000000000000},
+	{"pow", -inf, 2, 0x7ff0000000000000},
+	{"pow", -inf, -3, 0x8000000000000000},
+	{"pow", 0.9, inf, 0x0000000000000000},
+	{"pow", 1.5, -inf, 0x0000000000000000},
+	{"pow", -1, inf, 0xfff8000000000000},
+	{"pow", 1, inf, 0xfff8000000000000},
+	{"pow", 1, nan, 0x7ff8000000000000},
+	{"pow", nan, 0, 0x3ff0000000000000},
+	{"pow", nan, 1, 0x7ff8000000000000},
+	// exp
+	{"exp", 0, 0, 0x3ff0000000000000},
+	{"exp", 1, 0, 0x4005bf0a8b145769},
+	{"exp", -1, 0, 0x3fd78b56362cef38},
+	{"exp", 0.5, 0, 0x3ffa61298e1e069c},
+	{"exp", -0.1, 0, 0x3fecf46d99d52b3a},
+	{"exp", 3.3, 0, 0x403b1cd5e7807b7b},
+	{"exp", 1e-10, 0, 0x3ff000000006df38},
+	{"exp", 88.7, 0, 0x47ef4705bbffae5c},
+	{"exp", 709.78, 0, 0x7fefe9ce5c4c52b4},
+	{"exp", 709.8, 0, 0x7ff0000000000000},
+	{"exp", -708.5, 0, 0x000e6cf6d08897ac},
+	{"exp", -740, 0, 0x0000000000000055},
+	{"exp", -745.1, 0, 0x0000000000000001},
+	{"exp", -746, 0, 0x0000000000000000},
+	{"exp", 5e-324, 0, 0x3ff0000000000000},
+	{"exp", negZero, 0, 0x3ff0000000000000},
+	{"exp", inf, 0, 0x7ff0000000000000},
+	{"exp", -inf, 0, 0x0000000000000000},
+	{"exp", nan, 0, 0x7ff8000000000000},
+	// log
+	{"log", 1, 0, 0x0000000000000000},
+	{"log", 2, 0, 0x3fe62e42fefa39ef},
+	{"log", 0.5, 0, 0xbfe62e42fefa39ef},
+	{"log", 10, 0, 0x40026bb1bbb55516},
+	{"log", 3.3, 0, 0x3ff31a4e7240c777},
+	{"log", 1.0000001, 0, 0x3e7ad7f2847b6492},
+	{"log", 0.9999999, 0, 0xbe7ad7f2b1049b9f},
+	{"log", 1e308, 0, 0x40862991d5d62a5e},
+	{"log", 2.2250738585072014e-308, 0, 0xc086232bdd7abcd2},
+	{"log", 1e-310, 0, 0xc0864e69394d9508},
+	{"log", 5e-324, 0, 0xc0874385446d71c3},
+	{"log", 0, 0, 0xfff0000000000000},
+	{"log", negZero, 0, 0xfff0000000000000},
+	{"log", -1, 0, 0x7ff4000000000000},
+	{"log", inf, 0, 0x7ff0000000000000},
+	{"log", -inf, 0, 0x7ff4000000000000},
+	{"log", nan, 0, 0x7ff8000000000000},
+	// cos
+	{"cos", 0, 0, 0x3ff0000000000000},
+	{"cos", 5e-324, 0, 0x3ff0000000000000},
+	{"cos", 0.5, 0, 0x3fec1528065b7d50},
+	{"cos", -1, 0, 0x3fe14a280fb5068c},
+	{"cos", 0.7853981633974483, 0, 0x3fe6a09e667f3bcd},
+	{"cos", 1.5707963267948966, 0, 0x3c91a62633145c07},
+	{"cos", 3.141592653589793, 0, 0xbff0000000000000},
+	{"cos", 100, 0, 0x3feb981dbf665fdf},
+	{"cos", 1e5, 0, 0xbfeffac3841b3da7},
+	{"cos", -823549.6, 0, 0x3fefeeebfee47670},
+	{"cos", 823550.5, 0, 0x3fe577c0ab2aeb3e},
+	{"cos", 1e6, 0, 0x3fedf9df9906d32c},
+	{"cos", -1e10, 0, 0x3febf098901c931a},
+	{"cos", 1e22, 0, 0x3fe0be2cef01c8f4},
+	{"cos", 1e300, 0, 0xbfe2699022adc4c1},
+	{"cos", 1.7976931348623157e308, 0, 0xbfefffe62ecfab75},
+	{"cos", inf, 0, 0xfff8000000000000},
+	{"cos", nan, 0, 0x7ff8000000000000},
+}
+
+func TestV8Bits(t *testing.T) {
+	functions := map[string]func(x, y float64) float64{
+		"pow": Pow,
+		"exp": func(x, _ float64) float64 { return Exp(x) },
+		"log": func(x, _ float64) float64 { return Log(x) },
+		"cos": func(x, _ float64) float64 { return Cos(x) },
+	}
+	for _, test := range v8Bits {
+		got := functions[test.name](test.x, test.y)
+		want := math.Float64frombits(test.bits)
+		// NaN в V8 и в Go кодируются разными битами, достаточно того, что это NaN
+		if math.IsNaN(want) {
+			if !math.IsNaN(got) {
+				t.Errorf("%s(%v, %v) = %v, ожидалось NaN", test.name, test.x, test.y, got)
+			}
+			continue
+		}
+		if math.Float64bits(got) != test.bits {
+			t.Errorf("%s(%v, %v) = %v (%#016x), V8 дает %v (%#016x)",
+				test.name, test.x, test.y, got, math.Float64bits(got), want, test.bits)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package jsmath
+
+import "math"
+
+// Коэффициенты fdlibm e_log.c
+const (
+	logLn2Hi = 6.93147180369123816490e-01
+	logLn2Lo = 1.90821492927058770002e-10
+	logTwo54 = 1.80143985094819840000e+16
+	logLg1   = 6.666666666666735130e-01
+	logLg2   = 3.999999999940941908e-01
+	logLg3   = 2.857142874366239149e-01
+	logLg4   = 2.222219843214978396e-01
+	logLg5   = 1.818357216161805012e-01
+	logLg6   = 1.531383769920937332e-01
+	logLg7   = 1.479819860511658591e-01
+)
+
+// Log — Math.log: перенос __ieee754_log
+func Log(x float64) float64 {
+	hx, lx := highWord(x), lowWord(x)
+
+	k := int32(0)
+	if hx < 0x00100000 {
+		switch {
+		case hx&0x7fffffff|int32(lx) == 0:
+			return math.Inf(-1)
+		case hx < 0:
+			return math.NaN()
+		}
+		// Денормализованное число
+		k -= 54
+		x *= logTwo54
+		hx = highWord(x)
+	}
+	if hx >= 0x7ff00000 {
+		return x + x
+	}
+	k += hx>>20 - 1023
+	hx &= 0x000fffff
+	i := (hx + 0x95f64) & 0x100000
+	// Нормировка x или x/2 к [sqrt(2)/2, sqrt(2))
+	x = withHighWord(x, hx|(i^0x3ff00000))
+	k += i >> 20
+	f := x - 1
+	dk := float64(k)
+
+	// |f| < 2**-20
+	if 0x000fffff&(2+hx) < 3 {
+		if f == 0 {
+			if k == 0 {
+				return 0
+			}
+			return float64(dk*logLn2Hi) + float64(dk*logLn2Lo)
+		}
+		r := float64(f*f) * (0.5 - float64(0.33333333333333333*f))
+		if k == 0 {
+			return f - r
+		}
+		return float64(dk*logLn2Hi) - ((r - float64(dk*logLn2Lo)) - f)
+	}
+
+	s := f / (2 + f)
+	z := s * s
+	i = hx - 0x6147a
+	w := z * z
+	j := 0x6b851 - hx
+	t1 := float64(w * (logLg2 + float64(w*(logLg4+float64(w*logLg6)))))
+	t2 := float64(z * (logLg1 + float64(w*(logLg3+float64(w*(logLg5+float64(w*logLg7)))))))
+	i |= j
+	r := t2 + t1
+	if i > 0 {
+		hfsq := float64(0.5*f) * f
+		if k == 0 {
+			return f - (hfsq - float64(s*(hfsq+r)))
+		}
+		return float64(dk*logLn2Hi) - ((hfsq - (float64(s*(hfsq+r)) + float64(dk*logLn2Lo))) - f)
+	}
+	if k == 0 {
+		return f - float64(s*(f-r))
+	}
+	return float64(dk*logLn2Hi) - ((float64(s*(f-r)) - float64(dk*logLn2Lo)) - f)
+}
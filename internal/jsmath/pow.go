@@ -0,0 +1,275 @@
+package jsmath
+
+import "math"
+
+const (
+	powTwo53 = 9007199254740992.0
+
+	// Коэффициенты fdlibm e_pow.c
+	powL1     = 5.99999999999994648725e-01
+	powL2     = 4.28571428578550184252e-01
+	powL3     = 3.33333329818377432918e-01
+	powL4     = 2.72728123808534006489e-01
+	powL5     = 2.30660745775561754067e-01
+	powL6     = 2.06975017800338417784e-01
+	powP1     = 1.66666666666666019037e-01
+	powP2     = -2.77777777770155933842e-03
+	powP3     = 6.61375632143793436117e-05
+	powP4     = -1.65339022054652515390e-06
+	powP5     = 4.13813679705723846039e-08
+	powLg2    = 6.93147180559945286227e-01
+	powLg2H   = 6.93147182464599609375e-01
+	powLg2L   = -1.90465429995776804525e-09
+	powOvt    = 8.0085662595372944372e-17
+	powCp     = 9.61796693925975554329e-01
+	powCpH    = 9.61796700954437255859e-01
+	powCpL    = -7.02846165095275826516e-09
+	powIvln2  = 1.44269504088896338700e+00
+	powIvln2H = 1.44269502162933349609e+00
+	powIvln2L = 1.92596299112661746887e-08
+)
+
+var (
+	// Переменные, а не константы: произведение powHuge*powHuge должно давать +Inf во время выполнения
+	powHuge = 1.0e300
+	powTiny = 1.0e-300
+
+	powBp  = [2]float64{1.0, 1.5}
+	powDpH = [2]float64{0.0, 5.84962487220764160156e-01}
+	powDpL = [2]float64{0.0, 1.35003920212974897128e-08}
+)
+
+// Pow — Math.pow: перенос __ieee754_pow из fdlibm с одним отличием V8 в вычислении r
+func Pow(x, y float64) float64 {
+	hx, lx := highWord(x), lowWord(x)
+	hy, ly := highWord(y), lowWord(y)
+	ix, iy := hx&0x7fffffff, hy&0x7fffffff
+
+	// x**0 = 1
+	if iy|int32(ly) == 0 {
+		return 1
+	}
+	if ix > 0x7ff00000 || (ix == 0x7ff00000 && lx != 0) || iy > 0x7ff00000 || (iy == 0x7ff00000 && ly != 0) {
+		return x + y
+	}
+
+	// yisint: 0 — y не целое, 1 — нечетное целое, 2 — четное целое
+	yisint := int32(0)
+	if hx < 0 {
+		if iy >= 0x43400000 {
+			yisint = 2
+		} else if iy >= 0x3ff00000 {
+			k := (iy >> 20) - 0x3ff
+			if k > 20 {
+				j := ly >> (52 - k)
+				if j<<(52-k) == ly {
+					yisint = 2 - int32(j&1)
+				}
+			} else if ly == 0 {
+				j := iy >> (20 - k)
+				if j<<(20-k) == iy {
+					yisint = 2 - (j & 1)
+				}
+			}
+		}
+	}
+
+	if ly == 0 {
+		if iy == 0x7ff00000 {
+			switch {
+			case (ix-0x3ff00000)|int32(lx) == 0:
+				return y - y
+			case ix >= 0x3ff00000:
+				if hy >= 0 {
+					return y
+				}
+				return 0
+			default:
+				if hy < 0 {
+					return -y
+				}
+				return 0
+			}
+		}
+		if iy == 0x3ff00000 {
+			if hy < 0 {
+				return 1 / x
+			}
+			return x
+		}
+		if hy == 0x40000000 {
+			return x * x
+		}
+		if hy == 0x3fe00000 && hx >= 0 {
+			return math.Sqrt(x)
+		}
+	}
+
+	ax := math.Abs(x)
+	if lx == 0 && (ix == 0x7ff00000 || ix == 0 || ix == 0x3ff00000) {
+		z := ax
+		if hy < 0 {
+			z = 1 / z
+		}
+		if hx < 0 {
+			if (ix-0x3ff00000)|yisint == 0 {
+				z = (z - z) / (z - z)
+			} else if yisint == 1 {
+				z = -z
+			}
+		}
+		return z
+	}
+
+	n := (hx >> 31) + 1
+	if n|yisint == 0 {
+		return (x - x) / (x - x)
+	}
+	s := 1.0
+	if n|(yisint-1) == 0 {
+		s = -1
+	}
+
+	var t1, t2 float64
+	if iy > 0x41e00000 {
+		// |y| > 2**31
+		if iy > 0x43f00000 {
+			if ix <= 0x3fefffff {
+				if hy < 0 {
+					return powHuge * powHuge
+				}
+				return powTiny * powTiny
+			}
+			if ix >= 0x3ff00000 {
+				if hy > 0 {
+					return powHuge * powHuge
+				}
+				return powTiny * powTiny
+			}
+		}
+		if ix < 0x3fefffff {
+			if hy < 0 {
+				return s * powHuge * powHuge
+			}
+			return s * powTiny * powTiny
+		}
+		if ix > 0x3ff00000 {
+			if hy > 0 {
+				return s * powHuge * powHuge
+			}
+			return s * powTiny * powTiny
+		}
+		t := ax - 1
+		w := float64(t*t) * (0.5 - float64(t*(0.3333333333333333333333-float64(t*0.25))))
+		u := float64(powIvln2H * t)
+		v := float64(t*powIvln2L) - float64(w*powIvln2)
+		t1 = clearLowWord(u + v)
+		t2 = v - (t1 - u)
+	} else {
+		n = 0
+		if ix < 0x00100000 {
+			ax *= powTwo53
+			n -= 53
+			ix = highWord(ax)
+		}
+		n += (ix >> 20) - 0x3ff
+		j := ix & 0x000fffff
+		ix = j | 0x3ff00000
+		k := 0
+		switch {
+		case j <= 0x3988E:
+			k = 0
+		case j < 0xBB67A:
+			k = 1
+		default:
+			n++
+			ix -= 0x00100000
+		}
+		ax = withHighWord(ax, ix)
+
+		// ss = sH+sL = (x-1)/(x+1) или (x-1.5)/(x+1.5)
+		u := ax - powBp[k]
+		v := 1 / (ax + powBp[k])
+		ss := float64(u * v)
+		sH := clearLowWord(ss)
+		tH := math.Float64frombits(uint64(uint32(((ix>>1)|0x20000000)+0x00080000+int32(k<<18))) << 32)
+		tL := ax - (tH - powBp[k])
+		sL := v * (float64(u-float64(sH*tH)) - float64(sH*tL))
+
+		// log(ax)
+		s2 := ss * ss
+		poly := powL6
+		for _, c := range [...]float64{powL5, powL4, powL3, powL2, powL1} {
+			poly = c + float64(s2*poly)
+		}
+		r := float64(s2*s2) * poly
+		r += float64(sL * (sH + ss))
+		s2 = float64(sH * sH)
+		tH = clearLowWord(3.0 + s2 + r)
+		tL = r - ((tH - 3.0) - s2)
+
+		u = float64(sH * tH)
+		v = float64(sL*tH) + float64(tL*ss)
+		pH := clearLowWord(u + v)
+		pL := v - (pH - u)
+		zH := float64(powCpH * pH)
+		zL := float64(powCpL*pH) + float64(pL*powCp) + powDpL[k]
+
+		t := float64(n)
+		t1 = clearLowWord(((zH + zL) + powDpH[k]) + t)
+		t2 = zL - (((t1 - t) - powDpH[k]) - zH)
+	}
+
+	// (y1+y2)*(t1+t2)
+	y1 := clearLowWord(y)
+	pL := float64((y-y1)*t1) + float64(y*t2)
+	pH := float64(y1 * t1)
+	z := pL + pH
+	j := highWord(z)
+	i := int32(lowWord(z))
+	if j >= 0x40900000 {
+		if (j-0x40900000)|i != 0 || pL+powOvt > z-pH {
+			return s * powHuge * powHuge
+		}
+	} else if j&0x7fffffff >= 0x4090cc00 {
+		if (j-int32(-0x3f6f3400))|i != 0 || pL <= z-pH {
+			return s * powTiny * powTiny
+		}
+	}
+
+	// 2**(pH+pL)
+	i = j & 0x7fffffff
+	k := (i >> 20) - 0x3ff
+	n = 0
+	if i > 0x3fe00000 {
+		n = j + (0x00100000 >> (k + 1))
+		k = ((n & 0x7fffffff) >> 20) - 0x3ff
+		t := math.Float64frombits(uint64(uint32(n&^(0x000fffff>>k))) << 32)
+		n = ((n & 0x000fffff) | 0x00100000) >> (20 - k)
+		if j < 0 {
+			n = -n
+		}
+		pH -= t
+	}
+	t := clearLowWord(pL + pH)
+	u := float64(t * powLg2H)
+	v := float64((pL-(t-pH))*powLg2) + float64(t*powLg2L)
+	z = u + v
+	w := v - (z - u)
+	t = z * z
+	poly := powP5
+	for _, c := range [...]float64{powP4, powP3, powP2, powP1} {
+		poly = c + float64(t*poly)
+	}
+	t1 = z - float64(t*poly)
+	// V8 делит на (t1-2)-(w+z*w), а не вычитает w+z*w после деления, как fdlibm
+	r := float64(z*t1) / ((t1 - 2) - (w + float64(z*w)))
+	z = 1 - (r - z)
+	j = highWord(z) + n<<20
+	if j>>20 <= 0 {
+		z = math.Ldexp(z, int(n))
+	} else {
+		z = withHighWord(z, j)
+	}
+	return s * z
+}
@@ -0,0 +1,229 @@
+package jsmath
+
+import "math"
+
+// twoOverPi — 1584 бита 2/pi по 24 бита в слове, таблица two_over_pi из e_rem_pio2.c
+var twoOverPi = [66]int32{
+	0xA2F983, 0x6E4E44, 0x1529FC, 0x2757D1, 0xF534DD, 0xC0DB62, 0x95993C,
+	0x439041, 0xFE5163, 0xABDEBB, 0xC561B7, 0x246E3A, 0x424DD2, 0xE00649,
+	0x2EEA09, 0xD1921C, 0xFE1DEB, 0x1CB129, 0xA73EE8, 0x8235F5, 0x2EBB44,
+	0x84E99C, 0x7026B4, 0x5F7E41, 0x3991D6, 0x398353, 0x39F49C, 0x845F8B,
+	0xBDF928, 0x3B1FF8, 0x97FFDE, 0x05980F, 0xEF2F11, 0x8B5A0A, 0x6D1F6D,
+	0x367ECF, 0x27CB09, 0xB74F46, 0x3F669E, 0x5FEA2D, 0x7527BA, 0xC7EBE5,
+	0xF17B3D, 0x0739F7, 0x8A5292, 0xEA6BFB, 0x5FB11F, 0x8D5D08, 0x560330,
+	0x46FC7B, 0x6BABF0, 0xCFBC20, 0x9AF436, 0x1DA9E3, 0x91615E, 0xE61B08,
+	0x659985, 0x5F14A0, 0x68408D, 0xFFD880, 0x4D7327, 0x310606, 0x1556CA,
+	0x73A8C9, 0x60E27B, 0xC08C6B,
+}
+
+// pio2Chunks — pi/2 по 24 бита, таблица PIo2 из k_rem_pio2.c
+var pio2Chunks = [8]float64{
+	1.57079625129699707031e+00,
+	7.54978941586159635335e-08,
+	5.39030252995776476554e-15,
+	3.28200341580791294123e-22,
+	1.27065575308067607349e-29,
+	1.22933308981111328932e-36,
+	2.73370053816464559624e-44,
+	2.16741683877804819444e-51,
+}
+
+const (
+	two24  = 1.67772160000000000000e+07
+	twon24 = 5.96046447753906250000e-08
+)
+
+// remPio2Large — ветка __ieee754_rem_pio2 для |x| > 2**19*pi/2: x раскладывается
+// на три 24-битные части и приводится по таблице 2/pi
+func remPio2Large(x float64) (n int32, y0, y1 float64) {
+	hx := highWord(x)
+	ix := hx & 0x7fffffff
+
+	// z = scalbn(|x|, ilogb(x)-23)
+	e0 := ix>>20 - 1046
+	z := math.Float64frombits(uint64(uint32(ix-e0<<20))<<32 | uint64(lowWord(x)))
+	var tx [3]float64
+	for i := range 2 {
+		tx[i] = float64(int32(z))
+		z = float64((z - tx[i]) * two24)
+	}
+	tx[2] = z
+	nx := 3
+	for tx[nx-1] == 0 {
+		nx--
+	}
+
+	n, y0, y1 = kernelRemPio2(tx[:nx], int(e0))
+	if hx < 0 {
+		return -n, -y0, -y1
+	}
+	return n, y0, y1
+}
+
+// kernelRemPio2 — __kernel_rem_pio2 с точностью prec = 2 (53 бита, результат y0+y1):
+// x — 24-битные части аргумента, e0 — показатель x[0] минус 23
+func kernelRemPio2(x []float64, e0 int) (int32, float64, float64) {
+	const jk = 4
+	jp := jk
+
+	var (
+		iq    [20]int32
+		f, fq [20]float64
+		q     [20]float64
+	)
+	jx := len(x) - 1
+	jv := max((e0-3)/24, 0)
+	q0 := e0 - 24*(jv+1)
+
+	// f[0..jx+jk], где f[jx+jk] = twoOverPi[jv+jk]
+	for i, j := 0, jv-jx; i <= jx+jk; i, j = i+1, j+1 {
+		if j >= 0 {
+			f[i] = float64(twoOverPi[j])
+		}
+	}
+	for i := 0; i <= jk; i++ {
+		fw := 0.0
+		for j := 0; j <= jx; j++ {
+			fw += float64(x[j] * f[jx+i-j])
+		}
+		q[i] = fw
+	}
+
+	jz := jk
+	var (
+		n  int32
+		ih int32
+		z  float64
+	)
+	for {
+		// Раскладывает q[] в iq[] в обратном порядке
+		z = q[jz]
+		for i, j := 0, jz; j > 0; i, j = i+1, j-1 {
+			fw := float64(int32(float64(twon24 * z)))
+			iq[i] = int32(z - float64(two24*fw))
+			z = q[j-1] + fw
+		}
+
+		z = math.Ldexp(z, q0)
+		z -= float64(8 * math.Floor(float64(z*0.125)))
+		n = int32(z)
+		z -= float64(n)
+		ih = 0
+		switch {
+		case q0 > 0:
+			// Для n нужен iq[jz-1]
+			i := iq[jz-1] >> (24 - q0)
+			n += i
+			iq[jz-1] -= i << (24 - q0)
+			ih = iq[jz-1] >> (23 - q0)
+		case q0 == 0:
+			ih = iq[jz-1] >> 23
+		case z >= 0.5:
+			ih = 2
+		}
+
+		if ih > 0 {
+			// Остаток больше 0.5: берется 1 - q
+			n++
+			carry := false
+			for i := 0; i < jz; i++ {
+				j := iq[i]
+				switch {
+				case carry:
+					iq[i] = 0xffffff - j
+				case j != 0:
+					carry = true
+					iq[i] = 0x1000000 - j
+				}
+			}
+			switch q0 {
+			case 1:
+				iq[jz-1] &= 0x7fffff
+			case 2:
+				iq[jz-1] &= 0x3fffff
+			}
+			if ih == 2 {
+				z = 1 - z
+				if carry {
+					z -= math.Ldexp(1, q0)
+				}
+			}
+		}
+
+		// Если все биты остатка нулевые, нужны следующие слова 2/pi
+		if z != 0 {
+			break
+		}
+		var j int32
+		for i := jz - 1; i >= jk; i-- {
+			j |= iq[i]
+		}
+		if j != 0 {
+			break
+		}
+		k := 1
+		for jk >= k && iq[jk-k] == 0 {
+			k++
+		}
+		for i := jz + 1; i <= jz+k; i++ {
+			f[jx+i] = float64(twoOverPi[jv+i])
+			fw := 0.0
+			for j := 0; j <= jx; j++ {
+				fw += float64(x[j] * f[jx+i-j])
+			}
+			q[i] = fw
+		}
+		jz += k
+	}
+
+	// Отбрасывает нулевые слова или делит z на 24-битные части
+	if z == 0 {
+		jz--
+		q0 -= 24
+		for iq[jz] == 0 {
+			jz--
+			q0 -= 24
+		}
+	} else {
+		z = math.Ldexp(z, -q0)
+		if z >= two24 {
+			fw := float64(int32(float64(twon24 * z)))
+			iq[jz] = int32(z - float64(two24*fw))
+			jz++
+			q0 += 24
+			iq[jz] = int32(fw)
+		} else {
+			iq[jz] = int32(z)
+		}
+	}
+
+	fw := math.Ldexp(1, q0)
+	for i := jz; i >= 0; i-- {
+		q[i] = float64(fw * float64(iq[i]))
+		fw *= twon24
+	}
+
+	// fq = PIo2[0..jp] * q[jz..0]
+	for i := jz; i >= 0; i-- {
+		fw := 0.0
+		for k := 0; k <= jp && k <= jz-i; k++ {
+			fw += float64(pio2Chunks[k] * q[i+k])
+		}
+		fq[jz-i] = fw
+	}
+
+	fw = 0
+	for i := jz; i >= 0; i-- {
+		fw += fq[i]
+	}
+	y0 := fw
+	fw = fq[0] - fw
+	for i := 1; i <= jz; i++ {
+		fw += fq[i]
+	}
+	y1 := fw
+	if ih != 0 {
+		y0, y1 = -y0, -y1
+	}
+	return n & 7, y0, y1
+}
@@ -0,0 +1,54 @@
+// Печатает таблицу для jsmath_test.go: аргументы и битовые шаблоны результатов
+// Math.pow, Math.exp, Math.log и Math.cos в V8.
+//
+//   node internal/jsmath/testdata/bits.mjs
+//
+// Аргументы записаны выражениями, которые одинаково читаются в JavaScript и Go;
+// inf, nan и negZero в тесте — бесконечность, NaN и отрицательный ноль
+const inf = Infinity;
+const nan = NaN;
+const negZero = -0;
+
+const cases = {
+  pow: [
+    ['2', '10'], ['2', '0.5'], ['0.1', '3'], ['1.5', '2.5'], ['3', '0.1'], ['7', '-0.5'],
+    ['2.718281828459045', '3.3'], ['123.456', '2'], ['1.0000001', '1e7'], ['10', '-308'],
+    // Отрицательное основание: целый показатель задает знак, дробный дает NaN
+    ['-2', '3'], ['-2', '4'], ['-2', '-3'], ['-0.5', '5'], ['-1.5', '-7'], ['-8', '1.0 / 3'],
+    // Субнормальные числа
+    ['2', '-1074'], ['2', '-1075'], ['0.5', '1074'], ['5e-324', '0.5'], ['5e-324', '1'],
+    ['2.2250738585072014e-308', '0.5'], ['1e-160', '2'],
+    // Переполнение, нули, бесконечности и NaN; pow(1, ±inf) и pow(1, nan) в JS — NaN
+    ['1e308', '2'], ['0', '-1'], ['negZero', '-1'], ['negZero', '3'], ['negZero', '0.5'],
+    ['inf', '-1'], ['-inf', '3'], ['-inf', '2'], ['-inf', '-3'], ['0.9', 'inf'], ['1.5', '-inf'],
+    ['-1', 'inf'], ['1', 'inf'], ['1', 'nan'], ['nan', '0'], ['nan', '1'],
+  ],
+  exp: [
+    ['0'], ['1'], ['-1'], ['0.5'], ['-0.1'], ['3.3'], ['1e-10'], ['88.7'], ['709.78'], ['709.8'],
+    ['-708.5'], ['-740'], ['-745.1'], ['-746'], ['5e-324'], ['negZero'], ['inf'], ['-inf'], ['nan'],
+  ],
+  log: [
+    ['1'], ['2'], ['0.5'], ['10'], ['3.3'], ['1.0000001'], ['0.9999999'], ['1e308'],
+    ['2.2250738585072014e-308'], ['1e-310'], ['5e-324'], ['0'], ['negZero'], ['-1'], ['inf'], ['-inf'], ['nan'],
+  ],
+  cos: [
+    ['0'], ['5e-324'], ['0.5'], ['-1'], ['0.7853981633974483'], ['1.5707963267948966'], ['3.141592653589793'],
+    ['100'], ['1e5'], ['-823549.6'],
+    // Больше 2**19*pi/2: приведение по таблице 2/pi
+    ['823550.5'], ['1e6'], ['-1e10'], ['1e22'], ['1e300'], ['1.7976931348623157e308'],
+    ['inf'], ['nan'],
+  ],
+};
+
+const f64 = new Float64Array(1);
+const u64 = new BigUint64Array(f64.buffer);
+for (const [name, rows] of Object.entries(cases)) {
+  const fn = Math[name];
+  console.log(`\t// ${name}`);
+  for (const args of rows) {
+    f64[0] = fn(...args.map((a) => eval(a)));
+    const bits = '0x' + u64[0].toString(16).padStart(16, '0');
+    const y = args.length > 1 ? args[1] : '0';
+    console.log(`\t{"${name}", ${args[0]}, ${y}, ${bits}},`);
+  }
+}
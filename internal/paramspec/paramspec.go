@@ -0,0 +1,179 @@
+// Package paramspec читает описания параметров static/latex/params/*.tex и проверяет
+// объявленные в них ограничения.
+//
+// Формат тот же, что разбирает parseParamSpecs конвертера в utils/latex/params.go: блоки,
+// разделенные пустой строкой, первая строка блока — имя и необязательное ограничение
+//
+//	rho !!! \in (0,1] !!!
+//	Коэффициент испарения $\rho\in(0,1]$
+//	Описание
+package paramspec
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Spec — описание одного параметра
+type Spec struct {
+	Name        string `json:"name"`
+	Constraint  string `json:"constraint,omitempty"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+
+	bounds *bounds
+}
+
+// Parse разбирает файл описаний. Ограничение, которое не удается прочитать, — ошибка:
+// иначе параметр молча остался бы без проверки
+func Parse(text string) ([]Spec, error) {
+	var specs []Spec
+	var block []string
+	blockStart := 0
+
+	flush := func() error {
+		if len(block) == 0 {
+			return nil
+		}
+		defer func() { block = nil }()
+
+		name, constraint, _ := strings.Cut(block[0], "!!!")
+		spec := Spec{
+			Name:       strings.TrimSpace(name),
+			Constraint: strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(constraint), "!!!")),
+		}
+		if len(block) > 1 {
+			spec.Title = strings.TrimSpace(block[1])
+		}
+		if len(block) > 2 {
+			spec.Description = strings.TrimSpace(strings.Join(block[2:], " "))
+		}
+		if spec.Constraint != "" {
+			b, err := parseBounds(spec.Constraint)
+			if err != nil {
+				return fmt.Errorf("строка %d, параметр %s: %w", blockStart, spec.Name, err)
+			}
+			spec.bounds = &b
+		}
+		specs = append(specs, spec)
+		return nil
+	}
+
+	for i, line := range strings.Split(text, "\n") {
+		if strings.TrimSpace(line) == "" {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if len(block) == 0 {
+			blockStart = i + 1
+		}
+		block = append(block, line)
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	return specs, nil
+}
+
+// Load читает и разбирает файл описаний
+func Load(path string) ([]Spec, error) {
+	text, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("чтение описаний параметров: %w", err)
+	}
+	specs, err := Parse(string(text))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return specs, nil
+}
+
+// Check проверяет значение на объявленное ограничение; без ограничения подходит любое число
+func (s Spec) Check(v float64) error {
+	if s.bounds == nil || s.bounds.contains(v) {
+		return nil
+	}
+	return fmt.Errorf("%s = %v: нарушено ограничение %s %s", s.Name, v, s.Name, s.Constraint)
+}
+
+// bounds — промежуток допустимых значений
+type bounds struct {
+	lo, hi         float64
+	loOpen, hiOpen bool
+}
+
+func (b bounds) contains(v float64) bool {
+	switch {
+	case math.IsNaN(v):
+		return false
+	case v < b.lo || b.loOpen && v == b.lo:
+		return false
+	case v > b.hi || b.hiOpen && v == b.hi:
+		return false
+	}
+	return true
+}
+
+var (
+	intervalPattern   = regexp.MustCompile(`^\\in\s*([(\[])\s*([^,]+?)\s*,\s*([^\])]+?)\s*([)\]])$`)
+	comparisonPattern = regexp.MustCompile(`^(>=|<=|>|<|\\geqslant|\\leqslant|\\geq?|\\leq?)\s*(.+)$`)
+)
+
+// parseBounds читает ограничение вида \in (a,b], > a, \geq a, < b или \leq b;
+// бесконечность записывается как \infty
+func parseBounds(text string) (bounds, error) {
+	if m := intervalPattern.FindStringSubmatch(text); m != nil {
+		lo, err := parseBound(m[2])
+		if err != nil {
+			return bounds{}, err
+		}
+		hi, err := parseBound(m[3])
+		if err != nil {
+			return bounds{}, err
+		}
+		if lo > hi {
+			return bounds{}, fmt.Errorf("пустой промежуток %s", text)
+		}
+		return bounds{lo: lo, hi: hi, loOpen: m[1] == "(", hiOpen: m[4] == ")"}, nil
+	}
+
+	if m := comparisonPattern.FindStringSubmatch(text); m != nil {
+		v, err := parseBound(m[2])
+		if err != nil {
+			return bounds{}, err
+		}
+		b := bounds{lo: math.Inf(-1), hi: math.Inf(1)}
+		switch m[1] {
+		case ">":
+			b.lo, b.loOpen = v, true
+		case ">=", `\ge`, `\geq`, `\geqslant`:
+			b.lo = v
+		case "<":
+			b.hi, b.hiOpen = v, true
+		default:
+			b.hi = v
+		}
+		return b, nil
+	}
+	return bounds{}, fmt.Errorf("ограничение %q не распознано: ожидается \\in (a,b], > a, \\geq a, < b или \\leq b", text)
+}
+
+func parseBound(text string) (float64, error) {
+	switch strings.ReplaceAll(text, " ", "") {
+	case `\infty`, `+\infty`:
+		return math.Inf(1), nil
+	case `-\infty`:
+		return math.Inf(-1), nil
+	}
+	v, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return 0, fmt.Errorf("граница %q не число", text)
+	}
+	return v, nil
+}
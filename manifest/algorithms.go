@@ -0,0 +1,135 @@
+package manifest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/RiddlerXenon/roi/aco"
+	"github.com/RiddlerXenon/roi/boids"
+	"github.com/RiddlerXenon/roi/sds"
+)
+
+// outcome — повтор прогона: трасса и то, что манифест записывает для сверки
+type outcome struct {
+	params    any
+	trace     any
+	graph     *aco.Graph
+	objective *Objective
+	result    any
+}
+
+type algorithm struct {
+	engineVersion int
+
+	// replay выполняет steps шагов с параметрами в JSON на графе inst, если он есть;
+	// every = 0 оставляет в трассе только последний шаг
+	replay func(params json.RawMessage, inst *aco.Instance, steps, every int) (outcome, error)
+}
+
+var algorithms = map[string]algorithm{
+	aco.Algorithm:   {aco.EngineVersion, replayACO},
+	sds.Algorithm:   {sds.EngineVersion, replaySDS},
+	boids.Algorithm: {boids.EngineVersion, replayBoids},
+}
+
+// Algorithms возвращает отсортированные имена алгоритмов, для которых есть повтор
+func Algorithms() []string {
+	names := make([]string, 0, len(algorithms))
+	for name := range algorithms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// decodeParams накладывает параметры из манифеста на параметры страницы по умолчанию.
+// Неизвестное поле — ошибка: опечатка в имени иначе молча дала бы другой прогон
+func decodeParams(data json.RawMessage, params any) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(params); err != nil {
+		return fmt.Errorf("параметры: %w", err)
+	}
+	return nil
+}
+
+func replayACO(data json.RawMessage, inst *aco.Instance, steps, _ int) (outcome, error) {
+	p := aco.DefaultParams()
+	if err := decodeParams(data, &p); err != nil {
+		return outcome{}, err
+	}
+	if steps > p.MaxIterations {
+		return outcome{}, fmt.Errorf("steps = %d превышает maxIterations = %d", steps, p.MaxIterations)
+	}
+
+	var trace aco.Trace
+	var err error
+	if inst != nil {
+		trace, err = aco.RecordOnInstance(p, inst, steps)
+	} else {
+		trace, err = aco.Record(p, steps)
+	}
+	if err != nil {
+		return outcome{}, err
+	}
+	graph := aco.Graph{Nodes: trace.Nodes, Start: trace.Start, End: trace.End}
+	result := aco.Snapshot{BestLength: aco.Length(math.Inf(1)), CurrentBestLength: aco.Length(math.Inf(1))}
+	if len(trace.Steps) > 0 {
+		result = trace.Steps[len(trace.Steps)-1]
+	}
+	return outcome{params: trace.Params, trace: trace, graph: &graph, result: result}, nil
+}
+
+func replaySDS(data json.RawMessage, inst *aco.Instance, steps, every int) (outcome, error) {
+	if inst != nil {
+		return outcome{}, errNoInstance(sds.Algorithm)
+	}
+	p := sds.DefaultParams()
+	if err := decodeParams(data, &p); err != nil {
+		return outcome{}, err
+	}
+
+	// Итог — последний шаг, даже если он не попал в трассу
+	trace, result, err := sds.RecordFinal(p, steps, snapshotEvery(steps, every))
+	if err != nil {
+		return outcome{}, err
+	}
+
+	objective, _ := sds.LookupObjective(p.Func)
+	info := &Objective{Func: p.Func, Range: objective.Range()}
+	if f, ok := objective.(sds.Function); ok {
+		info.Name = f.Name
+	}
+	return outcome{params: p, trace: trace, objective: info, result: result}, nil
+}
+
+func replayBoids(data json.RawMessage, inst *aco.Instance, steps, every int) (outcome, error) {
+	if inst != nil {
+		return outcome{}, errNoInstance(boids.Algorithm)
+	}
+	p := boids.DefaultParams()
+	if err := decodeParams(data, &p); err != nil {
+		return outcome{}, err
+	}
+
+	trace, result, err := boids.RecordFinal(p, steps, snapshotEvery(steps, every))
+	if err != nil {
+		return outcome{}, err
+	}
+	return outcome{params: p, trace: trace, result: result}, nil
+}
+
+func errNoInstance(algorithm string) error {
+	return fmt.Errorf("граф из файла есть только у aco, а не у %s", algorithm)
+}
+
+// snapshotEvery заменяет нулевой интервал снимков на число шагов: в трассе остается последний шаг
+func snapshotEvery(steps, every int) int {
+	if every == 0 {
+		return max(steps, 1)
+	}
+	return every
+}
@@ -0,0 +1,218 @@
+// Package manifest — манифесты прогонов: алгоритм, версия модели, полные параметры
+// вместе с зерном, граф (для графа из файла — сам файловый граф) или целевая функция
+// и итог последнего шага.
+//
+// По манифесту прогон повторяется без браузера и сверяется с записанным итогом бит в бит.
+// Страницы aco и sds сохраняют манифест кнопкой «Сохранить прогон» в том же формате.
+// Страница boids берет начальное состояние из Math.random, поэтому манифесты стаи
+// записывает только Go
+package manifest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"time"
+
+	"github.com/RiddlerXenon/roi/aco"
+)
+
+// Format — версия формата манифеста
+const Format = 1
+
+// Manifest — описание прогона, достаточное для его повторения
+type Manifest struct {
+	Format    int    `json:"format"`
+	Algorithm string `json:"algorithm"`
+
+	// EngineVersion — версия модели, которой записан прогон, например aco.EngineVersion
+	EngineVersion int `json:"engineVersion"`
+
+	// Source — где записан прогон: go или browser
+	Source  string `json:"source,omitempty"`
+	Created string `json:"created,omitempty"`
+
+	// Params — параметры алгоритма в JSON, включая seed; отсутствующие поля
+	// берутся из параметров страницы по умолчанию
+	Params json.RawMessage `json:"params"`
+
+	// Steps — число выполненных шагов или итераций
+	Steps int `json:"steps"`
+
+	Graph *aco.Graph `json:"graph,omitempty"`
+	// Instance — граф из файла, на котором шел прогон aco: имя, вершины и ребра.
+	// Без него aco строит случайный граф по seed
+	Instance  *aco.Instance `json:"instance,omitempty"`
+	Objective *Objective    `json:"objective,omitempty"`
+
+	// Result — состояние после последнего шага: aco.Snapshot, sds.Snapshot или boids.Snapshot
+	Result json.RawMessage `json:"result,omitempty"`
+
+	// Modified — параметры меняли посреди прогона в браузере. Повтор с начала идет
+	// с последними значениями и с записанным итогом не совпадет
+	Modified bool `json:"modified,omitempty"`
+}
+
+// Objective — целевая функция sds: ключ реестра и полуширина области. Формула в манифест
+// не попадает, поэтому при повторе функция должна быть зарегистрирована под тем же ключом
+type Objective struct {
+	Func  string  `json:"func"`
+	Name  string  `json:"name,omitempty"`
+	Range float64 `json:"range"`
+}
+
+// New выполняет steps шагов алгоритма с параметрами params (aco.Params, sds.Params,
+// boids.Params или их часть в JSON) и записывает манифест вместе с полными параметрами,
+// графом, целевой функцией и итогом
+func New(algorithm string, params any, steps int) (Manifest, error) {
+	return NewOnInstance(algorithm, params, nil, steps)
+}
+
+// NewOnInstance записывает манифест прогона aco на графе из файла; при inst = nil
+// работает как New
+func NewOnInstance(algorithm string, params any, inst *aco.Instance, steps int) (Manifest, error) {
+	a, ok := algorithms[algorithm]
+	if !ok {
+		return Manifest{}, unknownAlgorithm(algorithm)
+	}
+	data, err := json.Marshal(params)
+	if err != nil {
+		return Manifest{}, fmt.Errorf("параметры: %w", err)
+	}
+
+	out, err := a.replay(data, inst, steps, 0)
+	if err != nil {
+		return Manifest{}, err
+	}
+	full, err := json.Marshal(out.params)
+	if err != nil {
+		return Manifest{}, fmt.Errorf("параметры: %w", err)
+	}
+	result, err := json.Marshal(out.result)
+	if err != nil {
+		return Manifest{}, fmt.Errorf("итог прогона: %w", err)
+	}
+	return Manifest{
+		Format:        Format,
+		Algorithm:     algorithm,
+		EngineVersion: a.engineVersion,
+		Source:        "go",
+		Created:       time.Now().UTC().Format(time.RFC3339),
+		Params:        full,
+		Steps:         steps,
+		Graph:         out.graph,
+		Instance:      inst,
+		Objective:     out.objective,
+		Result:        result,
+	}, nil
+}
+
+// Load читает манифест из JSON файла
+func Load(path string) (Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Manifest{}, fmt.Errorf("чтение манифеста: %w", err)
+	}
+
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return Manifest{}, fmt.Errorf("разбор манифеста %s: %w", path, err)
+	}
+	if m.Format < 1 || m.Format > Format {
+		return Manifest{}, fmt.Errorf("манифест %s: формат %d не поддерживается, ожидается 1..%d", path, m.Format, Format)
+	}
+	return m, nil
+}
+
+// Save записывает манифест в JSON файл с отступами, чтобы его было удобно читать и сравнивать
+func (m Manifest) Save(path string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// Replay повторяет прогон и сверяет граф, целевую функцию и итог с записанными в манифесте.
+// every — интервал снимков трассы sds и boids, 0 — только последний шаг; трасса aco
+// содержит все итерации. Возвращает aco.Trace, sds.Trace или boids.Trace
+func (m Manifest) Replay(every int) (any, error) {
+	a, ok := algorithms[m.Algorithm]
+	if !ok {
+		return nil, unknownAlgorithm(m.Algorithm)
+	}
+	switch {
+	case m.EngineVersion != a.engineVersion:
+		return nil, fmt.Errorf("прогон записан версией модели %s %d, текущая версия %d",
+			m.Algorithm, m.EngineVersion, a.engineVersion)
+	case m.Steps < 0:
+		return nil, fmt.Errorf("steps = %d: число шагов не может быть отрицательным", m.Steps)
+	case every < 0:
+		return nil, fmt.Errorf("every = %d: интервал снимков не может быть отрицательным", every)
+	}
+
+	out, err := a.replay(m.Params, m.Instance, m.Steps, every)
+	if err != nil {
+		return nil, err
+	}
+
+	if m.Graph != nil {
+		if err := sameJSON("граф", out.graph, m.Graph); err != nil {
+			return out.trace, err
+		}
+	}
+	if m.Objective != nil && out.objective == nil {
+		return out.trace, fmt.Errorf("у алгоритма %s нет целевой функции", m.Algorithm)
+	}
+	if m.Objective != nil && (out.objective.Func != m.Objective.Func || out.objective.Range != m.Objective.Range) {
+		return out.trace, fmt.Errorf("целевая функция %s на [-%v, %v]², записана %s на [-%v, %v]²",
+			out.objective.Func, out.objective.Range, out.objective.Range,
+			m.Objective.Func, m.Objective.Range, m.Objective.Range)
+	}
+	if m.Result != nil {
+		// Записанный итог разбирается в тот же тип, что и повтор, чтобы сравнение
+		// не зависело от того, как браузер записывает числа
+		want := reflect.New(reflect.TypeOf(out.result))
+		if err := json.Unmarshal(m.Result, want.Interface()); err != nil {
+			return out.trace, fmt.Errorf("разбор итога: %w", err)
+		}
+		if err := sameJSON("итог", out.result, want.Elem().Interface()); err != nil {
+			return out.trace, err
+		}
+	}
+	return out.trace, nil
+}
+
+// sameJSON сравнивает значения по их записи в JSON. Go записывает float64 кратчайшей
+// строкой, которая читается обратно в то же число, поэтому сравнение точное
+func sameJSON(what string, got, want any) error {
+	g, err := json.Marshal(got)
+	if err != nil {
+		return err
+	}
+	w, err := json.Marshal(want)
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(g, w) {
+		return nil
+	}
+
+	i := 0
+	for i < len(g) && i < len(w) && g[i] == w[i] {
+		i++
+	}
+	from := max(0, i-40)
+	return fmt.Errorf("%s повтора расходится с записанным с позиции %d: получено …%s…, ожидалось …%s…",
+		what, i, excerpt(g, from), excerpt(w, from))
+}
+
+func excerpt(data []byte, from int) string {
+	return string(data[min(from, len(data)):min(from+100, len(data))])
+}
+
+func unknownAlgorithm(name string) error {
+	return fmt.Errorf("неизвестный алгоритм %q, доступны: %v", name, Algorithms())
+}
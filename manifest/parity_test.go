@@ -0,0 +1,40 @@
+package manifest
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// verifyFiles сверяет все файлы по шаблонам, каждый в своем подтесте
+func verifyFiles(t *testing.T, patterns ...string) {
+	t.Helper()
+	var paths []string
+	for _, pattern := range patterns {
+		found, err := filepath.Glob(pattern)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(found) == 0 {
+			t.Fatalf("по шаблону %s нет файлов", pattern)
+		}
+		paths = append(paths, found...)
+	}
+	for _, path := range paths {
+		t.Run(filepath.ToSlash(path), func(t *testing.T) {
+			if _, err := Verify(path); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// TestParity повторяет каждую трассу браузера и сверяет снимки бит в бит
+func TestParity(t *testing.T) {
+	verifyFiles(t, filepath.Join("..", "aco", "testdata", "*.json"), filepath.Join("..", "sds", "testdata", "*.json"))
+}
+
+// TestReplay повторяет каждый манифест из testdata и сверяет итог
+func TestReplay(t *testing.T) {
+	verifyFiles(t, filepath.Join("..", "aco", "testdata", "manifests", "*.json"),
+		filepath.Join("..", "sds", "testdata", "manifests", "*.json"))
+}
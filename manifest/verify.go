@@ -0,0 +1,70 @@
+package manifest
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/RiddlerXenon/roi/aco"
+	"github.com/RiddlerXenon/roi/sds"
+)
+
+// traceVerifiers сопоставляют полю algorithm трассы браузера проверку;
+// возвращается число сверенных снимков
+var traceVerifiers = map[string]func(path string) (int, error){
+	aco.Algorithm: func(path string) (int, error) {
+		trace, err := aco.LoadTrace(path)
+		if err != nil {
+			return 0, err
+		}
+		return len(trace.Steps), trace.Verify()
+	},
+	sds.Algorithm: func(path string) (int, error) {
+		trace, err := sds.LoadTrace(path)
+		if err != nil {
+			return 0, err
+		}
+		return len(trace.Steps), trace.Verify()
+	},
+}
+
+// Verify сверяет файл из testdata и возвращает краткий отчет. Файл с полем format —
+// манифест, он повторяется и сверяется с записанным итогом; иначе это трасса браузера,
+// алгоритм которой определяется по полю algorithm
+func Verify(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	var header struct {
+		Algorithm string `json:"algorithm"`
+		Format    int    `json:"format"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return "", fmt.Errorf("разбор трассы: %w", err)
+	}
+
+	if header.Format != 0 {
+		m, err := Load(path)
+		if err != nil {
+			return "", err
+		}
+		if m.Result == nil {
+			return "", fmt.Errorf("в манифесте нет итога для сверки")
+		}
+		if _, err := m.Replay(0); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("манифест повторен, итог после %d шагов совпадает", m.Steps), nil
+	}
+
+	verify, ok := traceVerifiers[header.Algorithm]
+	if !ok {
+		return "", fmt.Errorf("неизвестный алгоритм трассы %q", header.Algorithm)
+	}
+	steps, err := verify(path)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d снимков совпадают", steps), nil
+}
@@ -0,0 +1,111 @@
+package sds
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/RiddlerXenon/roi/internal/jsmath"
+)
+
+// Objective — максимизируемая функция на квадрате [-R, R]²
+type Objective interface {
+	// Value возвращает значение функции в точке; агенты сравнивают значения между собой
+	Value(x, y float64) float64
+
+	// Range возвращает полуширину R области поиска
+	Range() float64
+}
+
+// Function — целевая функция, заданная формулой
+type Function struct {
+	Name string
+	R    float64
+	F    func(x, y float64) float64
+
+	// Hint — положение глобального максимума
+	Hint Point
+}
+
+func (f Function) Value(x, y float64) float64 { return f.F(x, y) }
+func (f Function) Range() float64             { return f.R }
+
+// Реестр заполняется при запуске программы и во время прогонов не меняется
+var objectiveRegistry = make(map[string]Objective)
+
+func init() {
+	RegisterObjective("twopeaks", twoPeaks)
+	RegisterObjective("sphere", sphere)
+	RegisterObjective("rastrigin", rastrigin)
+	RegisterObjective("ackley", ackley)
+}
+
+// RegisterObjective регистрирует целевую функцию под ключом key, который указывается
+// в Params.Func. Регистрация допустима только до начала прогонов
+func RegisterObjective(key string, objective Objective) {
+	if key == "" || !(objective.Range() > 0) {
+		panic(fmt.Sprintf("RegisterObjective: недопустимый ключ %q или полуширина области %v", key, objective.Range()))
+	}
+	objectiveRegistry[key] = objective
+}
+
+// LookupObjective возвращает целевую функцию по ключу
+func LookupObjective(key string) (Objective, bool) {
+	objective, ok := objectiveRegistry[key]
+	return objective, ok
+}
+
+// Objectives возвращает отсортированные ключи зарегистрированных функций
+func Objectives() []string {
+	keys := make([]string, 0, len(objectiveRegistry))
+	for key := range objectiveRegistry {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Встроенные функции из таблицы Objectives в sds.js. Значения нормированы примерно к [0, 1];
+// Pow, Exp и Cos из jsmath, чтобы сравнения агентов совпадали с браузером
+var (
+	twoPeaks = Function{
+		Name: "Две горки (глобумакс справа)",
+		R:    2.5,
+		F: func(x, y float64) float64 {
+			g1 := jsmath.Exp(-(jsmath.Pow(x-1.0, 2)/0.15 + jsmath.Pow(y-0.2, 2)/0.25))
+			g2 := float64(0.6 * jsmath.Exp(-(jsmath.Pow(x+1.0, 2)/1.0 + jsmath.Pow(y+0.3, 2)/0.8)))
+			return g1 + g2
+		},
+		Hint: Point{1.0, 0.2},
+	}
+
+	sphere = Function{
+		Name: "Сфера (вогнутая) — максимум в 0",
+		R:    2.5,
+		F: func(x, y float64) float64 {
+			return max(0, 1-(float64(x*x)+float64(y*y))/(2.5*2.5))
+		},
+	}
+
+	rastrigin = Function{
+		Name: "Растригин (инвертированный)",
+		R:    2.5,
+		F: func(x, y float64) float64 {
+			const a = 10
+			val := 2*a + (float64(x*x) - float64(a*jsmath.Cos(2*math.Pi*x))) + (float64(y*y) - float64(a*jsmath.Cos(2*math.Pi*y)))
+			return max(0, 1-val/40)
+		},
+	}
+
+	ackley = Function{
+		Name: "Акли (инвертированный)",
+		R:    2.5,
+		F: func(x, y float64) float64 {
+			const a, b, c = 20, 0.2, 2 * math.Pi
+			s1 := float64(0.5 * (float64(x*x) + float64(y*y)))
+			s2 := float64(0.5 * (jsmath.Cos(c*x) + jsmath.Cos(c*y)))
+			ack := float64(-a*jsmath.Exp(float64(-b*math.Sqrt(s1)))) - jsmath.Exp(s2) + a + math.E
+			return max(0, 1-ack/8)
+		},
+	}
+)
@@ -0,0 +1,40 @@
+package sds
+
+import (
+	"math"
+
+	"github.com/RiddlerXenon/roi/internal/jsmath"
+)
+
+// defaultSeed подставляется вместо нулевого зерна, как в sds.js
+const defaultSeed = 123456789
+
+// RNG — генератор Mulberry32 из sds.js. Счетчик хранится в float64, как число в JavaScript:
+// после 2**53 он теряет младшие разряды так же, как в браузере
+type RNG struct {
+	s float64
+}
+
+// NewRNG создает генератор; нулевое зерно заменяется на 123456789
+func NewRNG(seed uint32) *RNG {
+	if seed == 0 {
+		seed = defaultSeed
+	}
+	return &RNG{s: float64(seed)}
+}
+
+// Float64 возвращает следующее число из [0, 1)
+func (r *RNG) Float64() float64 {
+	r.s += 0x6D2B79F5
+	t := uint32(math.Mod(r.s, 1<<32))
+	t = (t ^ t>>15) * (t | 1)
+	t ^= t + (t^t>>7)*(t|61)
+	return float64(t^t>>14) / (1 << 32)
+}
+
+// NormFloat64 возвращает нормально распределенное число по формуле Бокса — Мюллера
+func (r *RNG) NormFloat64() float64 {
+	u := 1 - r.Float64()
+	v := 1 - r.Float64()
+	return math.Sqrt(float64(-2*jsmath.Log(u))) * jsmath.Cos(2*math.Pi*v)
+}
@@ -0,0 +1,238 @@
+// Package sds — стохастический диффузионный поиск без браузера.
+//
+// Шаг повторяет step из static/js/sds.js: каждый агент сравнивает значение целевой функции
+// в своей точке со значением в случайной точке (тест), проигравшие копируют гипотезу
+// случайного победителя или, если победителей нет, с вероятностью restartProb начинают
+// заново (диффузия), затем все гипотезы смещаются нормальным шумом σ, при отжиге
+// σ_t = σ·annealDecay^t, и обрезаются квадратом [-R, R]² (разведка). С тем же зерном Swarm
+// проходит те же положения агентов, что и страница в браузере на V8
+package sds
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/RiddlerXenon/roi/internal/jsmath"
+)
+
+// EngineVersion — версия модели в манифестах прогонов; растет, если с тем же зерном
+// агенты начинают проходить другие положения
+const EngineVersion = 1
+
+// Params — параметры поиска; имена JSON совпадают с полями params в sds.js
+type Params struct {
+	N           int     `json:"N"`
+	Seed        uint32  `json:"seed"`
+	Func        string  `json:"func"`
+	Sigma       float64 `json:"sigma"`
+	Anneal      bool    `json:"anneal"`
+	AnnealDecay float64 `json:"annealDecay"`
+	RestartProb float64 `json:"restartProb"`
+
+	// TrackCount — число агентов, за траекториями которых следит страница. Выбор этих агентов
+	// расходует числа генератора, поэтому без него последовательности с браузером не совпадут
+	TrackCount int `json:"trackCount"`
+}
+
+// DefaultParams возвращает параметры страницы templates/sds.html
+func DefaultParams() Params {
+	return Params{
+		N:           300,
+		Seed:        defaultSeed,
+		Func:        "twopeaks",
+		Sigma:       0.05,
+		Anneal:      false,
+		AnnealDecay: 0.999,
+		RestartProb: 0.5,
+		TrackCount:  12,
+	}
+}
+
+// Validate проверяет параметры и наличие целевой функции в реестре
+func (p Params) Validate() error {
+	switch {
+	case p.N < 1:
+		return fmt.Errorf("N = %d: нужен хотя бы один агент", p.N)
+	case !(p.Sigma >= 0):
+		return fmt.Errorf("sigma = %v: дисперсия разведки не может быть отрицательной", p.Sigma)
+	case p.Anneal && !(p.AnnealDecay > 0 && p.AnnealDecay <= 1):
+		return fmt.Errorf("annealDecay = %v: множитель затухания должен лежать в (0, 1]", p.AnnealDecay)
+	case !(p.RestartProb >= 0 && p.RestartProb <= 1):
+		return fmt.Errorf("restartProb = %v: вероятность должна лежать в [0, 1]", p.RestartProb)
+	case p.TrackCount < 0:
+		return fmt.Errorf("trackCount = %d: число траекторий не может быть отрицательным", p.TrackCount)
+	}
+	if _, ok := LookupObjective(p.Func); !ok {
+		return fmt.Errorf("func = %q: неизвестная целевая функция, доступны %v", p.Func, Objectives())
+	}
+	return nil
+}
+
+// Point — точка на плоскости
+type Point struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// Agent — гипотеза агента и исход его последнего теста
+type Agent struct {
+	Point
+	Success bool `json:"success"`
+}
+
+// Metrics — показатели последнего шага, как getMetrics в sds.js
+type Metrics struct {
+	T            int     `json:"t"`
+	BestF        float64 `json:"bestF"`
+	MeanF        float64 `json:"meanF"`
+	WinnersFrac  float64 `json:"winnersFrac"`
+	WinnersCount int     `json:"winnersCount"`
+}
+
+// Swarm — состояние поиска
+type Swarm struct {
+	params    Params
+	objective Objective
+	rng       *RNG
+
+	agents  []Agent
+	winners []int
+	tracked []int
+	t       int
+
+	bestF, meanF float64
+}
+
+// New создает агентов со случайными гипотезами и выбирает отслеживаемых, как initAgents в sds.js
+func New(p Params) (*Swarm, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+
+	objective, _ := LookupObjective(p.Func)
+	s := &Swarm{
+		params:    p,
+		objective: objective,
+		rng:       NewRNG(p.Seed),
+		agents:    make([]Agent, p.N),
+	}
+	for i := range s.agents {
+		s.agents[i].X = s.randInRange()
+		s.agents[i].Y = s.randInRange()
+	}
+
+	// Отслеживаемые агенты в порядке первого выпадения, как значения Set в JavaScript
+	seen := make(map[int]bool)
+	for len(s.tracked) < min(p.TrackCount, p.N) {
+		i := int(s.rng.Float64() * float64(p.N))
+		if !seen[i] {
+			seen[i] = true
+			s.tracked = append(s.tracked, i)
+		}
+	}
+	return s, nil
+}
+
+func (s *Swarm) randInRange() float64 {
+	return (s.rng.Float64()*2 - 1) * s.objective.Range()
+}
+
+// Step выполняет один цикл тест — диффузия — разведка
+func (s *Swarm) Step() {
+	n := len(s.agents)
+	s.winners = s.winners[:0]
+	sum := 0.0
+	s.bestF = math.Inf(-1)
+
+	// Тест: сравнение с гипотезой в случайной точке
+	for i := range s.agents {
+		a := &s.agents[i]
+		xr := s.randInRange()
+		yr := s.randInRange()
+		fi := s.objective.Value(a.X, a.Y)
+		fr := s.objective.Value(xr, yr)
+		a.Success = fi >= fr
+		if a.Success {
+			s.winners = append(s.winners, i)
+		}
+		if fi > s.bestF {
+			s.bestF = fi
+		}
+		sum += fi
+	}
+
+	// Диффузия
+	if len(s.winners) == 0 {
+		for i := range s.agents {
+			if s.rng.Float64() < s.params.RestartProb {
+				s.agents[i].X = s.randInRange()
+				s.agents[i].Y = s.randInRange()
+			}
+		}
+	} else {
+		for i := range s.agents {
+			if !s.agents[i].Success {
+				j := s.winners[int(float64(len(s.winners))*s.rng.Float64())]
+				s.agents[i].Point = s.agents[j].Point
+			}
+		}
+	}
+
+	// Разведка
+	sigma := s.params.Sigma
+	if s.params.Anneal {
+		sigma = s.params.Sigma * jsmath.Pow(s.params.AnnealDecay, float64(s.t))
+	}
+	r := s.objective.Range()
+	for i := range s.agents {
+		a := &s.agents[i]
+		a.X = min(r, max(-r, a.X+float64(s.rng.NormFloat64()*sigma)))
+		a.Y = min(r, max(-r, a.Y+float64(s.rng.NormFloat64()*sigma)))
+	}
+
+	s.meanF = sum / float64(n)
+	s.t++
+}
+
+// Run выполняет steps шагов
+func (s *Swarm) Run(steps int) {
+	for range steps {
+		s.Step()
+	}
+}
+
+// Params возвращает параметры поиска
+func (s *Swarm) Params() Params { return s.params }
+
+// SetParams меняет sigma, режим отжига и restartProb между шагами, как ползунки страницы.
+// Число агентов, зерно, целевая функция и trackCount определяют начальное состояние
+// и меняются только вместе с новым роем
+func (s *Swarm) SetParams(p Params) error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	old := s.params
+	if p.N != old.N || p.Seed != old.Seed || p.Func != old.Func || p.TrackCount != old.TrackCount {
+		return errors.New("N, seed, func и trackCount задают начальное состояние: их смена требует нового роя")
+	}
+	s.params = p
+	return nil
+}
+
+// Agents возвращает текущие гипотезы агентов; срез нельзя изменять
+func (s *Swarm) Agents() []Agent { return s.agents }
+
+// Tracked возвращает номера агентов, траектории которых рисует страница
+func (s *Swarm) Tracked() []int { return s.tracked }
+
+// Metrics возвращает показатели последнего шага
+func (s *Swarm) Metrics() Metrics {
+	m := Metrics{T: s.t, WinnersCount: len(s.winners)}
+	if s.t > 0 {
+		m.BestF = s.bestF
+		m.MeanF = s.meanF
+		m.WinnersFrac = float64(len(s.winners)) / float64(len(s.agents))
+	}
+	return m
+}
@@ -0,0 +1,92 @@
+// Записывает трассы static/js/sds.js для проверки sds.Trace.Verify и манифесты,
+// сохраненные страницей, для проверки manifest.Manifest.Replay.
+//
+//   node sds/testdata/record.mjs [каталог]
+//
+// Скрипт подключает модуль страницы без изменений в логике: к возвращаемому объекту
+// добавляются только step и snapshot. Холст и DOM заменены заглушками; stepsPerFrame = 0,
+// чтобы запуск анимации при создании не делал лишних шагов
+import { mkdirSync, readFileSync, writeFileSync } from 'node:fs';
+import { dirname, join } from 'node:path';
+import { fileURLToPath, pathToFileURL } from 'node:url';
+
+const here = dirname(fileURLToPath(import.meta.url));
+const outDir = process.argv[2] ?? here;
+
+const source = readFileSync(join(here, '../../static/js/sds.js'), 'utf8');
+const marker = /return \{\s*\n\s*params,\s*\n\s*updateParams,\s*\n\s*updateParam,\s*\n\s*createUI,/;
+if (!marker.test(source)) {
+  throw new Error('sds.js: не найден объект, который возвращает initSDS');
+}
+// Модуль загружается из data: URL, поэтому относительные импорты (stream.js) заменяются абсолютными
+const jsDir = pathToFileURL(join(here, '../../static/js/')).href;
+const instrumented = source.replace(/from '\.\/([^']+)'/g, (_, file) => `from '${jsDir}${file}'`).replace(marker, (match) => `return {
+    step,
+    snapshot: () => ({
+      tracked: tracksIdx.slice(),
+      agents: agents.map(({ x, y }) => [x, y]),
+    }),` + match.slice('return {'.length));
+
+const ctx = new Proxy({}, {
+  get: (target, key) => (key in target ? target[key] : () => ctx),
+  set: (target, key, value) => { target[key] = value; return true; },
+});
+const canvasStub = () => ({ clientWidth: 800, clientHeight: 800, width: 0, height: 0, getContext: () => ctx });
+
+globalThis.document = { getElementById: () => null, createElement: canvasStub };
+globalThis.window = { addEventListener() {}, removeEventListener() {} };
+globalThis.requestAnimationFrame = () => 0;
+globalThis.cancelAnimationFrame = () => {};
+
+const { initSDS } = await import('data:text/javascript;base64,' + Buffer.from(instrumented).toString('base64'));
+
+// Параметры страницы templates/sds.html
+const pageDefaults = {
+  N: 300, seed: 123456789, func: 'twopeaks', sigma: 0.05, anneal: false, annealDecay: 0.999,
+  restartProb: 0.5, trackCount: 12,
+};
+
+// steps — число шагов, every — интервал снимков
+const scenarios = {
+  defaults: { params: { ...pageDefaults, N: 40 }, steps: 60, every: 1 },
+  sphere_anneal: { params: { ...pageDefaults, N: 30, func: 'sphere', anneal: true, annealDecay: 0.95, sigma: 0.2, seed: 42 }, steps: 60, every: 1 },
+  rastrigin_restart: { params: { ...pageDefaults, N: 25, func: 'rastrigin', restartProb: 1, sigma: 0.01, seed: 7 }, steps: 60, every: 1 },
+  ackley_zero_seed: { params: { ...pageDefaults, N: 20, func: 'ackley', seed: 0, trackCount: 30 }, steps: 60, every: 1 },
+  // Счетчик генератора превышает 2**53 примерно через 3600 шагов
+  long: { params: { ...pageDefaults, N: 200, sigma: 0.1 }, steps: 4500, every: 500 },
+};
+
+for (const [name, { params, steps, every }] of Object.entries(scenarios)) {
+  const swarm = initSDS(canvasStub(), { ...params, isPreview: true, stepsPerFrame: 0 });
+  const { tracked } = swarm.snapshot();
+
+  const snapshots = [];
+  for (let t = 1; t <= steps; t++) {
+    swarm.step();
+    if (t % every !== 0) continue;
+    const { t: step, bestF, meanF, winnersFrac, winnersCount } = swarm.getMetrics();
+    snapshots.push({ t: step, bestF, meanF, winnersFrac, winnersCount, agents: swarm.snapshot().agents });
+  }
+
+  const trace = { algorithm: 'sds', params, every, tracked, steps: snapshots };
+  writeFileSync(join(outDir, `${name}.json`), JSON.stringify(trace) + '\n');
+  console.log(`${name}: ${steps} шагов, bestF ${snapshots.at(-1).bestF}`);
+}
+
+// Манифесты страницы. trackCount меняется уже после выбора траекторий: в манифест должно
+// попасть значение, с которым генератор выбирал агентов
+mkdirSync(join(outDir, 'manifests'), { recursive: true });
+const manifestScenarios = {
+  defaults: { params: { ...pageDefaults, N: 50 }, steps: 137 },
+  ackley_tracks: { params: { ...pageDefaults, N: 40, func: 'ackley', seed: 2024, anneal: true, annealDecay: 0.99 }, steps: 90 },
+};
+for (const [name, { params, steps }] of Object.entries(manifestScenarios)) {
+  const swarm = initSDS(canvasStub(), { ...params, isPreview: true, stepsPerFrame: 0 });
+  swarm.updateParam('trackCount', 3);
+  for (let t = 1; t <= steps; t++) swarm.step();
+
+  // Время сохранения не пишется, чтобы повторная запись не меняла файлы
+  const { created, ...manifest } = swarm.exportManifest();
+  writeFileSync(join(outDir, 'manifests', `${name}.json`), JSON.stringify(manifest, null, 2) + '\n');
+  console.log(`manifests/${name}: ${manifest.steps} шагов, bestF ${manifest.result.bestF}`);
+}
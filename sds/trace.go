@@ -0,0 +1,107 @@
+package sds
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+)
+
+// Algorithm — имя алгоритма в трассах
+const Algorithm = "sds"
+
+// Snapshot — положения агентов и показатели после шага T
+type Snapshot struct {
+	Metrics
+	Agents [][2]float64 `json:"agents"`
+}
+
+// Trace — запись прогона: снимки после каждого Every-го шага.
+// Трассы браузера записывает sds/testdata/record.mjs
+type Trace struct {
+	Algorithm string     `json:"algorithm"`
+	Params    Params     `json:"params"`
+	Every     int        `json:"every"`
+	Tracked   []int      `json:"tracked"`
+	Steps     []Snapshot `json:"steps"`
+}
+
+// Record выполняет steps шагов с параметрами p и сохраняет снимок после каждого every-го
+func Record(p Params, steps, every int) (Trace, error) {
+	trace, _, err := RecordFinal(p, steps, every)
+	return trace, err
+}
+
+// RecordFinal работает как Record и возвращает еще снимок после последнего шага,
+// даже если он не попал в трассу
+func RecordFinal(p Params, steps, every int) (Trace, Snapshot, error) {
+	if every < 1 {
+		return Trace{}, Snapshot{}, fmt.Errorf("every = %d: интервал снимков должен быть положительным", every)
+	}
+	s, err := New(p)
+	if err != nil {
+		return Trace{}, Snapshot{}, err
+	}
+
+	trace := Trace{Algorithm: Algorithm, Params: p, Every: every, Tracked: slices.Clone(s.tracked)}
+	for step := 1; step <= steps; step++ {
+		s.Step()
+		if step%every == 0 {
+			trace.Steps = append(trace.Steps, s.snapshot())
+		}
+	}
+	return trace, s.snapshot(), nil
+}
+
+func (s *Swarm) snapshot() Snapshot {
+	snapshot := Snapshot{Metrics: s.Metrics(), Agents: make([][2]float64, len(s.agents))}
+	for i, a := range s.agents {
+		snapshot.Agents[i] = [2]float64{a.X, a.Y}
+	}
+	return snapshot
+}
+
+// LoadTrace читает трассу из JSON файла
+func LoadTrace(path string) (Trace, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Trace{}, fmt.Errorf("чтение трассы: %w", err)
+	}
+
+	var trace Trace
+	if err := json.Unmarshal(data, &trace); err != nil {
+		return Trace{}, fmt.Errorf("разбор трассы %s: %w", path, err)
+	}
+	if trace.Algorithm != Algorithm {
+		return Trace{}, fmt.Errorf("трасса %s записана для %q, а не для %q", path, trace.Algorithm, Algorithm)
+	}
+	return trace, nil
+}
+
+// Verify повторяет прогон с параметрами трассы и возвращает первое расхождение.
+// Числа сравниваются точно, без допуска
+func (t Trace) Verify() error {
+	got, err := Record(t.Params, len(t.Steps)*t.Every, t.Every)
+	if err != nil {
+		return err
+	}
+
+	if !slices.Equal(got.Tracked, t.Tracked) {
+		return fmt.Errorf("отслеживаемые агенты %v, ожидались %v", got.Tracked, t.Tracked)
+	}
+	for i, want := range t.Steps {
+		step := got.Steps[i]
+		if step.Metrics != want.Metrics {
+			return fmt.Errorf("шаг %d: показатели %+v, ожидались %+v", want.T, step.Metrics, want.Metrics)
+		}
+		if len(step.Agents) != len(want.Agents) {
+			return fmt.Errorf("шаг %d: %d агентов, ожидалось %d", want.T, len(step.Agents), len(want.Agents))
+		}
+		for j := range want.Agents {
+			if step.Agents[j] != want.Agents[j] {
+				return fmt.Errorf("шаг %d, агент %d: %v, ожидалось %v", want.T, j, step.Agents[j], want.Agents[j])
+			}
+		}
+	}
+	return nil
+}
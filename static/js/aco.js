@@ -35,6 +35,7 @@ export function initAnts(canvas, options = {}) {
     tau0: options.tau0 ?? 1.0,                // начальная концентрация феромона (> 0)
     graphType: options.graphType ?? 'undirected', // тип графа
     startDist: options.startDist ?? 'uniform', // распределение стартовых вершин
+    objective: options.objective ?? 'path',   // 'path' — путь до финиша | 'tour' — замкнутый обход
     seed: options.seed ?? 42,                 // инициализация ГПСЧ
     
     // Технические параметры
@@ -56,6 +57,14 @@ export function initAnts(canvas, options = {}) {
   let iteration = 0;
   let prng = new PRNG(params.seed);
 
+  // Граф в момент построения. При изменении размера окна вершины масштабируются, а расстояния
+  // и генератор остаются прежними, поэтому манифест прогона хранит исходный граф
+  let builtGraph = null;
+  // Параметры шага меняли посреди прогона: повтор с начала с последними значениями не совпадет
+  let modified = false;
+  // Граф из файла (ответ GET /api/aco/graphs/{name}) или null для случайного графа
+  let loaded = null;
+
   // Предрендеренные LaTeX формулы
   let tooltipElements = {};
 
@@ -71,6 +80,12 @@ export function initAnts(canvas, options = {}) {
   function generateGraph() {
     // Инициализируем PRNG
     prng = new PRNG(params.seed);
+
+    if (loaded) {
+      placeLoadedGraph();
+      resetResults();
+      return;
+    }
     
     nodes = [];
     const margin = 50;
@@ -115,12 +130,53 @@ export function initAnts(canvas, options = {}) {
       }
     }
 
-    // Сброс результатов
+    resetResults();
+  }
+
+  // Граф из файла: вершины вписываются в холст с отступом, сохраняя пропорции (ось y в файле
+  // направлена вверх), как fitCanvas в пакете aco; длины ребер — веса из файла,
+  // пары без ребра получают бесконечное расстояние, и муравьи по ним не ходят
+  function placeLoadedGraph() {
+    const margin = 50;
+    const n = loaded.nodes.length;
+    const xs = loaded.nodes.map(p => p.x);
+    const ys = loaded.nodes.map(p => p.y);
+    const minX = Math.min(...xs), maxX = Math.max(...xs);
+    const minY = Math.min(...ys), maxY = Math.max(...ys);
+    const scale = Math.min(
+      (canvas.width - 2 * margin) / (maxX - minX || 1),
+      (canvas.height - 2 * margin) / (maxY - minY || 1)
+    );
+    const offsetX = margin + (canvas.width - 2 * margin - (maxX - minX) * scale) / 2;
+    const offsetY = margin + (canvas.height - 2 * margin - (maxY - minY) * scale) / 2;
+    nodes = loaded.nodes.map(p => ({
+      x: offsetX + (p.x - minX) * scale,
+      y: canvas.height - offsetY - (p.y - minY) * scale
+    }));
+
+    startNode = loaded.start;
+    endNode = loaded.end;
+    distances = Array.from({ length: n }, (_, i) => Array.from({ length: n }, (_, j) => i === j ? 0 : Infinity));
+    pheromones = Array.from({ length: n }, () => Array(n).fill(params.tau0));
+    for (const [from, to, weight] of loaded.edges) {
+      distances[from][to] = Math.min(distances[from][to], weight);
+      if (!loaded.directed) distances[to][from] = Math.min(distances[to][from], weight);
+    }
+  }
+
+  // Сброс результатов
+  function resetResults() {
     bestPath = null;
     bestLength = Infinity;
     currentBestPath = null;
     currentBestLength = Infinity;
     iteration = 0;
+    modified = false;
+    builtGraph = {
+      width: canvas.width,
+      height: canvas.height,
+      nodes: nodes.map(({ x, y }) => ({ x, y }))
+    };
   }
 
   function drawArrow(fromX, fromY, toX, toY, color, lineWidth) {
@@ -197,13 +253,14 @@ export function initAnts(canvas, options = {}) {
     // Рисуем рёбра в зависимости от режима визуализации
     if (params.visualizationMode === 'pheromones') {
       // Визуализация феромонов
-      const maxPheromone = Math.max(...pheromones.flat());
-      const minPheromone = Math.min(...pheromones.flat());
+      const edgePheromones = pheromones.flatMap((row, i) => row.filter((_, j) => hasEdge(i, j)));
+      const maxPheromone = Math.max(...edgePheromones);
+      const minPheromone = Math.min(...edgePheromones);
       const pheromoneRange = maxPheromone - minPheromone;
 
       for (let i = 0; i < params.nodeCount; i++) {
         for (let j = 0; j < params.nodeCount; j++) {
-          if (i !== j) {
+          if (hasEdge(i, j)) {
             const pheromone = pheromones[i][j];
             const intensity = pheromoneRange > 0 ? 
               (pheromone - minPheromone) / pheromoneRange : 0.1;
@@ -226,13 +283,14 @@ export function initAnts(canvas, options = {}) {
       }
     } else if (params.visualizationMode === 'heuristic') {
       // Визуализация эвристики (обратно пропорциональна расстоянию)
-      const maxDistance = Math.max(...distances.flat());
-      const minDistance = Math.min(...distances.flat().filter(d => d > 0));
+      const finiteDistances = distances.flat().filter(d => d > 0 && isFinite(d));
+      const maxDistance = Math.max(...finiteDistances);
+      const minDistance = Math.min(...finiteDistances);
       const distanceRange = maxDistance - minDistance;
 
       for (let i = 0; i < params.nodeCount; i++) {
         for (let j = 0; j < params.nodeCount; j++) {
-          if (i !== j) {
+          if (hasEdge(i, j)) {
             const distance = distances[i][j];
             const heuristic = 1 / distance; // η = 1/d
             const maxHeuristic = 1 / minDistance;
@@ -262,7 +320,7 @@ export function initAnts(canvas, options = {}) {
       // Только слабые связи для контекста
       for (let i = 0; i < params.nodeCount; i++) {
         for (let j = 0; j < params.nodeCount; j++) {
-          if (i !== j) {
+          if (hasEdge(i, j)) {
             const color = "rgba(255,255,255,0.05)";
             const lineWidth = 1;
             
@@ -325,8 +383,10 @@ export function initAnts(canvas, options = {}) {
     // Подпись "СТАРТ"
     ctx.fillText("СТАРТ", nodes[startNode].x, nodes[startNode].y - 15);
     
-    // Подпись "ФИНИШ"
-    ctx.fillText("ФИНИШ", nodes[endNode].x, nodes[endNode].y - 15);
+    // Подпись "ФИНИШ"; у тура финиша нет
+    if (params.objective !== 'tour') {
+      ctx.fillText("ФИНИШ", nodes[endNode].x, nodes[endNode].y - 15);
+    }
   }
 
   function constructPath(fromNode) {
@@ -334,14 +394,15 @@ export function initAnts(canvas, options = {}) {
     const visited = new Set([fromNode]);
     let current = fromNode;
 
-    // Строим путь от начального узла к конечному
-    while (current !== endNode && visited.size < params.nodeCount) {
+    // Строим путь от начального узла к конечному, а тур — через все узлы
+    const tour = params.objective === 'tour';
+    while ((tour || current !== endNode) && visited.size < params.nodeCount) {
       const probabilities = [];
       let totalProbability = 0;
 
-      // Вычисляем вероятности перехода к непосещённым узлам
+      // Вычисляем вероятности перехода к непосещённым узлам, с которыми есть ребро
       for (let j = 0; j < params.nodeCount; j++) {
-        if (!visited.has(j)) {
+        if (!visited.has(j) && isFinite(distances[current][j])) {
           const tau = Math.pow(Math.max(pheromones[current][j], 1e-10), params.alpha);
           const eta = Math.pow(1 / Math.max(distances[current][j], 1e-10), params.beta);
           const probability = tau * eta;
@@ -375,9 +436,20 @@ export function initAnts(canvas, options = {}) {
       current = chosen;
     }
 
+    // Замыкаем тур, если обойдены все узлы и из последнего есть ребро в первый
+    if (tour && path.length === params.nodeCount && isFinite(distances[current][fromNode])) {
+      path.push(fromNode);
+    }
+
     return path;
   }
 
+  // Решил ли муравей задачу: дошёл до финиша или замкнул тур через все узлы
+  function solved(path) {
+    if (params.objective === 'tour') return path.length === params.nodeCount + 1;
+    return path[path.length - 1] === endNode;
+  }
+
   function calculatePathLength(path) {
     if (path.length < 2) return Infinity;
     
@@ -420,7 +492,7 @@ export function initAnts(canvas, options = {}) {
       pathLengths.push(pathLength);
     }
 
-    // Находим лучший путь в текущей итерации (только среди тех, что достигли цели)
+    // Находим лучший путь в текущей итерации (только среди тех, что решили задачу)
     let iterationBestIdx = -1;
     let iterationBestLength = Infinity;
     
@@ -428,8 +500,8 @@ export function initAnts(canvas, options = {}) {
       const path = paths[i];
       const length = pathLengths[i];
       
-      // Проверяем, достиг ли путь конечной точки
-      if (path[path.length - 1] === endNode && length < iterationBestLength) {
+      // Проверяем, достиг ли путь конечной точки или замкнулся ли тур
+      if (solved(path) && length < iterationBestLength) {
         iterationBestLength = length;
         iterationBestIdx = i;
       }
@@ -456,13 +528,13 @@ export function initAnts(canvas, options = {}) {
       }
     }
 
-    // Откладывание феромонов муравьями, достигшими цели
+    // Откладывание феромонов муравьями, решившими задачу; у тура и на замыкающем ребре
     for (let ant = 0; ant < params.colonySize; ant++) {
       const path = paths[ant];
       const pathLength = pathLengths[ant];
       
-      // Откладываем феромон только если муравей достиг конечной точки
-      if (path[path.length - 1] === endNode && pathLength < Infinity && pathLength > 0) {
+      // Откладываем феромон только если муравей решил задачу
+      if (solved(path) && pathLength < Infinity && pathLength > 0) {
         const deltaTau = params.Q / pathLength;
         
         // Обновляем феромоны на рёбрах пути
@@ -515,6 +587,7 @@ export function initAnts(canvas, options = {}) {
 
   function newGraph() {
     pause();
+    unloadGraph();
     // Генерируем новый случайный сид
     const newSeed = generateRandomSeed();
     params.seed = newSeed;
@@ -531,6 +604,7 @@ export function initAnts(canvas, options = {}) {
   }
 
   function updateNodeCount(n) {
+    unloadGraph();
     params.nodeCount = n;
     generateGraph();
     draw();
@@ -538,6 +612,9 @@ export function initAnts(canvas, options = {}) {
   }
 
   function updateParams(newParams) {
+    if (iteration > 0 && ['alpha', 'beta', 'rho', 'Q', 'colonySize', 'tau0'].some(key => key in newParams)) {
+      modified = true;
+    }
     Object.assign(params, newParams);
     
     if (newParams.nodeCount !== undefined) {
@@ -570,6 +647,91 @@ export function initAnts(canvas, options = {}) {
     }
   }
 
+  // Показывает граф из файла вместо случайного: число вершин и ориентированность
+  // берутся из файла, прогон начинается заново
+  function loadGraph(instance) {
+    pause();
+    loaded = instance;
+    params.nodeCount = instance.nodes.length;
+    if (instance.directed) params.graphType = 'directed';
+    syncGraphControls();
+    generateGraph();
+    draw();
+    updateInfo();
+  }
+
+  // Возвращает случайный граф; вызывающий сам перестраивает граф
+  function unloadGraph() {
+    if (!loaded) return;
+    loaded = null;
+    params.nodeCount = Math.min(Math.max(params.nodeCount, 5), 25);
+    syncGraphControls();
+  }
+
+  // Приводит ползунок вершин, кнопку типа графа и список графов к текущему графу
+  function syncGraphControls() {
+    const graphFile = document.getElementById('graphFile');
+    const nodeCountInput = document.getElementById('nodeCount');
+    const nodeCountVal = document.getElementById('nodeCountVal');
+    const graphTypeBtn = document.getElementById('graphTypeBtn');
+    if (graphFile && !loaded) graphFile.value = '';
+    if (nodeCountInput) nodeCountInput.value = params.nodeCount;
+    if (nodeCountVal) nodeCountVal.textContent = params.nodeCount;
+    if (graphTypeBtn) {
+      graphTypeBtn.textContent = params.graphType === 'undirected' ? 'неориентированный' : 'ориентированный';
+      graphTypeBtn.disabled = Boolean(loaded?.directed);
+    }
+  }
+
+  function hasEdge(i, j) {
+    return i !== j && isFinite(distances[i][j]);
+  }
+
+  // Манифест прогона для повтора в Go (пакет manifest): параметры и граф в момент построения,
+  // число итераций и итог последней. engineVersion совпадает с aco.EngineVersion.
+  // Граф из файла записывается целиком в instance, и Go повторяет прогон на нем
+  function exportManifest() {
+    return {
+      format: 1,
+      algorithm: 'aco',
+      engineVersion: 1,
+      source: 'browser',
+      created: new Date().toISOString(),
+      params: {
+        nodeCount: params.nodeCount,
+        alpha: params.alpha,
+        beta: params.beta,
+        rho: params.rho,
+        Q: params.Q,
+        colonySize: params.colonySize,
+        // Бюджет могли уменьшить после запуска, а повтору нужны все выполненные итерации
+        maxIterations: Math.max(params.maxIterations, iteration),
+        tau0: params.tau0,
+        graphType: params.graphType,
+        startDist: params.startDist,
+        objective: params.objective,
+        seed: params.seed,
+        width: builtGraph.width,
+        height: builtGraph.height
+      },
+      steps: iteration,
+      graph: { nodes: builtGraph.nodes, start: startNode, end: endNode },
+      instance: loaded || undefined,
+      // Бесконечные длины JSON.stringify записывает как null
+      result: { iteration, bestPath, bestLength, currentBestPath, currentBestLength },
+      modified
+    };
+  }
+
+  function downloadManifest() {
+    const blob = new Blob([JSON.stringify(exportManifest(), null, 2) + '\n'], { type: 'application/json' });
+    const link = document.createElement('a');
+    link.href = URL.createObjectURL(blob);
+    link.download = `aco-seed${params.seed}-it${iteration}.json`;
+    link.click();
+    setTimeout(() => URL.revokeObjectURL(link.href), 0);
+  }
+
   function updateInfo() {
     const iterationEl = document.getElementById('iterationCount');
     const bestLengthEl = document.getElementById('bestPathLength');
@@ -578,6 +740,11 @@ export function initAnts(canvas, options = {}) {
     if (iterationEl) iterationEl.textContent = iteration;
     if (bestLengthEl) {
       bestLengthEl.textContent = bestLength < Infinity ? bestLength.toFixed(1) : "–";
+      // Отставание тура от известного оптимума задачи TSPLIB
+      if (bestLength < Infinity && params.objective === 'tour' && loaded?.optimum) {
+        const gap = (bestLength - loaded.optimum) / loaded.optimum * 100;
+        bestLengthEl.textContent += ` (+${gap.toFixed(1)}%)`;
+      }
     }
     if (currentLengthEl) {
       currentLengthEl.textContent = currentBestLength < Infinity ? currentBestLength.toFixed(1) : "–";
@@ -600,7 +767,8 @@ export function initAnts(canvas, options = {}) {
         updateParams, 
         start, 
         pause,
-        drawStaticFrame
+        drawStaticFrame,
+        exportManifest
       };
     }
 
@@ -638,6 +806,39 @@ export function initAnts(canvas, options = {}) {
     const stepBtn = document.getElementById('stepBtn');
 
     newGraphBtn.addEventListener('click', newGraph);
+
+    // Список графов из файлов есть, только когда страницу раздает cmd/server
+    const graphFile = document.getElementById('graphFile');
+    if (graphFile) {
+      fetch('/api/aco/graphs')
+        .then(response => response.ok ? response.json() : [])
+        .then(graphs => {
+          for (const graph of graphs) {
+            const option = document.createElement('option');
+            option.value = graph.name;
+            option.textContent = `${graph.name} (${graph.nodes} вершин${graph.directed ? ', орграф' : ''})`;
+            option.title = graph.comment ?? '';
+            graphFile.append(option);
+          }
+          if (graphs.length > 0) document.getElementById('graphFileRow').style.display = '';
+        })
+        .catch(() => {});
+      graphFile.addEventListener('change', async () => {
+        if (!graphFile.value) {
+          newGraph();
+          return;
+        }
+        try {
+          const response = await fetch(`/api/aco/graphs/${encodeURIComponent(graphFile.value)}`);
+          if (!response.ok) throw new Error((await response.json()).error);
+          loadGraph(await response.json());
+        } catch (err) {
+          alert(`Граф не загружен: ${err.message}`);
+          graphFile.value = '';
+        }
+      });
+    }
+    document.getElementById('exportBtn').addEventListener('click', downloadManifest);
     stepBtn.addEventListener('click', step);
 
     // Кнопка расширенных настроек
@@ -667,11 +868,24 @@ export function initAnts(canvas, options = {}) {
       });
     }
 
+    // Кнопка задачи: путь до финиша или замкнутый тур; найденные решения теряют смысл
+    const objectiveBtn = document.getElementById('objectiveBtn');
+    if (objectiveBtn) {
+      objectiveBtn.addEventListener('click', () => {
+        params.objective = params.objective === 'path' ? 'tour' : 'path';
+        objectiveBtn.textContent = params.objective === 'path' ? 'путь' : 'тур';
+        generateGraph();
+        draw();
+        updateInfo();
+      });
+    }
+
     // Кнопка стартового распределения
     const startDistBtn = document.getElementById('startDistBtn');
     if (startDistBtn) {
       startDistBtn.addEventListener('click', () => {
         params.startDist = params.startDist === 'uniform' ? 'fixed' : 'uniform';
+        if (iteration > 0) modified = true;
         startDistBtn.textContent = params.startDist === 'uniform' ? 'равномерное' : 'фиксированное';
       });
     }
@@ -790,6 +1004,10 @@ export function initAnts(canvas, options = {}) {
         title: 'Тип графа (неориентированный/ориентированный)',
         description: 'Определяет симметрию феромонов: для неориентированного случая $\\tau_{ij} = \\tau_{ji}$ и $w_{ij} = w_{ji}$, для орграфа — независимые $\\tau_{ij}$ и $\\tau_{ji}$. Влияет на множество допустимых переходов и на нормировку $p_{ij}^k(t)$.'
       },
+      'objective': {
+        title: 'Задача (путь/тур)',
+        description: 'Путь: муравей идёт от старта, пока не достигнет финиша, и решение — маршрут $s \\to t$. Тур: муравей обходит все вершины $V$ и возвращается в первую, решение — гамильтонов цикл, и $L_k(t)$ включает замыкающее ребро. Для графов TSPLIB рядом с лучшей длиной выводится отставание от известного оптимума $(L - L^*)/L^*$.'
+      },
       'startDist': {
         title: 'Распределение стартовых вершин',
         description: 'Закон выбора начальной вершины $i_0$ для каждого муравья: равномерно по $V$ либо по заданному распределению. Контролирует охват пространства решений на ранних итерациях.'
@@ -893,6 +1111,8 @@ export function initAnts(canvas, options = {}) {
     step,
     reset,
     drawStaticFrame,
-    handleResize
+    handleResize,
+    exportManifest,
+    loadGraph
   };
 }
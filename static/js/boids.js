@@ -1,3 +1,7 @@
+import { connectSession } from './stream.js';
+
+// options.server включает режим просмотра: стаю шагает сеанс потока на сервере (cmd/server),
+// а страница только рисует присланные кадры
 export function initBoids(canvas, options = {}) {
   const ctx = canvas.getContext("2d");
   canvas.width = canvas.clientWidth;
@@ -29,6 +33,10 @@ export function initBoids(canvas, options = {}) {
   // Предрендеренные LaTeX формулы
   let tooltipElements = {};
 
+  // Сеанс потока в режиме просмотра
+  let viewer = Boolean(options.server);
+  let session = null;
+
   // Параметры
   const params = {
     // кинематика
@@ -297,6 +305,7 @@ export function initBoids(canvas, options = {}) {
   }
 
   let boids = Array.from({length: params.boidCount}, ()=> new Boid());
+  if (viewer) connect();
 
   function updateBoidCount(n){
     params.boidCount = n;
@@ -315,10 +324,59 @@ export function initBoids(canvas, options = {}) {
     }
   }
 
+  // Параметры движка boids.Params; размер области задается только при создании сеанса
+  function engineParams(){
+    return {
+      dt: params.dt, v_max: params.v_max, a_max: params.a_max, v_pref: params.v_pref,
+      tauMatch: params.tauMatch, tauCenter: params.tauCenter, tauSep: params.tauSep,
+      k_sep: params.k_sep, dampMode: params.dampMode, gamma: params.gamma,
+      neighborMode: params.neighborMode ? 'metric' : 'topo',
+      r: params.r, r_sep: params.r_sep, fovDeg: params.fovDeg, kTopo: params.kTopo,
+      'w.match': params.w.match, 'w.center': params.w.center, 'w.sep': params.w.sep,
+      boidCount: params.boidCount, walls: wallsEnabled
+    };
+  }
+
+  // Кадр сеанса: особи [x, y, vx, vy] заменяют локальные, след копится по кадрам
+  function applyFrame(frame){
+    const data = frame.agents ?? [];
+    if (boids.length !== data.length) boids = data.map(()=> new Boid());
+    data.forEach(([x, y, vx, vy], i) => {
+      const b = boids[i];
+      if (frame.keyframe) b.history = [];
+      b.X = {x, y};
+      b.V = {x: vx, y: vy};
+      if (params.tracing){
+        b.history.push({x, y});
+        if (b.history.length>b.maxTrail) b.history.shift();
+      }
+    });
+    if (!isAnimationRunning) drawStaticFrame();
+  }
+
+  function connect(){
+    connectSession('boids', {
+      base: typeof options.server === 'string' ? options.server : '',
+      params: { ...engineParams(), width: canvas.width, height: canvas.height },
+      fps: 60,
+      paused: isPaused,
+      onFrame: applyFrame,
+      onError: (err) => console.error('Сеанс boids:', err)
+    }).then((s) => { session = s; })
+      .catch((err) => {
+        console.error('Сеанс boids не открыт, расчет остается в браузере:', err);
+        viewer = false;
+      });
+  }
+
   function animate(){
+    if (session) {
+      session.setPaused(isPaused);
+      session.sync(engineParams());
+    }
     ctx.clearRect(0,0,canvas.width,canvas.height);
     for (const b of boids){
-      if (!isPaused && isAnimationRunning) {
+      if (!isPaused && isAnimationRunning && !viewer) {
         b.flock(boids);
         b.update();
         b.edges();
@@ -341,6 +399,7 @@ export function initBoids(canvas, options = {}) {
   function pauseAnimation() {
     isPaused = true;
     isAnimationRunning = false;
+    session?.setPaused(true);
     if (animationId) {
       cancelAnimationFrame(animationId);
       animationId = null;
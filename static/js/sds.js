@@ -4,6 +4,9 @@
 //   const stop = initSDS(canvas, { N: 300 });
 //   // Опционально: initSDS(canvas, opts).createControls(domContainer)
 //   // Для остановки анимации: stop()
+//   // Расчет на сервере (cmd/server), страница только рисует кадры: initSDS(canvas, { server: true })
+
+import { connectSession } from './stream.js';
 
 export function initSDS(canvas, options = {}) {
   const ctx = canvas.getContext("2d");
@@ -135,6 +138,15 @@ export function initSDS(canvas, options = {}) {
   // Предрендеренные LaTeX формулы
   let tooltipElements = {};
 
+  // Для манифеста прогона: trackCount, с которым выбраны траектории (выбор расходует генератор),
+  // и признак того, что параметры шага меняли посреди прогона
+  let initTrackCount = params.trackCount;
+  let modified = false;
+
+  // Режим просмотра: шаги делает сеанс потока на сервере, кадры приходят в applyFrame
+  let viewer = Boolean(options.server);
+  let session = null;
+
   // ===== Инициализация =====
   function randInRange(){ return (rng.next()*2 - 1) * params.range; }
 
@@ -146,6 +158,8 @@ export function initSDS(canvas, options = {}) {
     tracksIdx = Array.from(set.values());
     tracks = tracksIdx.map(()=> []);
     t = 0; bestF = 0; meanF = 0; winnersFrac = 0;
+    initTrackCount = params.trackCount;
+    modified = false;
   }
 
   // ===== Математика целевой функции =====
@@ -235,7 +249,7 @@ export function initSDS(canvas, options = {}) {
 
   // ===== Шаг SDS =====
   function step(){
-    if (isPaused || !isAnimationRunning) return;
+    if (isPaused || !isAnimationRunning || viewer) return;
 
     const n = agents.length;
     winnersIdx.length = 0;
@@ -296,6 +310,50 @@ export function initSDS(canvas, options = {}) {
     updateUIMetrics();
   }
 
+  // ===== Кадры сеанса потока =====
+  // Параметры движка sds.Params; остальные поля params относятся только к отрисовке
+  function engineParams(){
+    return {
+      N: params.N, seed: params.seed, func: params.func, sigma: params.sigma,
+      anneal: params.anneal, annealDecay: params.annealDecay,
+      restartProb: params.restartProb, trackCount: params.trackCount
+    };
+  }
+
+  function applyFrame(frame){
+    agents = frame.agents.map(([x, y, s]) => ({ x, y, success: s === 1 }));
+
+    // Траектории наращиваются по кадрам; опорный кадр приходит после перезапуска и перемотки
+    const tracked = frame.tracked ?? [];
+    if (frame.keyframe || tracks.length !== tracked.length) tracks = tracked.map(() => []);
+    tracked.forEach(([x, y], k) => {
+      tracks[k].push([x, y]);
+      if (tracks[k].length > params.maxTrail) tracks[k].shift();
+    });
+
+    const m = frame.metrics;
+    t = m.t; bestF = m.bestF; meanF = m.meanF; winnersFrac = m.winnersFrac;
+    winnersIdx.length = m.winnersCount;
+    updateUIMetrics();
+    if (!isAnimationRunning) draw();
+  }
+
+  function connect(){
+    connectSession('sds', {
+      base: typeof options.server === 'string' ? options.server : '',
+      params: engineParams(),
+      fps: 60,
+      stepsPerFrame: params.stepsPerFrame,
+      paused: isPaused,
+      onFrame: applyFrame,
+      onError: (err) => console.error('Сеанс sds:', err)
+    }).then((s) => { session = s; })
+      .catch((err) => {
+        console.error('Сеанс sds не открыт, расчет остается в браузере:', err);
+        viewer = false;
+      });
+  }
+
   // ===== Обновление метрик в UI =====
   function updateUIMetrics() {
     // Обновляем таблицу в панели управления
@@ -367,6 +425,10 @@ export function initSDS(canvas, options = {}) {
 
   // ===== Цикл =====
   function loop(){
+    if (session) {
+      session.setPaused(isPaused);
+      session.sync(engineParams());
+    }
     // Выполняем шаги алгоритма только если не на паузе
     if (!isPaused && isAnimationRunning) {
       for (let k=0;k<params.stepsPerFrame;k++) step();
@@ -389,6 +451,7 @@ export function initSDS(canvas, options = {}) {
   function pauseAnimation() {
     isPaused = true;
     isAnimationRunning = false;
+    session?.setPaused(true);
     if (animationId) {
       cancelAnimationFrame(animationId);
       animationId = null;
@@ -415,7 +478,9 @@ export function initSDS(canvas, options = {}) {
     if (key === 'seed') {
       params.seed = (val>>>0) || 123456789; rng = new RNG(params.seed); initAgents(); return;
     }
+    if (t > 0 && ['sigma', 'anneal', 'annealDecay', 'restartProb'].includes(key)) modified = true;
     params[key] = val;
+    if (key === 'stepsPerFrame') session?.speed(undefined, val);
     if (key === 'heatGrid') heatValid = false;
   }
 
@@ -452,11 +517,13 @@ export function initSDS(canvas, options = {}) {
   }
 
   function reset() {
+    session?.reset();
     initAgents();
     updateUIMetrics();
   }
 
   function stop(){ 
+    session?.close();
     if (animationId) cancelAnimationFrame(animationId);
     isAnimationRunning = false;
     window.removeEventListener('resize', onResize); 
@@ -551,6 +618,45 @@ export function initSDS(canvas, options = {}) {
   }
 
   // ===== Функция для получения текущих метрик =====
+  // ===== Манифест прогона для повтора в Go (пакет manifest) =====
+  // engineVersion совпадает с sds.EngineVersion
+  function exportManifest() {
+    return {
+      format: 1,
+      algorithm: 'sds',
+      engineVersion: 1,
+      source: 'browser',
+      created: new Date().toISOString(),
+      params: {
+        N: params.N,
+        seed: params.seed,
+        func: params.func,
+        sigma: params.sigma,
+        anneal: params.anneal,
+        annealDecay: params.annealDecay,
+        restartProb: params.restartProb,
+        trackCount: initTrackCount
+      },
+      steps: t,
+      objective: { func: params.func, name: Objectives[params.func].name, range: params.range },
+      result: {
+        t, bestF, meanF, winnersFrac,
+        winnersCount: t > 0 ? winnersIdx.length : 0,
+        agents: agents.map(({ x, y }) => [x, y])
+      },
+      modified
+    };
+  }
+
+  function downloadManifest() {
+    const blob = new Blob([JSON.stringify(exportManifest(), null, 2) + '\n'], { type: 'application/json' });
+    const link = document.createElement('a');
+    link.href = URL.createObjectURL(blob);
+    link.download = `sds-${params.func}-seed${params.seed}-t${t}.json`;
+    link.click();
+    setTimeout(() => URL.revokeObjectURL(link.href), 0);
+  }
+
   function getMetrics() {
     return {
       t: t,
@@ -578,7 +684,8 @@ export function initSDS(canvas, options = {}) {
         getMetrics, 
         startAnimation, 
         pauseAnimation,
-        drawStaticFrame
+        drawStaticFrame,
+        exportManifest
       };
     }
 
@@ -626,6 +733,8 @@ export function initSDS(canvas, options = {}) {
     });
 
     // Кнопка генерации нового сида
+    document.getElementById('exportBtn').addEventListener('click', downloadManifest);
+
     document.getElementById('newSeedBtn').addEventListener('click', () => {
       reset();
       const newSeed = Math.floor(Math.random() * 1000000000);
@@ -777,6 +886,7 @@ export function initSDS(canvas, options = {}) {
   initAgents();
   renderHeatmap();
   window.addEventListener('resize', onResize);
+  if (viewer) connect();
   
   // Запускаем анимацию если не указан стартовый режим паузы
   if (!isPaused) {
@@ -798,6 +908,7 @@ export function initSDS(canvas, options = {}) {
     stop,
     startAnimation,
     pauseAnimation,
-    drawStaticFrame
+    drawStaticFrame,
+    exportManifest
   };
 }
@@ -0,0 +1,164 @@
+// Полнотекстовый поиск по описаниям алгоритмов без сервера.
+// Индекс строится конвертером: go run ./utils -search-index static/latex -output static/search-index.json
+// Токенизация и стемминг повторяют utils/latex/search.go и utils/latex/stem.go, иначе основы слов запроса
+// не совпадут с основами в индексе.
+// Использование:
+//   const index = await loadSearchIndex("../static/search-index.json");
+//   const results = searchIndex(index, "испарение феромона");
+
+// ===== Русский стеммер Snowball =====
+const VOWELS = "аеиоуыэюя";
+
+function suffixes(afterAYa, plain) {
+  const list = [];
+  for (const s of afterAYa.split(" ").filter(Boolean)) list.push({ text: s, afterAYa: true });
+  for (const s of plain.split(" ").filter(Boolean)) list.push({ text: s, afterAYa: false });
+  // Как и в Snowball, выбирается самое длинное подходящее окончание (сортировка устойчива)
+  return list.sort((a, b) => [...b.text].length - [...a.text].length);
+}
+
+const PERFECTIVE_GERUND = suffixes("в вши вшись", "ив ивши ившись ыв ывши ывшись");
+const ADJECTIVE = suffixes("", "ее ие ые ое ими ыми ей ий ый ой ем им ым ом его ого ему ому их ых ую юю ая яя ою ею");
+const PARTICIPLE = suffixes("ем нн вш ющ щ", "ивш ывш ующ");
+const REFLEXIVE = suffixes("", "ся сь");
+const VERB = suffixes("ла на ете йте ли й л ем н ло но ет ют ны ть ешь нно",
+  "ила ыла ена ейте уйте ите или ыли ей уй ил ыл им ым ен ило ыло ено ят ует уют ит ыт ены ить ыть ишь ую ю");
+const NOUN = suffixes("", "а ев ов ие ье е иями ями ами еи ии и ией ей ой ий й иям ям ием ем ам ом о у ах иях ях ы ь ию ью ю ия ья я");
+const DERIVATIONAL = suffixes("", "ост ость");
+const SUPERLATIVE = suffixes("", "ейш ейше");
+
+export function stemRussian(word) {
+  let w = [...word.replaceAll("ё", "е")];
+  const isVowel = (c) => VOWELS.includes(c);
+
+  let rv = w.length;
+  for (let i = 0; i < w.length; i++) {
+    if (isVowel(w[i])) { rv = i + 1; break; }
+  }
+  const regionAfter = (start) => {
+    for (let i = start + 1; i < w.length; i++) {
+      if (!isVowel(w[i]) && isVowel(w[i - 1])) return i + 1;
+    }
+    return w.length;
+  };
+  const r2 = regionAfter(regionAfter(0));
+
+  const strip = (list, start) => {
+    for (const suffix of list) {
+      const s = [...suffix.text];
+      const n = s.length;
+      if (w.length - n < start || w.slice(w.length - n).join("") !== suffix.text) continue;
+      if (suffix.afterAYa) {
+        const prev = w[w.length - n - 1];
+        if (w.length - n - 1 < start || (prev !== "а" && prev !== "я")) continue;
+      }
+      w = w.slice(0, w.length - n);
+      return true;
+    }
+    return false;
+  };
+
+  // Шаг 1
+  if (!strip(PERFECTIVE_GERUND, rv)) {
+    strip(REFLEXIVE, rv);
+    if (strip(ADJECTIVE, rv)) {
+      strip(PARTICIPLE, rv);
+    } else if (!strip(VERB, rv)) {
+      strip(NOUN, rv);
+    }
+  }
+
+  // Шаг 2
+  if (w.length > rv && w[w.length - 1] === "и") w = w.slice(0, -1);
+
+  // Шаг 3
+  strip(DERIVATIONAL, Math.max(r2, rv));
+
+  // Шаг 4
+  const undouble = () => {
+    if (w.length - 2 >= rv && w[w.length - 1] === "н" && w[w.length - 2] === "н") {
+      w = w.slice(0, -1);
+      return true;
+    }
+    return false;
+  };
+  if (!undouble()) {
+    if (strip(SUPERLATIVE, rv)) {
+      undouble();
+    } else if (w.length > rv && w[w.length - 1] === "ь") {
+      w = w.slice(0, -1);
+    }
+  }
+
+  return w.join("");
+}
+
+// ===== Токенизация =====
+const STOP_WORDS = new Set(["и", "в", "во", "на", "с", "со", "по", "для", "не", "что", "как", "из", "к", "а", "о", "от",
+  "при", "же", "или", "это", "то", "его", "ее", "их", "за", "до", "но", "у", "the", "of", "and"]);
+
+export function tokenize(text) {
+  const words = text.toLowerCase().split(/[^\p{L}\p{N}]+/u).filter(Boolean);
+  const terms = [];
+  for (let word of words) {
+    word = word.replaceAll("ё", "е");
+    if (STOP_WORDS.has(word) || (word.length === 1 && word.charCodeAt(0) < 0x80)) continue;
+    terms.push(stemRussian(word));
+  }
+  return terms;
+}
+
+// ===== Поиск =====
+export async function loadSearchIndex(url) {
+  const response = await fetch(url);
+  if (!response.ok) throw new Error(`Не удалось загрузить поисковый индекс: ${response.status}`);
+  return response.json();
+}
+
+// Возвращает фрагменты, содержащие все слова запроса. Последнее слово ищется по префиксу,
+// чтобы результаты появлялись по мере набора. Совпадения в заголовке и параметры выше в выдаче
+export function searchIndex(index, query, limit = 20) {
+  const terms = tokenize(query);
+  if (terms.length === 0) return [];
+
+  let matched = null;
+  const scores = new Map();
+  terms.forEach((term, i) => {
+    const ids = new Set(index.terms[term] || []);
+    if (i === terms.length - 1) {
+      for (const key of Object.keys(index.terms)) {
+        if (key.startsWith(term)) index.terms[key].forEach((id) => ids.add(id));
+      }
+    }
+    matched = matched === null ? ids : new Set([...matched].filter((id) => ids.has(id)));
+    ids.forEach((id) => scores.set(id, (scores.get(id) || 0) + 1));
+  });
+
+  const titleTerms = (entry) => new Set(tokenize(entry.title));
+  return [...matched]
+    .map((id) => {
+      const entry = index.entries[id];
+      const inTitle = titleTerms(entry);
+      let score = scores.get(id);
+      for (const term of terms) if (inTitle.has(term)) score += 2;
+      if (entry.kind === "param" || entry.kind === "section") score += 1;
+      return { ...entry, document: index.documents[entry.doc], score };
+    })
+    .sort((a, b) => b.score - a.score || a.doc - b.doc || a.line - b.line)
+    .slice(0, limit);
+}
+
+// Короткий фрагмент текста вокруг первого найденного слова
+export function snippet(text, query, radius = 80) {
+  const terms = tokenize(query);
+  const lower = text.toLowerCase().replaceAll("ё", "е");
+  let pos = -1;
+  for (const term of terms) {
+    pos = lower.indexOf(term);
+    if (pos >= 0) break;
+  }
+  if (pos < 0) return text.length > radius * 2 ? text.slice(0, radius * 2) + "…" : text;
+  const start = Math.max(0, pos - radius);
+  const end = Math.min(text.length, pos + radius);
+  return (start > 0 ? "…" : "") + text.slice(start, end) + (end < text.length ? "…" : "");
+}
@@ -0,0 +1,119 @@
+// Клиент сеанса потока (пакет api, команда cmd/server): симуляцию шагает сервер,
+// страница получает кадры через EventSource и только рисует их.
+// Использование:
+//   const session = await connectSession('boids', { params, onFrame: (frame, state) => ... });
+//   session.sync(params);       // отправить изменившиеся параметры
+//   session.setPaused(true);    session.step();    session.seek(500);
+//   session.close();
+//
+// state собирает кадры в полное состояние: для aco в нем граф и матрица феромона,
+// восстановленная из опорного кадра и изменений [i, j, v]
+
+export async function connectSession(algorithm, options = {}) {
+  const base = `${options.base ?? ''}/api/${algorithm}/sessions`;
+
+  async function request(url, method, body) {
+    const response = await fetch(url, {
+      method,
+      headers: { 'Content-Type': 'application/json' },
+      body: body === undefined ? undefined : JSON.stringify(body)
+    });
+    const data = response.status === 204 ? null : await response.json();
+    if (!response.ok) throw new Error(data?.error ?? `HTTP ${response.status}`);
+    return data;
+  }
+
+  const status = await request(base, 'POST', {
+    params: options.params ?? {},
+    fps: options.fps ?? 30,
+    stepsPerFrame: options.stepsPerFrame ?? 1,
+    agents: options.agents ?? 0,
+    paused: options.paused ?? false
+  });
+  const url = `${base}/${status.id}`;
+
+  // Полное состояние, собранное из кадров
+  const state = { step: 0, paused: status.paused, params: status.params, metrics: null, agents: [], graph: null, pheromone: null };
+
+  let paused = status.paused;
+
+  const events = new EventSource(`${url}/events`);
+  events.addEventListener('frame', (e) => {
+    const frame = JSON.parse(e.data);
+    state.step = frame.step;
+    state.paused = paused = frame.paused;
+    state.metrics = frame.metrics;
+    state.agents = frame.agents ?? [];
+    if (frame.params) state.params = frame.params;
+    if (frame.keyframe) {
+      state.graph = frame.graph ?? null;
+      state.pheromone = frame.pheromone ?? null;
+    }
+    for (const [i, j, v] of frame.pheromoneDelta ?? []) state.pheromone[i][j] = v;
+    options.onFrame?.(frame, state);
+  });
+  events.addEventListener('end', () => {
+    events.close();
+    options.onEnd?.();
+  });
+  events.onerror = () => options.onError?.(new Error('поток кадров прерван'));
+
+  // Параметры отправляются по одной команде за раз: пока ответ не пришел,
+  // изменения от ползунков копятся и уходят следующей командой
+  let lastSent = { ...status.params };
+  let pending = null;
+  let inFlight = false;
+
+  const differs = ([key, value]) => JSON.stringify(value) !== JSON.stringify(lastSent[key]);
+
+  async function flush() {
+    if (inFlight || !pending) return;
+    const changes = Object.fromEntries(Object.entries(pending).filter(differs));
+    pending = null;
+    if (Object.keys(changes).length === 0) return;
+    inFlight = true;
+    try {
+      const reply = await request(url, 'POST', { action: 'params', params: changes });
+      lastSent = { ...reply.params };
+    } catch (err) {
+      // Отклоненные значения не отправляются повторно, пока их не изменят
+      lastSent = { ...lastSent, ...changes };
+      options.onError?.(err);
+    } finally {
+      inFlight = false;
+      flush();
+    }
+  }
+
+  // sync отправляет только ключи, значения которых отличаются от последних принятых сервером
+  function sync(params) {
+    for (const entry of Object.entries(params)) {
+      if (differs(entry)) pending = { ...pending, [entry[0]]: entry[1] };
+    }
+    flush();
+  }
+
+  const command = (body) => request(url, 'POST', body).catch((err) => options.onError?.(err));
+
+  // Сервер сам ставит сеанс на паузу, когда бюджет итераций aco исчерпан
+  function setPaused(value) {
+    if (value === paused) return;
+    paused = value;
+    command({ action: value ? 'pause' : 'resume' });
+  }
+
+  return {
+    id: status.id,
+    state,
+    sync,
+    setPaused,
+    step: (steps = 1) => { paused = true; return command({ action: 'step', steps }); },
+    seek: (step) => command({ action: 'seek', step }),
+    reset: () => command({ action: 'reset' }),
+    speed: (fps, stepsPerFrame) => command({ action: 'speed', fps, stepsPerFrame }),
+    close() {
+      events.close();
+      return request(url, 'DELETE').catch(() => {});
+    }
+  };
+}
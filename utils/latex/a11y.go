@@ -0,0 +1,249 @@
+package latex
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// mathSymbolNames сопоставляет командам TeX символы, которые озвучивают программы экранного доступа
+var mathSymbolNames = map[string]string{
+	"alpha": "α", "beta": "β", "gamma": "γ", "delta": "δ", "epsilon": "ε", "varepsilon": "ε",
+	"zeta": "ζ", "eta": "η", "theta": "θ", "vartheta": "ϑ", "iota": "ι", "kappa": "κ",
+	"lambda": "λ", "mu": "μ", "nu": "ν", "xi": "ξ", "pi": "π", "rho": "ρ", "sigma": "σ",
+	"tau": "τ", "upsilon": "υ", "phi": "φ", "varphi": "φ", "chi": "χ", "psi": "ψ", "omega": "ω",
+	"Gamma": "Γ", "Delta": "Δ", "Theta": "Θ", "Lambda": "Λ", "Xi": "Ξ", "Pi": "Π",
+	"Sigma": "Σ", "Phi": "Φ", "Psi": "Ψ", "Omega": "Ω",
+	"le": "≤", "leq": "≤", "ge": "≥", "geq": "≥", "ne": "≠", "neq": "≠", "approx": "≈",
+	"sim": "∼", "equiv": "≡", "in": "∈", "notin": "∉", "subset": "⊂", "subseteq": "⊆",
+	"cup": "∪", "cap": "∩", "emptyset": "∅", "varnothing": "∅", "infty": "∞",
+	"sum": "∑", "prod": "∏", "int": "∫", "partial": "∂", "nabla": "∇",
+	"forall": "∀", "exists": "∃", "to": "→", "rightarrow": "→", "leftarrow": "←", "gets": "←",
+	"propto": "∝", "wedge": "∧", "vee": "∨", "Rightarrow": "⇒", "Leftrightarrow": "⇔", "mapsto": "↦", "cdot": "·", "times": "×",
+	"pm": "±", "star": "⋆", "mid": "∣", "ldots": "…", "dots": "…", "cdots": "⋯",
+	"langle": "⟨", "rangle": "⟩", "lVert": "‖", "rVert": "‖", "Vert": "‖",
+}
+
+// mathLayoutCommands не влияют на смысл формулы и при озвучивании опускаются
+var mathLayoutCommands = map[string]bool{
+	"left": true, "right": true, "bigl": true, "bigr": true, "Bigl": true, "Bigr": true,
+	"big": true, "Big": true, "displaystyle": true, "textstyle": true, "quad": true, "qquad": true,
+	"mathrm": true, "mathbf": true, "mathit": true, "mathbb": true, "mathcal": true,
+	"operatorname": true, "text": true, "limits": true, "nolimits": true,
+}
+
+var (
+	mathCommandRe     = regexp.MustCompile(`\\([a-zA-Z]+)\s*`)
+	mathEnvironmentRe = regexp.MustCompile(`\\(?:begin|end)\{[^}]*\}`)
+	mathLineBreakRe   = regexp.MustCompile(`\\\\(?:\[[^\]]*\])?`)
+	headingLevelRe    = regexp.MustCompile(`\\((?:sub){0,2})section(\*?)\s*\{`)
+	sectionLabelRe    = regexp.MustCompile(`^\s*\\label\{([^}]+)\}`)
+)
+
+// mathEscapeReplacer убирает пробелы TeX и прячет экранированные скобки от mathBraceReplacer
+var mathEscapeReplacer = strings.NewReplacer(
+	`\{`, "\x01",
+	`\}`, "\x02",
+	`\|`, "‖",
+	`\$`, "$",
+	`\,`, " ",
+	`\;`, " ",
+	`\:`, " ",
+	`\!`, "",
+	`\ `, " ",
+	"&", " ",
+	"~", " ",
+)
+
+// mathBraceReplacer удаляет группирующие скобки и возвращает экранированные
+var mathBraceReplacer = strings.NewReplacer("{", "", "}", "", "\x01", "{", "\x02", "}")
+
+// mathAltText строит по исходному TeX текстовое описание формулы для aria-label
+func mathAltText(tex string) string {
+	// Номер \tag виден рядом с формулой и в описание не входит
+	text := replaceCommand(tex, "tag", 1, func([]string) string { return "" })
+	for _, frac := range []string{"frac", "dfrac", "tfrac"} {
+		text = replaceCommand(text, frac, 2, func(args []string) string {
+			return "(" + mathAltText(args[0]) + ")/(" + mathAltText(args[1]) + ")"
+		})
+	}
+	text = replaceCommand(text, "sqrt", 1, func(args []string) string {
+		return "√(" + mathAltText(args[0]) + ")"
+	})
+
+	text = mathEnvironmentRe.ReplaceAllString(text, " ")
+	text = mathLineBreakRe.ReplaceAllString(text, ", ")
+	text = mathEscapeReplacer.Replace(text)
+
+	text = mathCommandRe.ReplaceAllStringFunc(text, func(match string) string {
+		name := mathCommandRe.FindStringSubmatch(match)[1]
+		if symbol, ok := mathSymbolNames[name]; ok {
+			return symbol + " "
+		}
+		if mathLayoutCommands[name] {
+			return ""
+		}
+		// Операторы вида \max, \log читаются по имени
+		return name + " "
+	})
+
+	text = mathBraceReplacer.Replace(text)
+	return strings.TrimSpace(whitespaceRunRe.ReplaceAllString(text, " "))
+}
+
+// processMathAccessibility оборачивает формулы в элементы с role="math" и текстовым описанием,
+// чтобы программы экранного доступа не зачитывали исходный TeX
+func processMathAccessibility(content string) string {
+	var out strings.Builder
+	out.Grow(len(content))
+
+	textStart := 0
+	for i := 0; i < len(content); {
+		skip := protectedSpanLength(content[i:])
+		if skip == 0 {
+			i++
+			continue
+		}
+
+		span := content[i : i+skip]
+		out.WriteString(content[textStart:i])
+		if tex, ok := mathSource(span); ok {
+			out.WriteString(`<span class="math" role="math" aria-label="` + html.EscapeString(mathAltText(tex)) + `">` + span + `</span>`)
+		} else {
+			out.WriteString(span)
+		}
+		i += skip
+		textStart = i
+	}
+	out.WriteString(content[textStart:])
+
+	return out.String()
+}
+
+// mathSource возвращает TeX формулы без ограничителей; для тегов и \$ ok равно false
+func mathSource(span string) (string, bool) {
+	for _, delimiters := range [][2]string{{"$$", "$$"}, {`\[`, `\]`}, {`\(`, `\)`}, {"$", "$"}} {
+		if strings.HasPrefix(span, delimiters[0]) && strings.HasSuffix(span, delimiters[1]) &&
+			len(span) >= len(delimiters[0])+len(delimiters[1]) {
+			return span[len(delimiters[0]) : len(span)-len(delimiters[1])], true
+		}
+	}
+	return "", false
+}
+
+// processSections заменяет \section, \subsection и \subsubsection заголовками h2–h4.
+// Уровень заголовка не может быть глубже предыдущего более чем на один, чтобы оглавление
+// для программ экранного доступа не имело пропусков; номер раздела сохраняется в labels
+func processSections(content string, state *documentState, between func(string) string) string {
+	var out strings.Builder
+	for {
+		loc := headingLevelRe.FindStringSubmatchIndex(content)
+		if loc == nil {
+			break
+		}
+		title, end, ok := readBraceGroup(content, loc[1]-1)
+		if !ok {
+			break
+		}
+
+		state.headings++
+		depth := (loc[3] - loc[2]) / 3
+		starred := loc[5] > loc[4]
+		number := ""
+		if !starred {
+			state.sections[depth]++
+			for i := depth + 1; i < len(state.sections); i++ {
+				state.sections[i] = 0
+			}
+			parts := make([]string, depth+1)
+			for i := range parts {
+				parts[i] = strconv.Itoa(state.sections[i])
+			}
+			number = strings.Join(parts, ".")
+		}
+
+		// \label сразу после заголовка задает якорь раздела
+		id := fmt.Sprintf("section-%d", state.headings)
+		if matches := sectionLabelRe.FindStringSubmatch(content[end:]); matches != nil {
+			id = matches[1]
+			state.labels[id] = number
+			end += len(matches[0])
+		}
+
+		level := min(depth+2, state.headingLevel+1)
+		state.headingLevel = level
+
+		heading := fmt.Sprintf(`<h%d id="%s">`, level, html.EscapeString(id))
+		if number != "" {
+			heading += `<span class="section-number">` + number + `</span> `
+		}
+		heading += strings.TrimSpace(title) + fmt.Sprintf(`</h%d>`, level)
+
+		out.WriteString(between(content[:loc[0]]))
+		out.WriteString("\n\n" + heading + "\n\n")
+		content = content[end:]
+	}
+	out.WriteString(between(content))
+
+	return out.String()
+}
+
+// LintAccessibility проверяет модель документа на изображения без альтернативного текста,
+// алгоритмы, листинги и плавающие окружения без подписи, пропуски уровней заголовков
+// и ссылки без текста
+func LintAccessibility(doc *Document) []Diagnostic {
+	var diagnostics []Diagnostic
+	report := func(node *Node, format string, args ...any) {
+		diagnostics = append(diagnostics, Diagnostic{Line: node.Pos.Line, Message: fmt.Sprintf(format, args...)})
+	}
+
+	previous := 0
+	var walk func(nodes []*Node)
+	walk = func(nodes []*Node) {
+		for _, node := range nodes {
+			switch node.Type {
+			case "section":
+				if node.Level > previous+1 {
+					report(node, "пропущен уровень заголовка перед %q", node.Text)
+				}
+				previous = node.Level
+			case "image":
+				if strings.TrimSpace(node.Text) == "" {
+					report(node, "изображение %s без альтернативного текста (опция alt=)", node.URL)
+				}
+			case "algorithm":
+				if node.Caption == "" {
+					report(node, "алгоритм без подписи \\caption")
+				}
+			case "code":
+				if node.Name != "" && node.Caption == "" {
+					report(node, "листинг без подписи caption=")
+				}
+			case "environment":
+				if (node.Name == "figure" || node.Name == "table") && !containsCaption(node.Children) {
+					report(node, "окружение %s без подписи \\caption", node.Name)
+				}
+			case "link":
+				if strings.TrimSpace(node.Text) == "" {
+					report(node, "ссылка на %s без текста", node.URL)
+				}
+			}
+			walk(node.Children)
+		}
+	}
+	walk(doc.Children)
+
+	return diagnostics
+}
+
+// containsCaption сообщает, встречается ли \caption в тексте узлов
+func containsCaption(nodes []*Node) bool {
+	for _, node := range nodes {
+		if strings.Contains(node.Text, `\caption`) || containsCaption(node.Children) {
+			return true
+		}
+	}
+	return false
+}
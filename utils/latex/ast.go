@@ -0,0 +1,672 @@
+package latex
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Position задает положение узла в исходном .tex файле (строки нумеруются с 1)
+type Position struct {
+	Line    int `json:"line"`
+	EndLine int `json:"endLine"`
+}
+
+// Node — узел модели документа.
+//
+// Блочные узлы: section, paragraph, math, algorithm, step, theorem, proof, code, environment.
+// Строчные узлы (дети paragraph): text, math, cite, ref, footnote, link, code, image
+type Node struct {
+	Type     string   `json:"type"`
+	Pos      Position `json:"pos"`
+	Name     string   `json:"name,omitempty"`     // имя окружения или вид шага алгоритма
+	Level    int      `json:"level,omitempty"`    // уровень раздела: 1 для \section
+	Text     string   `json:"text,omitempty"`     // исходный TeX текста, заголовка или шага
+	TeX      string   `json:"tex,omitempty"`      // исходный TeX формулы
+	Display  bool     `json:"display,omitempty"`  // выключная формула
+	Number   string   `json:"number,omitempty"`   // номер формулы, теоремы или раздела
+	Label    string   `json:"label,omitempty"`    // метка \label
+	Title    string   `json:"title,omitempty"`    // необязательный заголовок окружения
+	Caption  string   `json:"caption,omitempty"`  // подпись алгоритма или листинга
+	Language string   `json:"language,omitempty"` // язык листинга
+	URL      string   `json:"url,omitempty"`      // адрес ссылки или файл изображения
+	Targets  []string `json:"targets,omitempty"`  // номера источников в цитате или метки \ref
+	Children []*Node  `json:"children,omitempty"`
+}
+
+// Reference — элемент списка литературы
+type Reference struct {
+	Number int      `json:"number"`
+	Text   string   `json:"text"`
+	DOI    string   `json:"doi,omitempty"`
+	Pos    Position `json:"pos"`
+}
+
+// Document — модель разобранного LaTeX документа
+type Document struct {
+	Metadata   Metadata    `json:"metadata"`
+	Children   []*Node     `json:"children"`
+	References []Reference `json:"references,omitempty"`
+}
+
+// docParser хранит состояние разбора, общее для вложенных окружений
+type docParser struct {
+	kinds        map[string]theoremKind
+	environments map[string]TextEnvironment
+	counters     map[string]int
+	// theoremScopes — номер раздела, в котором последний раз шел счетчик теорем с [within]
+	theoremScopes map[string]string
+	equations     int
+	sections      [3]int
+}
+
+var (
+	referenceLineRe  = regexp.MustCompile(`^\d+\.\s+[A-Z]`)
+	sectionRe        = regexp.MustCompile(`^\\((?:sub){0,2})section(\*?)\s*\{`)
+	beginEnvRe       = regexp.MustCompile(`^\\begin\{([^}]+)\}`)
+	optionalArgRe    = regexp.MustCompile(`^\s*\[([^\]]*)\]`)
+	labelCommandRe   = regexp.MustCompile(`\\label\{([^}]+)\}`)
+	numericCiteRe    = regexp.MustCompile(`^\[(\d+(?:\s*[,–-]\s*\d+)*)\]`)
+	doiRe            = regexp.MustCompile(`\b10\.\d{4,9}/[^\s<>"]+[^\s<>".,;]`)
+	whitespaceRunRe  = regexp.MustCompile(`\s+`)
+	digitsRe         = regexp.MustCompile(`\d+`)
+	commandNameRe    = regexp.MustCompile(`^\\([a-zA-Z]+)`)
+	numberedMathEnvs = map[string]bool{"equation": true, "align": true, "gather": true, "multline": true}
+	mathEnvs         = map[string]bool{"equation": true, "equation*": true, "align": true, "align*": true,
+		"gather": true, "gather*": true, "multline": true, "multline*": true, "displaymath": true}
+	codeEnvs = map[string]bool{"verbatim": true, "lstlisting": true, "minted": true}
+)
+
+// ConvertLatexToAST строит модель документа и сериализует ее в JSON
+func ConvertLatexToAST(latex string, opts Options) ([]byte, error) {
+	if opts.Locale.Lang == "" {
+		opts.Locale = locales[DefaultLang]
+	}
+	return json.MarshalIndent(ParseDocument(latex, opts), "", "  ")
+}
+
+// ParseDocument строит модель документа по исходному LaTeX
+func ParseDocument(latex string, opts Options) *Document {
+	p := &docParser{
+		kinds:         parseTheoremDeclarations(stripComments(latex)),
+		environments:  opts.textEnvironments(),
+		counters:      make(map[string]int),
+		theoremScopes: make(map[string]string),
+	}
+
+	doc := &Document{Metadata: documentMetadata(stripComments(latex), opts)}
+
+	lines := strings.Split(latex, "\n")
+	start, end := 0, len(lines)
+	for i, line := range lines {
+		code, _ := stripLineComment(line)
+		if strings.Contains(code, `\begin{document}`) {
+			start = i + 1
+		}
+		if strings.Contains(code, `\end{document}`) {
+			end = i
+			break
+		}
+	}
+
+	doc.Children, doc.References = p.parseBlocks(lines[start:end], start+1)
+	return doc
+}
+
+// stripLineComment отрезает комментарий % и сообщает, был ли он в строке
+func stripLineComment(line string) (string, bool) {
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++
+		case '%':
+			return line[:i], true
+		}
+	}
+	return line, false
+}
+
+// parseBlocks разбирает строки на блочные узлы; firstLine — номер первой строки в файле
+func (p *docParser) parseBlocks(lines []string, firstLine int) ([]*Node, []Reference) {
+	var nodes []*Node
+	var paragraph []string
+	paragraphStart := 0
+
+	flush := func(endLine int) {
+		if len(paragraph) == 0 {
+			return
+		}
+		text := strings.Join(paragraph, "\n")
+		nodes = append(nodes, &Node{
+			Type:     "paragraph",
+			Pos:      Position{Line: paragraphStart, EndLine: endLine},
+			Children: parseInline(text, paragraphStart),
+		})
+		paragraph = nil
+	}
+
+	for i := 0; i < len(lines); i++ {
+		lineNo := firstLine + i
+		code, hadComment := stripLineComment(lines[i])
+		trimmed := strings.TrimSpace(code)
+
+		switch {
+		case trimmed == "":
+			// Строка из одного комментария не разрывает абзац
+			if !hadComment {
+				flush(lineNo - 1)
+			}
+
+		case referenceLineRe.MatchString(trimmed):
+			// Как и в HTML конвертере, все после первого источника считается списком литературы
+			flush(lineNo - 1)
+			return nodes, parseReferences(lines[i:], lineNo)
+
+		case sectionRe.MatchString(trimmed):
+			flush(lineNo - 1)
+			matches := sectionRe.FindStringSubmatch(trimmed)
+			title, end, _ := readBraceGroup(trimmed, len(matches[0])-1)
+			node := &Node{
+				Type:  "section",
+				Pos:   Position{Line: lineNo, EndLine: lineNo},
+				Level: len(matches[1])/3 + 1,
+				Text:  title,
+			}
+			if matches[2] == "" {
+				node.Number = p.sectionNumber(node.Level)
+			}
+			// Как и processSections, учитываем \label сразу после заголовка
+			if rest := strings.TrimSpace(trimmed[end:]); strings.HasPrefix(rest, `\label{`) {
+				node.Label = labelCommandRe.FindStringSubmatch(rest)[1]
+			}
+			nodes = append(nodes, node)
+
+		case trimmed == `\maketitle`:
+			flush(lineNo - 1)
+
+		case beginEnvRe.MatchString(trimmed):
+			flush(lineNo - 1)
+			name := beginEnvRe.FindStringSubmatch(trimmed)[1]
+			j := findEnvironmentEnd(lines, i, name)
+			nodes = append(nodes, p.parseEnvironment(name, lines[i:j+1], lineNo))
+			i = j
+
+		case strings.HasPrefix(trimmed, "$$") || strings.HasPrefix(trimmed, `\[`):
+			flush(lineNo - 1)
+			opening, closing := "$$", "$$"
+			if strings.HasPrefix(trimmed, `\[`) {
+				opening, closing = `\[`, `\]`
+			}
+
+			// Формула может закрываться на той же или на одной из следующих строк
+			j := i
+			if !strings.Contains(trimmed[len(opening):], closing) {
+				for j = i + 1; j < len(lines)-1 && !strings.Contains(lines[j], closing); j++ {
+				}
+			}
+			tex := strings.TrimPrefix(strings.TrimSpace(strings.Join(lines[i:j+1], "\n")), opening)
+			tex, _, _ = strings.Cut(tex, closing)
+			nodes = append(nodes, &Node{
+				Type:    "math",
+				Pos:     Position{Line: lineNo, EndLine: firstLine + j},
+				TeX:     strings.TrimSpace(tex),
+				Display: true,
+			})
+			i = j
+
+		default:
+			if len(paragraph) == 0 {
+				paragraphStart = lineNo
+			}
+			paragraph = append(paragraph, trimmed)
+		}
+	}
+
+	flush(firstLine + len(lines) - 1)
+	return nodes, nil
+}
+
+// sectionNumber увеличивает счетчик раздела уровня level и возвращает номер вида 1.2
+func (p *docParser) sectionNumber(level int) string {
+	p.sections[level-1]++
+	parts := make([]string, level)
+	for i := range p.sections {
+		if i >= level {
+			p.sections[i] = 0
+			continue
+		}
+		parts[i] = strconv.Itoa(p.sections[i])
+	}
+	return strings.Join(parts, ".")
+}
+
+// findEnvironmentEnd возвращает индекс строки с \end{name}, учитывая вложенность
+func findEnvironmentEnd(lines []string, start int, name string) int {
+	begin, end := `\begin{`+name+`}`, `\end{`+name+`}`
+	depth := 0
+	for j := start; j < len(lines); j++ {
+		code := lines[j]
+		if !codeEnvs[name] {
+			code, _ = stripLineComment(code)
+		}
+		depth += strings.Count(code, begin)
+		depth -= strings.Count(code, end)
+		if depth <= 0 {
+			return j
+		}
+	}
+	return len(lines) - 1
+}
+
+// parseEnvironment разбирает окружение, занимающее строки lines
+func (p *docParser) parseEnvironment(name string, lines []string, lineNo int) *Node {
+	raw := strings.Join(lines, "\n")
+	pos := Position{Line: lineNo, EndLine: lineNo + len(lines) - 1}
+
+	// Содержимое между \begin{name}[...] и последним \end{name}
+	begin := `\begin{` + name + `}`
+	innerOffset := strings.Index(raw, begin) + len(begin)
+	inner := raw[innerOffset:]
+	if idx := strings.LastIndex(inner, `\end{`+name+`}`); idx >= 0 {
+		inner = inner[:idx]
+	}
+
+	option := ""
+	if matches := optionalArgRe.FindStringSubmatch(inner); matches != nil && !mathEnvs[name] {
+		option = matches[1]
+		inner = inner[len(matches[0]):]
+		innerOffset += len(matches[0])
+	}
+	// Аргумент ширины minipage к содержимому не относится
+	if p.environments[name].Width {
+		trimmed := strings.TrimLeft(inner, " \t\n")
+		if _, end, ok := readBraceGroup(trimmed, 0); ok {
+			innerOffset += len(inner) - len(trimmed) + end
+			inner = trimmed[end:]
+		}
+	}
+	innerLine := lineNo + strings.Count(raw[:innerOffset], "\n")
+
+	switch {
+	case mathEnvs[name]:
+		node := &Node{Type: "math", Pos: pos, Name: name, Display: true}
+		inner = stripComments(inner)
+		if matches := labelCommandRe.FindStringSubmatch(inner); matches != nil {
+			node.Label = matches[1]
+			inner = labelCommandRe.ReplaceAllString(inner, "")
+		}
+		if numberedMathEnvs[name] {
+			p.equations++
+			node.Number = strconv.Itoa(p.equations)
+		}
+		node.TeX = strings.TrimSpace(inner)
+		return node
+
+	case codeEnvs[name]:
+		node := &Node{Type: "code", Pos: pos, Name: name}
+		options := parseKeyValueOptions(option)
+		node.Language = options["language"]
+		node.Caption = options["caption"]
+		if name == "minted" {
+			if arg, end, ok := readBraceGroup(inner, 0); ok {
+				node.Language = arg
+				inner = inner[end:]
+			}
+		}
+		node.Text = trimCodeNewlines(inner)
+		return node
+
+	case name == "algorithm" || name == "algorithm*":
+		node := &Node{Type: "algorithm", Pos: pos, Name: name}
+		node.Caption, node.Children = parseAlgorithmSteps(stripComments(inner), innerLine)
+		return node
+
+	case name == "proof":
+		children, _ := p.parseBlocks(strings.Split(inner, "\n"), innerLine)
+		return &Node{Type: "proof", Pos: pos, Name: name, Title: option, Children: children}
+
+	default:
+		node := &Node{Type: "environment", Pos: pos, Name: name, Title: option}
+		if kind, ok := p.kinds[name]; ok {
+			node.Type = "theorem"
+			node.Text = kind.Title
+			if kind.Numbered {
+				node.Number = theoremNumber(kind, p.counters, p.theoremScopes, p.sections)
+			}
+			// Метки вложенных окружений (формул) к теореме не относятся
+			loc := labelCommandRe.FindStringSubmatchIndex(inner)
+			nested := strings.Index(inner, `\begin{`)
+			if loc != nil && (nested < 0 || loc[0] < nested) {
+				node.Label = inner[loc[2]:loc[3]]
+				inner = inner[:loc[0]] + inner[loc[1]:]
+			}
+		}
+		node.Children, _ = p.parseBlocks(strings.Split(inner, "\n"), innerLine)
+		return node
+	}
+}
+
+// algorithmCommands сопоставляет команды algorithm2e видам шагов
+var algorithmCommands = map[string]string{
+	"KwIn":     "input",
+	"KwData":   "input",
+	"KwOut":    "output",
+	"KwResult": "output",
+	"KwRet":    "return",
+	"Return":   "return",
+	"For":      "for",
+	"ForEach":  "foreach",
+	"While":    "while",
+	"If":       "if",
+	"ElseIf":   "elseif",
+	"Else":     "else",
+	"tcp":      "comment",
+	"tcc":      "comment",
+}
+
+// blockAlgorithmSteps перечисляет шаги, у которых после условия идет тело {…}
+var blockAlgorithmSteps = map[string]bool{"for": true, "foreach": true, "while": true, "if": true, "elseif": true}
+
+var algorithmCommandRe = regexp.MustCompile(`^\$?\\(caption|KwIn|KwData|KwOut|KwResult|KwRet|Return|ForEach|For|While|ElseIf|If|Else|tcp|tcc)\s*\{`)
+
+// parseAlgorithmSteps разбирает тело algorithm2e на структурированные шаги
+func parseAlgorithmSteps(src string, firstLine int) (string, []*Node) {
+	var caption string
+	var steps []*Node
+
+	lineAt := func(offset int) int {
+		return firstLine + strings.Count(src[:offset], "\n")
+	}
+
+	for pos := 0; pos < len(src); {
+		// Пропускаем пробелы и разделители строк \\
+		if c := src[pos]; c == ' ' || c == '\t' || c == '\n' || c == '\r' {
+			pos++
+			continue
+		}
+		if strings.HasPrefix(src[pos:], `\\`) {
+			pos += 2
+			continue
+		}
+
+		start := pos
+		if matches := algorithmCommandRe.FindStringSubmatch(src[pos:]); matches != nil {
+			open := pos + len(matches[0]) - 1
+			arg, end, ok := readBraceGroup(src, open)
+			if ok {
+				pos = end
+				// Закрывающий $ у конструкции вида $\KwIn{...}$
+				if strings.HasPrefix(matches[0], "$") && pos < len(src) && src[pos] == '$' {
+					pos++
+				}
+
+				if matches[1] == "caption" {
+					caption = strings.TrimSpace(arg)
+					continue
+				}
+
+				kind := algorithmCommands[matches[1]]
+				step := &Node{Type: "step", Name: kind, Text: strings.TrimSpace(arg)}
+
+				if kind == "else" {
+					// У \Else единственный аргумент — тело
+					step.Text = ""
+					_, step.Children = parseAlgorithmSteps(arg, lineAt(open))
+				} else if blockAlgorithmSteps[kind] {
+					for pos < len(src) && strings.ContainsRune(" \t\r\n", rune(src[pos])) {
+						pos++
+					}
+					if body, bodyEnd, ok := readBraceGroup(src, pos); ok {
+						_, step.Children = parseAlgorithmSteps(body, lineAt(pos))
+						pos = bodyEnd
+					}
+				}
+
+				step.Pos = Position{Line: lineAt(start), EndLine: lineAt(pos)}
+				steps = append(steps, step)
+				continue
+			}
+		}
+
+		// Обычная инструкция: до конца строки или \\ вне фигурных скобок и формул
+		depth, inMath := 0, false
+	scan:
+		for pos < len(src) {
+			switch {
+			case src[pos] == '\\' && pos+1 < len(src) && src[pos+1] == '\\' && depth == 0 && !inMath:
+				break scan
+			case src[pos] == '\\':
+				pos += 2
+				continue
+			case src[pos] == '$':
+				inMath = !inMath
+			case src[pos] == '{':
+				depth++
+			case src[pos] == '}':
+				depth--
+			case src[pos] == '\n' && depth <= 0 && !inMath:
+				break scan
+			}
+			pos++
+		}
+		if pos > len(src) {
+			pos = len(src)
+		}
+
+		text := strings.TrimSpace(src[start:pos])
+		if text == "" || strings.HasPrefix(text, `\begin`) || strings.HasPrefix(text, `\end`) || text == "}" {
+			continue
+		}
+
+		step := &Node{Type: "step", Name: "statement", Text: text, Pos: Position{Line: lineAt(start), EndLine: lineAt(pos)}}
+		if strings.HasPrefix(text, `\textbf{Init:}`) {
+			step.Name = "init"
+			step.Text = strings.TrimSpace(strings.TrimPrefix(strings.TrimPrefix(text, `\textbf{Init:}`), `\quad`))
+		}
+		steps = append(steps, step)
+	}
+
+	return caption, steps
+}
+
+// parseReferences разбирает список литературы в конце документа
+func parseReferences(lines []string, firstLine int) []Reference {
+	var references []Reference
+
+	for i := 0; i < len(lines); i++ {
+		code, _ := stripLineComment(lines[i])
+		trimmed := strings.TrimSpace(code)
+		if !referenceLineRe.MatchString(trimmed) {
+			continue
+		}
+
+		ref := Reference{Pos: Position{Line: firstLine + i, EndLine: firstLine + i}}
+		number, text, _ := strings.Cut(trimmed, ".")
+		ref.Number, _ = strconv.Atoi(number)
+
+		// Многострочный источник продолжается до пустой строки или следующего номера
+		text = strings.TrimSpace(text)
+		for j := i + 1; j < len(lines); j++ {
+			next := strings.TrimSpace(lines[j])
+			if next == "" || referenceLineRe.MatchString(next) || strings.HasPrefix(next, `\end{document}`) {
+				break
+			}
+			text += " " + next
+			ref.Pos.EndLine = firstLine + j
+			i = j
+		}
+
+		ref.Text = text
+		ref.DOI = doiRe.FindString(text)
+		references = append(references, ref)
+	}
+
+	return references
+}
+
+// parseInline разбирает текст абзаца на строчные узлы
+func parseInline(text string, firstLine int) []*Node {
+	var nodes []*Node
+	var buf strings.Builder
+	bufStart := 0
+
+	lineAt := func(offset int) int {
+		return firstLine + strings.Count(text[:offset], "\n")
+	}
+	flush := func(end int) {
+		if buf.Len() == 0 {
+			return
+		}
+		chunk := whitespaceRunRe.ReplaceAllString(buf.String(), " ")
+		nodes = append(nodes, &Node{Type: "text", Text: chunk, Pos: Position{Line: lineAt(bufStart), EndLine: lineAt(end)}})
+		buf.Reset()
+	}
+	add := func(node *Node, start, end int) {
+		flush(start)
+		node.Pos = Position{Line: lineAt(start), EndLine: lineAt(end)}
+		nodes = append(nodes, node)
+	}
+
+	for i := 0; i < len(text); {
+		rest := text[i:]
+
+		switch {
+		case strings.HasPrefix(rest, `\$`):
+			// Экранированный знак доллара остается текстом
+			if buf.Len() == 0 {
+				bufStart = i
+			}
+			buf.WriteString(rest[:2])
+			i += 2
+			continue
+
+		case strings.HasPrefix(rest, "$$") || strings.HasPrefix(rest, `\[`) ||
+			strings.HasPrefix(rest, "$") || strings.HasPrefix(rest, `\(`):
+			n := protectedSpanLength(rest)
+			open, closing := 1, "$"
+			switch {
+			case strings.HasPrefix(rest, "$$"):
+				open, closing = 2, "$$"
+			case strings.HasPrefix(rest, `\(`):
+				open, closing = 2, `\)`
+			case strings.HasPrefix(rest, `\[`):
+				open, closing = 2, `\]`
+			}
+			// Незакрытая формула остается текстом
+			if n < open+len(closing) || !strings.HasSuffix(rest[:n], closing) {
+				break
+			}
+			add(&Node{
+				Type:    "math",
+				TeX:     strings.TrimSpace(rest[open : n-len(closing)]),
+				Display: strings.HasPrefix(rest, "$$") || strings.HasPrefix(rest, `\[`),
+			}, i, i+n)
+			i += n
+			continue
+
+		case numericCiteRe.MatchString(rest):
+			match := numericCiteRe.FindStringSubmatch(rest)
+			var targets []string
+			for _, number := range digitsRe.FindAllString(match[1], -1) {
+				targets = append(targets, number)
+			}
+			add(&Node{Type: "cite", Targets: targets}, i, i+len(match[0]))
+			i += len(match[0])
+			continue
+
+		case strings.HasPrefix(rest, `\`):
+			if node, n := parseInlineCommand(rest, lineAt(i)); node != nil {
+				add(node, i, i+n)
+				i += n
+				continue
+			}
+		}
+
+		if buf.Len() == 0 {
+			bufStart = i
+		}
+		buf.WriteByte(text[i])
+		i++
+	}
+	flush(len(text))
+
+	return nodes
+}
+
+// parseInlineCommand разбирает строчную команду в начале s и возвращает узел и длину команды
+func parseInlineCommand(s string, line int) (*Node, int) {
+	name := commandNameRe.FindStringSubmatch(s)
+	if name == nil {
+		return nil, 0
+	}
+
+	// Аргументы в фигурных скобках, следующие сразу за командой
+	args := func(count int) ([]string, int) {
+		pos := len(name[0])
+		var result []string
+		for i := 0; i < count; i++ {
+			for pos < len(s) && s[pos] == ' ' {
+				pos++
+			}
+			arg, end, ok := readBraceGroup(s, pos)
+			if !ok {
+				return nil, 0
+			}
+			result = append(result, arg)
+			pos = end
+		}
+		return result, pos
+	}
+
+	switch name[1] {
+	case "cite":
+		if a, n := args(1); a != nil {
+			var targets []string
+			for _, key := range strings.Split(a[0], ",") {
+				targets = append(targets, strings.TrimSpace(key))
+			}
+			return &Node{Type: "cite", Targets: targets}, n
+		}
+	case "ref", "eqref":
+		if a, n := args(1); a != nil {
+			return &Node{Type: "ref", Name: name[1], Targets: []string{strings.TrimSpace(a[0])}}, n
+		}
+	case "footnote":
+		if a, n := args(1); a != nil {
+			return &Node{Type: "footnote", Children: parseInline(a[0], line)}, n
+		}
+	case "url":
+		if a, n := args(1); a != nil {
+			return &Node{Type: "link", URL: unescapeURL(a[0]), Text: a[0]}, n
+		}
+	case "href":
+		if a, n := args(2); a != nil {
+			return &Node{Type: "link", URL: unescapeURL(a[0]), Text: a[1]}, n
+		}
+	case "includegraphics":
+		pos := len(name[0])
+		options := ""
+		if matches := optionalArgRe.FindStringSubmatch(s[pos:]); matches != nil {
+			options = matches[1]
+			pos += len(matches[0])
+		}
+		for pos < len(s) && s[pos] == ' ' {
+			pos++
+		}
+		if file, end, ok := readBraceGroup(s, pos); ok {
+			// Альтернативный текст изображения хранится в Text
+			return &Node{Type: "image", URL: strings.TrimSpace(file), Text: parseKeyValueOptions(options)["alt"]}, end
+		}
+	case "verb", "lstinline":
+		pos := len(name[0])
+		if pos < len(s) {
+			delimiter := s[pos]
+			if end := strings.IndexByte(s[pos+1:], delimiter); end >= 0 {
+				return &Node{Type: "code", Text: s[pos+1 : pos+1+end]}, pos + end + 2
+			}
+		}
+	}
+
+	return nil, 0
+}
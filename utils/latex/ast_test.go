@@ -0,0 +1,187 @@
+package latex
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// outline описывает узлы одной строкой каждый: тип, имя, строки и поля, заданные у узла
+func outline(nodes []*Node) []string {
+	var result []string
+	for _, node := range nodes {
+		line := fmt.Sprintf("%s %d-%d", node.Type, node.Pos.Line, node.Pos.EndLine)
+		for _, field := range [][2]string{{"name", node.Name}, {"number", node.Number}, {"label", node.Label},
+			{"text", node.Text}, {"tex", node.TeX}, {"url", node.URL}, {"targets", strings.Join(node.Targets, ",")}} {
+			if field[1] != "" {
+				line += " " + field[0] + "=" + field[1]
+			}
+		}
+		result = append(result, line)
+	}
+	return result
+}
+
+func TestParseDocumentBlocks(t *testing.T) {
+	tests := []struct {
+		name, body string
+		want       []string
+	}{
+		{
+			"разделы с номерами и метками",
+			"\\section{Введение}\\label{sec:intro}\n\\subsection{Задача}\n\\subsection*{Без номера}\n\\section{Итоги}\n\\subsection{Вывод}",
+			[]string{
+				"section 3-3 number=1 label=sec:intro text=Введение",
+				"section 4-4 number=1.1 text=Задача",
+				"section 5-5 text=Без номера",
+				"section 6-6 number=2 text=Итоги",
+				"section 7-7 number=2.1 text=Вывод",
+			},
+		},
+		{
+			"абзацы разделяются пустой строкой, но не комментарием",
+			"Первый\n% комментарий\nабзац.\n\nВторой.",
+			[]string{"paragraph 3-5", "paragraph 7-7"},
+		},
+		{
+			"выключные формулы",
+			"\\begin{equation}\\label{eq:a}\nE = mc^2\n\\end{equation}\n$$\na+b\n$$\n\\[c\\]\n\\begin{align*}\nx\n\\end{align*}\n\\begin{gather}\ny\n\\end{gather}",
+			[]string{
+				"math 3-5 name=equation number=1 label=eq:a tex=E = mc^2",
+				"math 6-8 tex=a+b",
+				"math 9-9 tex=c",
+				"math 10-12 name=align* tex=x",
+				"math 13-15 name=gather number=2 tex=y",
+			},
+		},
+		{
+			"листинг сохраняет процент",
+			"\\begin{lstlisting}[language=Go]\nx := 1 % не комментарий\n\\end{lstlisting}",
+			[]string{"code 3-5 name=lstlisting text=x := 1 % не комментарий"},
+		},
+		{
+			"алгоритм",
+			"\\begin{algorithm}\n\\caption{Поиск}\n\\KwIn{граф}\n\\Return{путь}\n\\end{algorithm}",
+			[]string{"algorithm 3-7 name=algorithm"},
+		},
+		{
+			"вложенное окружение",
+			"\\begin{itemize}\n\\begin{itemize}\nа\n\\end{itemize}\n\\end{itemize}\nпосле",
+			[]string{"environment 3-7 name=itemize", "paragraph 8-8"},
+		},
+		{
+			"доказательство",
+			"\\begin{proof}[Идея]\nОчевидно.\n\\end{proof}",
+			[]string{"proof 3-5 name=proof"},
+		},
+		{
+			"\\maketitle пропускается",
+			"\\maketitle\nТекст.",
+			[]string{"paragraph 4-4"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			doc := ParseDocument(document(test.body), Options{Locale: locales["ru"]})
+			if got := outline(doc.Children); !slices.Equal(got, test.want) {
+				t.Errorf("получено\n%s\nожидалось\n%s", strings.Join(got, "\n"), strings.Join(test.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestParseDocumentChildren(t *testing.T) {
+	tests := []struct {
+		name, body string
+		want       []string
+	}{
+		{
+			"шаги алгоритма",
+			"\\begin{algorithm}\n\\caption{Поиск}\n\\KwIn{граф}\n\\For{$i$}{шаг}\n\\end{algorithm}",
+			[]string{"step 5-5 name=input text=граф", "step 6-6 name=for text=$i$"},
+		},
+		{
+			"абзац доказательства",
+			"\\begin{proof}\n\nОчевидно.\n\\end{proof}",
+			[]string{"paragraph 5-5"},
+		},
+		{
+			"теорема без вложенной метки",
+			"\\newtheorem{theorem}{Теорема}\n\\begin{theorem}\\label{thm:a}\nТекст\n\\begin{equation}\\label{eq:b}\nx\n\\end{equation}\n\\end{theorem}",
+			[]string{"paragraph 5-5", "math 6-8 name=equation number=1 label=eq:b tex=x"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			doc := ParseDocument(document(test.body), Options{Locale: locales["ru"]})
+			block := doc.Children[len(doc.Children)-1]
+			if got := outline(block.Children); !slices.Equal(got, test.want) {
+				t.Errorf("получено\n%s\nожидалось\n%s", strings.Join(got, "\n"), strings.Join(test.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestParseInline(t *testing.T) {
+	tests := []struct {
+		name, text string
+		want       []string
+	}{
+		{"формулы", `a $x$ b \(y\) $$z$$`, []string{"text 1-1 text=a ", "math 1-1 tex=x", "text 1-1 text= b ",
+			"math 1-1 tex=y", "text 1-1 text= ", "math 1-1 tex=z"}},
+		{"экранированный доллар", `цена \$5`, []string{`text 1-1 text=цена \$5`}},
+		{"цитаты", `\cite{a, b} и [1, 3–5]`, []string{"cite 1-1 targets=a,b", "text 1-1 text= и ", "cite 1-1 targets=1,3,5"}},
+		{"ссылки на метки", `\ref{sec:a} \eqref{eq:b}`, []string{"ref 1-1 name=ref targets=sec:a", "text 1-1 text= ",
+			"ref 1-1 name=eqref targets=eq:b"}},
+		{"адреса", `\url{https://e.org/a\_b} \href{https://e.org}{сайт}`, []string{`link 1-1 text=https://e.org/a\_b url=https://e.org/a_b`,
+			"text 1-1 text= ", "link 1-1 text=сайт url=https://e.org"}},
+		{"изображение", `\includegraphics[width=5cm, alt=Схема]{fig.png}`, []string{"image 1-1 text=Схема url=fig.png"}},
+		{"код", `\verb|a{b| \lstinline!c!`, []string{"code 1-1 text=a{b", "text 1-1 text= ", "code 1-1 text=c"}},
+		{"сноска", `a\footnote{b $c$}`, []string{"text 1-1 text=a", "footnote 1-1"}},
+		{"строки многострочного текста", "a\nb $x$\n\nc", []string{"text 1-2 text=a b ", "math 2-2 tex=x", "text 2-4 text= c"}},
+		{"незавершенная формула остается текстом", "a $b", []string{"text 1-1 text=a $b"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := outline(parseInline(test.text, 1)); !slices.Equal(got, test.want) {
+				t.Errorf("получено\n%s\nожидалось\n%s", strings.Join(got, "\n"), strings.Join(test.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestParseReferences(t *testing.T) {
+	doc := ParseDocument(document("Текст [1].\n\n1. Author A. Title.\nJournal, 2020. doi 10.1000/xyz123.\n2. Second B. Book."), Options{})
+	want := []Reference{
+		{Number: 1, Text: "Author A. Title. Journal, 2020. doi 10.1000/xyz123.", DOI: "10.1000/xyz123", Pos: Position{5, 6}},
+		{Number: 2, Text: "Second B. Book.", Pos: Position{7, 7}},
+	}
+	if !reflect.DeepEqual(doc.References, want) {
+		t.Errorf("получено %+v, ожидалось %+v", doc.References, want)
+	}
+	if got := outline(doc.Children); !slices.Equal(got, []string{"paragraph 3-3"}) {
+		t.Errorf("блоки до списка литературы: %v", got)
+	}
+}
+
+func TestConvertLatexToAST(t *testing.T) {
+	src := document("\\section{Введение}\nТекст $x$ \\cite{1}.\n\n1. Author A. Title.")
+	data, err := ConvertLatexToAST(src, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got Document
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("вывод не разбирается как JSON: %v", err)
+	}
+	want := ParseDocument(src, Options{Locale: locales[DefaultLang]})
+	if !reflect.DeepEqual(&got, want) {
+		t.Errorf("JSON не совпадает с моделью документа:\n%s", data)
+	}
+	if got.Metadata.Lang != DefaultLang {
+		t.Errorf("язык %q, ожидался %q", got.Metadata.Lang, DefaultLang)
+	}
+}
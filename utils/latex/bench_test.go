@@ -0,0 +1,108 @@
+package latex
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+// Бенчмарки конвертации синтетического документа из benchmarkPages страниц:
+//
+//	go test -bench Convert -benchmem ./utils/latex
+const benchmarkPages = 50
+
+// syntheticDocument строит документ примерно из pages страниц, в котором встречаются
+// все обрабатываемые конструкции: разделы, формулы со ссылками, теоремы, алгоритмы,
+// сноски, листинги и список литературы
+func syntheticDocument(pages int) string {
+	var doc strings.Builder
+	doc.WriteString(`\documentclass{article}
+\usepackage[russian]{babel}
+\newtheorem{theorem}{Теорема}
+\title{Синтетический документ}
+\author{Бенчмарк}
+\begin{document}
+\maketitle
+
+`)
+
+	for page := 1; page <= pages; page++ {
+		fmt.Fprintf(&doc, `\section{Раздел %d}\label{sec:%d}
+Муравьи откладывают феромон на ребрах графа -- его концентрация $\tau_{ij}$ убывает со временем "быстро"\footnote{Сноска к разделу %d.}.
+Вероятность перехода задается формулой \eqref{eq:%d}, а оценка сходимости дана в теореме \ref{thm:%d}.
+
+\begin{equation}\label{eq:%d}
+p_{ij} = \frac{\tau_{ij}^\alpha \eta_{ij}^\beta}{\sum_{k \in N_i} \tau_{ik}^\alpha \eta_{ik}^\beta}
+\end{equation}
+
+\subsection{Свойства}
+\begin{theorem}[Сходимость]\label{thm:%d}
+При $\rho \in (0, 1)$ значения $\tau_{ij}$ ограничены сверху величиной $\frac{1}{\rho L^*}$.
+\end{theorem}
+
+\begin{algorithm}
+\caption{Итерация %d}
+\KwIn{граф $G = (V, E)$}
+\KwOut{маршрут $T$}
+\For{$k = 1, \dots, m$}{
+  построить маршрут $T_k$\;
+  \If{$L(T_k) < L(T)$}{
+    $T \gets T_k$\;
+  }
+}
+\Return{$T$}
+\end{algorithm}
+
+\begin{lstlisting}[language=Go, caption={Обновление феромона}]
+for i := range tau {
+	tau[i] *= 1 - rho // 50%% испаряется
+}
+\end{lstlisting}
+
+Подробности см. на \href{https://example.com/aco}{странице проекта} и в \url{https://example.com/%d}.
+
+`, page, page, page, page, page, page, page, page, page)
+	}
+
+	doc.WriteString("\\section*{Список литературы}\n")
+	for i := 1; i <= pages; i++ {
+		fmt.Fprintf(&doc, "%d. Dorigo M., Stützle T. Ant Colony Optimization. MIT Press, 2004. doi:10.7551/mitpress/%d.001.0001\n", i, 1290+i)
+	}
+	doc.WriteString("\\end{document}\n")
+
+	return doc.String()
+}
+
+func BenchmarkConvertHTML(b *testing.B) {
+	benchmarkConvert(b, func(latex string, opts Options) error {
+		_, err := ConvertLatexToHTML(latex, opts)
+		return err
+	})
+}
+
+func BenchmarkConvertStream(b *testing.B) {
+	benchmarkConvert(b, func(latex string, opts Options) error {
+		_, err := ConvertLatexStream(strings.NewReader(latex), io.Discard, opts)
+		return err
+	})
+}
+
+func BenchmarkConvertMarkdown(b *testing.B) {
+	benchmarkConvert(b, func(latex string, opts Options) error {
+		_, err := ConvertLatexToMarkdown(latex, opts)
+		return err
+	})
+}
+
+func benchmarkConvert(b *testing.B, convert func(latex string, opts Options) error) {
+	latex := syntheticDocument(benchmarkPages)
+	opts := Options{Locale: locales[DefaultLang]}
+	b.SetBytes(int64(len(latex)))
+	b.ReportAllocs()
+	for b.Loop() {
+		if err := convert(latex, opts); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
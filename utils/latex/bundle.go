@@ -0,0 +1,175 @@
+package latex
+
+import (
+	"encoding/base64"
+	"fmt"
+	"html"
+	"log"
+	"mime"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// mathJaxScript — компонент MathJax, который подключает generateHTML
+const mathJaxScript = "tex-svg.js"
+
+// imageExtensions перебираются, если в \includegraphics расширение не указано
+var imageExtensions = []string{".svg", ".png", ".jpg", ".jpeg", ".gif"}
+
+var (
+	graphicsRe       = regexp.MustCompile(`\\includegraphics\s*(?:\[([^\]]*)\])?\s*\{([^}]+)\}`)
+	relativeLengthRe = regexp.MustCompile(`^([\d.]*)\s*\\(?:textwidth|linewidth|columnwidth)$`)
+	stylesheetLinkRe = regexp.MustCompile(`<link\s+[^>]*rel="stylesheet"[^>]*href="([^"]+)"[^>]*>`)
+	imageSourceRe    = regexp.MustCompile(`(<img\s+[^>]*src=")([^"]+)(")`)
+	mathJaxScriptRe  = regexp.MustCompile(`<script[^>]*src="[^"]*` + regexp.QuoteMeta(mathJaxScript) + `"[^>]*></script>`)
+	cssURLRe         = regexp.MustCompile(`url\(\s*['"]?([^'")]+)['"]?\s*\)`)
+)
+
+// processGraphics заменяет \includegraphics[...]{file} изображением
+func processGraphics(content, baseDir string) string {
+	return graphicsRe.ReplaceAllStringFunc(content, func(match string) string {
+		matches := graphicsRe.FindStringSubmatch(match)
+		options := parseKeyValueOptions(matches[1])
+		src := resolveImagePath(strings.TrimSpace(matches[2]), baseDir)
+
+		style := ""
+		if width := options["width"]; width != "" {
+			style = ` style="width: ` + latexLengthToCSS(width) + `"`
+		}
+
+		return `<img src="` + html.EscapeString(src) + `" alt="` + html.EscapeString(options["alt"]) + `" class="graphics"` + style + `>`
+	})
+}
+
+// resolveImagePath подбирает расширение файла, если оно не указано
+func resolveImagePath(path, baseDir string) string {
+	if filepath.Ext(path) != "" {
+		return path
+	}
+	for _, ext := range imageExtensions {
+		if _, err := os.Stat(filepath.Join(baseDir, path+ext)); err == nil {
+			return path + ext
+		}
+	}
+	return path
+}
+
+// latexLengthToCSS переводит длины вида 0.5\textwidth в проценты, остальные оставляет как есть
+func latexLengthToCSS(length string) string {
+	if matches := relativeLengthRe.FindStringSubmatch(strings.TrimSpace(length)); matches != nil {
+		factor := 1.0
+		if matches[1] != "" {
+			fmt.Sscanf(matches[1], "%g", &factor)
+		}
+		return fmt.Sprintf("%g%%", factor*100)
+	}
+	return strings.TrimSpace(length)
+}
+
+// bundleHTML делает страницу автономной: встраивает таблицы стилей, изображения
+// и, если задан mathJaxDir, скрипт MathJax
+func bundleHTML(page, baseDir, mathJaxDir string) (string, error) {
+	var bundleErr error
+	fail := func(err error) {
+		if bundleErr == nil {
+			bundleErr = err
+		}
+	}
+
+	page = stylesheetLinkRe.ReplaceAllStringFunc(page, func(match string) string {
+		href := html.UnescapeString(stylesheetLinkRe.FindStringSubmatch(match)[1])
+		if isRemote(href) {
+			log.Printf("Предупреждение: внешняя таблица стилей не встроена: %s", href)
+			return match
+		}
+		path := filepath.Join(baseDir, href)
+		css, err := os.ReadFile(path)
+		if err != nil {
+			fail(fmt.Errorf("чтение таблицы стилей: %w", err))
+			return match
+		}
+		return "<style>\n" + inlineCSSURLs(string(css), filepath.Dir(path), fail) + "\n</style>"
+	})
+
+	page = imageSourceRe.ReplaceAllStringFunc(page, func(match string) string {
+		matches := imageSourceRe.FindStringSubmatch(match)
+		src := html.UnescapeString(matches[2])
+		if isRemote(src) || strings.HasPrefix(src, "data:") {
+			return match
+		}
+		uri, err := dataURI(filepath.Join(baseDir, src))
+		if err != nil {
+			fail(err)
+			return match
+		}
+		return matches[1] + uri + matches[3]
+	})
+
+	if mathJaxDir == "" {
+		if mathJaxScriptRe.MatchString(page) {
+			log.Printf("Предупреждение: MathJax подключается из CDN; для работы без сети укажите -mathjax-dir")
+		}
+		return page, bundleErr
+	}
+
+	script, err := readMathJax(mathJaxDir)
+	if err != nil {
+		return "", err
+	}
+	page = mathJaxScriptRe.ReplaceAllLiteralString(page, "<script>\n"+script+"\n</script>")
+
+	return page, bundleErr
+}
+
+// readMathJax читает компонент MathJax из каталога дистрибутива (корня пакета или es5/)
+func readMathJax(dir string) (string, error) {
+	for _, path := range []string{filepath.Join(dir, mathJaxScript), filepath.Join(dir, "es5", mathJaxScript)} {
+		data, err := os.ReadFile(path)
+		if err == nil {
+			// Последовательность </script внутри встроенного скрипта закрыла бы тег раньше времени
+			return strings.ReplaceAll(string(data), "</script", `<\/script`), nil
+		}
+		if !os.IsNotExist(err) {
+			return "", fmt.Errorf("чтение MathJax: %w", err)
+		}
+	}
+	return "", fmt.Errorf("в каталоге %s не найден %s", dir, mathJaxScript)
+}
+
+// inlineCSSURLs заменяет url(...) локальных файлов в CSS на data URI
+func inlineCSSURLs(css, cssDir string, fail func(error)) string {
+	return cssURLRe.ReplaceAllStringFunc(css, func(match string) string {
+		ref := cssURLRe.FindStringSubmatch(match)[1]
+		if isRemote(ref) || strings.HasPrefix(ref, "data:") || strings.HasPrefix(ref, "#") {
+			return match
+		}
+		uri, err := dataURI(filepath.Join(cssDir, ref))
+		if err != nil {
+			fail(err)
+			return match
+		}
+		return `url("` + uri + `")`
+	})
+}
+
+// dataURI кодирует файл в data URI с типом по расширению
+func dataURI(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("чтение ресурса: %w", err)
+	}
+
+	mimeType := mime.TypeByExtension(strings.ToLower(filepath.Ext(path)))
+	if mimeType == "" {
+		mimeType = "application/octet-stream"
+	}
+
+	return "data:" + mimeType + ";base64," + base64.StdEncoding.EncodeToString(data), nil
+}
+
+// isRemote сообщает, указывает ли адрес на внешний ресурс
+func isRemote(ref string) bool {
+	return strings.HasPrefix(ref, "http://") || strings.HasPrefix(ref, "https://") || strings.HasPrefix(ref, "//")
+}
@@ -0,0 +1,1082 @@
+// Package latex конвертирует LaTeX описания алгоритмов в HTML страницы сайта, Markdown
+// и JSON модель документа и строит поисковый индекс страниц. Команды и окружения
+// добавляются через RegisterCommand и RegisterEnvironment, текстовые окружения —
+// через Options.TextEnvironments. Командная строка конвертера — в каталоге utils
+package latex
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Регулярные выражения компилируются один раз при запуске, а не на каждом вызове
+var (
+	beginDocumentRe        = regexp.MustCompile(`\\begin\{document\}`)
+	endDocumentRe          = regexp.MustCompile(`\\end\{document\}`)
+	algorithmMathRe        = regexp.MustCompile(`\$\$.+?\$\$|\$[^$]+\$`)
+	algorithmLineBreakRe   = regexp.MustCompile(`\$[^$]*\$|\\\\`)
+	statementSeparatorRe   = regexp.MustCompile(`;\s*`)
+	textbfRe               = regexp.MustCompile(`\\textbf\{([^}]+)\}`)
+	textitRe               = regexp.MustCompile(`\\textit\{([^}]+)\}`)
+	textRe                 = regexp.MustCompile(`\\text\{([^}]+)\}`)
+	emphRe                 = regexp.MustCompile(`\\emph\{([^}]+)\}`)
+	subscriptRe            = regexp.MustCompile(`([a-zA-Z])_([a-zA-Z0-9]+)([^{]|$)`)
+	superscriptRe          = regexp.MustCompile(`([a-zA-Z])\^([a-zA-Z0-9]+)([^{]|$)`)
+	displayMathParagraphRe = regexp.MustCompile(`<p>\$\$([^$]+)\$\$</p>`)
+	referenceNumberRe      = regexp.MustCompile(`^\d+\.\s*`)
+)
+
+// Options задает параметры конвертации
+type Options struct {
+	Title  string
+	Locale Locale
+
+	// BaseDir — каталог, относительно которого ищутся изображения и стили
+	BaseDir string
+
+	// Bundle включает сборку автономной страницы без обращений к сети
+	Bundle     bool
+	MathJaxDir string
+
+	// DevOverlay добавляет на страницу подсказку «файл:строка» над блоками.
+	// По щелчку подсказка открывает редактор по шаблону EditorURL с {path} и {line}
+	DevOverlay bool
+	SourcePath string
+	EditorURL  string
+
+	// TextEnvironments дополняют встроенный реестр текстовых окружений
+	TextEnvironments map[string]TextEnvironment
+
+	// Params — описания параметров симуляции для подсказок \param{имя}
+	Params map[string]ParamSpec
+}
+
+// textEnvironments возвращает встроенный реестр текстовых окружений вместе с TextEnvironments
+func (opts Options) textEnvironments() map[string]TextEnvironment {
+	environments := defaultTextEnvironments(opts.Locale)
+	for name, env := range opts.TextEnvironments {
+		environments[name] = env
+	}
+	return environments
+}
+
+// Result содержит результат конвертации
+type Result struct {
+	HTML     string
+	Metadata Metadata
+
+	// SourceMap — строки исходника для блоков с атрибутом data-src-line, по порядку в тексте
+	SourceMap []SourceBlock
+
+	// Diagnostics — замечания к исходнику, например неизвестные окружения
+	Diagnostics []Diagnostic
+}
+
+// ConvertLatexToHTML конвертирует LaTeX контент в HTML с поддержкой MathJax
+func ConvertLatexToHTML(latex string, opts Options) (Result, error) {
+	if opts.Locale.Lang == "" {
+		opts.Locale = locales[DefaultLang]
+	}
+
+	// Код защищаем до удаления комментариев: в нем % не начинает комментарий
+	protected, _ := protectVerbatim(latex)
+	protected = stripComments(protected)
+
+	// Метаданные берем из всего документа: \title может стоять и после \begin{document}
+	meta := documentMetadata(protected, opts)
+
+	// Теоремоподобные окружения объявляются в преамбуле через \newtheorem
+	converter := newHTMLConverter(opts, meta, parseTheoremDeclarations(protected))
+	converter.hasTitleBlock = strings.Contains(extractDocumentContent(protected), `\maketitle`)
+
+	// Тело конвертируется по блокам, как и в потоковом режиме, чтобы знать строки исходника
+	in := bufio.NewReader(strings.NewReader(latex))
+	_, body, line, err := readPreamble(in)
+	if err != nil {
+		return Result{}, err
+	}
+	var content strings.Builder
+	if err := converter.convertBody(io.MultiReader(strings.NewReader(body), in), line, func(html string) {
+		content.WriteString(html)
+	}); err != nil {
+		return Result{}, err
+	}
+	converter.warnUnresolved()
+
+	// Ссылки вперед по тексту известны только после обработки всего документа
+	html := generateHTML(resolveForwardRefs(content.String(), converter.state),
+		extractReferences(converter.references.String()), converter.state.footnotes, meta, opts)
+
+	if opts.Bundle {
+		bundled, err := bundleHTML(html, opts.BaseDir, opts.MathJaxDir)
+		if err != nil {
+			return Result{}, fmt.Errorf("сборка автономной страницы: %w", err)
+		}
+		html = bundled
+	}
+
+	return Result{HTML: html, Metadata: meta, SourceMap: converter.sourceMap, Diagnostics: converter.diagnostics}, nil
+}
+
+// documentState — счетчики и метки, общие для всех блоков документа
+type documentState struct {
+	labels     map[string]string // метка -> номер формулы, теоремы или раздела
+	unresolved map[string]bool   // метки из \ref, не объявленные к моменту ссылки
+
+	counters map[string]int // формулы, алгоритмы, теоремы и счетчики обработчиков из реестра
+	// theoremScopes — номер раздела, в котором последний раз шел счетчик теорем с [within]
+	theoremScopes map[string]string
+	headings      int
+	headingLevel  int // уровень последнего заголовка; h1 — заголовок документа
+	sections      [3]int
+
+	kinds     map[string]theoremKind
+	theoremRe *regexp.Regexp
+
+	footnotes []string
+}
+
+// newDocumentState создает состояние документа с окружениями, объявленными через \newtheorem
+func newDocumentState(kinds map[string]theoremKind) *documentState {
+	return &documentState{
+		labels:        make(map[string]string),
+		unresolved:    make(map[string]bool),
+		headingLevel:  1,
+		counters:      make(map[string]int),
+		theoremScopes: make(map[string]string),
+		kinds:         kinds,
+		theoremRe:     theoremBeginRegexp(kinds),
+	}
+}
+
+// htmlConverter переводит тело документа в HTML блок за блоком.
+// Нумерация формул, разделов и сносок продолжается между блоками, поэтому
+// весь документ и поток блоков дают одинаковую разметку
+type htmlConverter struct {
+	opts       Options
+	meta       Metadata
+	state      *documentState
+	codeBlocks []codeBlock // защищенный код текущего блока
+
+	// hasTitleBlock — документ выводит заголовок через \maketitle, и <h1> не нужен;
+	// titleRendered — блок заголовка уже выведен
+	hasTitleBlock bool
+	titleRendered bool
+
+	environments map[string]TextEnvironment
+	sourceMap    []SourceBlock
+	diagnostics  []Diagnostic
+
+	// references накапливает текст начиная с первой строки списка литературы
+	inReferences bool
+	references   strings.Builder
+}
+
+// newHTMLConverter создает конвертер документа
+func newHTMLConverter(opts Options, meta Metadata, kinds map[string]theoremKind) *htmlConverter {
+	return &htmlConverter{opts: opts, meta: meta, state: newDocumentState(kinds), environments: opts.textEnvironments()}
+}
+
+// convertBlock конвертирует фрагмент тела документа без комментариев и с защищенным кодом.
+// Блок заголовка выводится по первой команде \maketitle, остальные удаляются
+func (c *htmlConverter) convertBlock(content string) string {
+	if c.titleRendered {
+		content = strings.ReplaceAll(content, `\maketitle`, "")
+	}
+	content, found := processMaketitle(content, c.meta)
+	c.titleRendered = c.titleRendered || found
+
+	// Окружения из реестра (алгоритмы, формулы) обрабатываются БЕЗ источников
+	ctx := &RenderContext{Locale: c.opts.Locale, opts: c.opts, state: c.state}
+	content = processRegisteredEnvironments(content, ctx)
+
+	// Все, что идет после первого источника, относится к списку литературы
+	if c.inReferences {
+		c.references.WriteString("\n" + content)
+		return ""
+	}
+	content, rest, found := cutReferences(content)
+	if found {
+		c.inReferences = true
+		c.references.WriteString(rest)
+	}
+
+	// Теоремы нумеруются между заголовками: номер внутри раздела зависит от того,
+	// какой раздел уже начался
+	content = processSections(content, c.state, func(text string) string {
+		return processTheorems(text, c.state, c.opts.Locale)
+	})
+	content = processRefs(content, c.state)
+	content = processTextEnvironments(content, c.environments)
+	content = processRegisteredCommands(content, ctx)
+
+	// Обрабатываем абзацы и команды
+	content = processParagraphs(content)
+	content = processLinks(content)
+	content = processGraphics(content, c.opts.BaseDir)
+	content = processFootnotes(content, c.state)
+	content = processCommands(content)
+	content = processTypography(content, c.opts.Locale.Lang)
+	content = cleanupMathSymbols(content)
+	content = processMathAccessibility(content)
+	return restoreVerbatim(content, c.codeBlocks)
+}
+
+// warnUnresolved сообщает о метках, которые так и не были объявлены
+func (c *htmlConverter) warnUnresolved() {
+	labels := make([]string, 0, len(c.state.unresolved))
+	for label := range c.state.unresolved {
+		if _, ok := c.state.labels[label]; !ok {
+			labels = append(labels, label)
+		}
+	}
+	sort.Strings(labels)
+	for _, label := range labels {
+		log.Printf("Предупреждение: неизвестная метка %q", label)
+	}
+}
+
+// documentMetadata извлекает метаданные с учетом заголовка и языка из опций
+func documentMetadata(latex string, opts Options) Metadata {
+	meta := extractMetadata(latex, opts.Locale.Lang)
+	meta.Lang = opts.Locale.Lang
+	if opts.Title != "" {
+		meta.Title = opts.Title
+	}
+	if meta.Title == "" {
+		meta.Title = opts.Locale.Untitled
+	}
+	return meta
+}
+
+// extractDocumentContent извлекает содержимое между \begin{document} и \end{document}
+func extractDocumentContent(latex string) string {
+	beginMatch := beginDocumentRe.FindStringIndex(latex)
+	endMatch := endDocumentRe.FindStringIndex(latex)
+
+	if beginMatch != nil && endMatch != nil {
+		return latex[beginMatch[1]:endMatch[0]]
+	}
+	return latex
+}
+
+// extractReferences извлекает ссылки из документа
+func extractReferences(content string) []string {
+	var references []string
+	lines := strings.Split(content, "\n")
+
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+
+		// Ищем строки, начинающиеся с цифры и точки (источники)
+		if referenceLineRe.MatchString(line) {
+			ref := line
+			// Собираем многострочную ссылку
+			for j := i + 1; j < len(lines); j++ {
+				nextLine := strings.TrimSpace(lines[j])
+				if nextLine == "" {
+					break
+				}
+				// Если встретили новый источник, останавливаемся
+				if referenceLineRe.MatchString(nextLine) {
+					break
+				}
+				ref += " " + nextLine
+			}
+			references = append(references, ref)
+		}
+	}
+
+	return references
+}
+
+// cutReferences отделяет текст до первого источника от списка литературы
+func cutReferences(content string) (before, rest string, found bool) {
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		if referenceLineRe.MatchString(strings.TrimSpace(line)) {
+			return strings.Join(lines[:i], "\n"), strings.Join(lines[i:], "\n"), true
+		}
+	}
+	return content, "", false
+}
+
+// renderAlgorithm выводит алгоритм с корректной математикой.
+// Шаги выводятся вложенными списками <ol>, отступы задаются стилями
+func renderAlgorithm(ctx *RenderContext, env Environment) string {
+	locale := ctx.Locale
+	caption, steps := parseAlgorithmSteps(env.Body, 0)
+	number := ctx.Next("algorithm")
+
+	var result []string
+	if caption != "" {
+		titleID := fmt.Sprintf("algorithm-%d-title", number)
+		result = append(result, `<figure class="algorithm" aria-labelledby="`+titleID+`">`)
+		result = append(result, `<figcaption class="algorithm-title" id="`+titleID+`">`+locale.Algorithm+` `+caption+`</figcaption>`)
+	} else {
+		result = append(result, `<figure class="algorithm">`)
+	}
+
+	// Вход, выход и инициализация в начале алгоритма выводятся над списком шагов
+	for len(steps) > 0 && (steps[0].Name == "input" || steps[0].Name == "output" || steps[0].Name == "init") {
+		result = append(result, `<div class="algorithm-`+steps[0].Name+`">`+renderAlgorithmStep(steps[0], locale)+`</div>`)
+		steps = steps[1:]
+	}
+
+	result = append(result, renderAlgorithmSteps(steps, locale, "algorithm-steps")...)
+	result = append(result, `</figure>`)
+	return strings.Join(result, "\n")
+}
+
+// renderAlgorithmSteps выводит шаги алгоритма списком, вложенные блоки — вложенными списками
+func renderAlgorithmSteps(steps []*Node, locale Locale, class string) []string {
+	if len(steps) == 0 {
+		return nil
+	}
+
+	result := []string{`<ol class="` + class + `">`}
+	for _, step := range steps {
+		itemClass := "algorithm-" + step.Name
+		if step.Name == "statement" {
+			itemClass = "algorithm-line"
+		}
+
+		item := `<li class="` + itemClass + `">` + renderAlgorithmStep(step, locale)
+		if len(step.Children) == 0 {
+			result = append(result, item+`</li>`)
+			continue
+		}
+		result = append(result, item)
+		result = append(result, renderAlgorithmSteps(step.Children, locale, "algorithm-block")...)
+		result = append(result, `</li>`)
+	}
+	result = append(result, `</ol>`)
+
+	return result
+}
+
+// renderAlgorithmStep формирует текст одного шага с ключевыми словами локали
+func renderAlgorithmStep(step *Node, locale Locale) string {
+	text := whitespaceRunRe.ReplaceAllString(step.Text, " ")
+	keyword := func(word string) string {
+		return `<strong>` + word + `</strong>`
+	}
+
+	switch step.Name {
+	case "input":
+		return keyword(locale.Input) + ` ` + processInlineMathForAlgorithm(text)
+	case "output":
+		return keyword(locale.Output) + ` ` + processInlineMathForAlgorithm(text)
+	case "init":
+		return keyword(locale.Init) + ` ` + processAlgorithmComplexLine(text)
+	case "for":
+		return keyword(locale.For) + ` ` + processInlineMathForAlgorithm(text) + ` ` + keyword(locale.Do)
+	case "foreach":
+		return keyword(locale.ForEach) + ` ` + processInlineMathForAlgorithm(text) + ` ` + keyword(locale.Do)
+	case "while":
+		return keyword(locale.While) + ` ` + processAlgorithmComplexLine(text) + ` ` + keyword(locale.Do)
+	case "if":
+		return keyword(locale.If) + ` ` + processAlgorithmComplexLine(text) + ` ` + keyword(locale.Then)
+	case "elseif":
+		return keyword(locale.Else+` `+locale.If) + ` ` + processAlgorithmComplexLine(text) + ` ` + keyword(locale.Then)
+	case "else":
+		return keyword(locale.Else)
+	case "return":
+		return keyword(locale.Return) + ` ` + processInlineMathForAlgorithm(text)
+	case "comment":
+		return `<span aria-hidden="true">// </span>` + text
+	}
+
+	// Формулы инструкции оборачиваются, чтобы их выравнивание задавалось стилями алгоритма
+	return algorithmMathRe.ReplaceAllStringFunc(processAlgorithmComplexLine(text), func(math string) string {
+		return `<span class="algorithm-math">` + math + `</span>`
+	})
+}
+
+// processInlineMathForAlgorithm обрабатывает inline математику в алгоритмах
+func processInlineMathForAlgorithm(text string) string {
+	// Очищаем текст
+	text = strings.TrimSpace(text)
+	text = strings.ReplaceAll(text, "\\quad", " ")
+	text = strings.ReplaceAll(text, "\\;", " ")
+
+	// Если уже есть $ или \(, оставляем как есть
+	if strings.Contains(text, "$") || strings.Contains(text, "\\(") {
+		return cleanMathSyntax(text)
+	}
+
+	// Если содержит математические символы, оборачиваем в $...$
+	if containsMathSymbols(text) {
+		cleanText := cleanMathSyntax(text)
+		return "$" + cleanText + "$"
+	}
+
+	return text
+}
+
+// processAlgorithmComplexLine обрабатывает сложные строки в алгоритме
+func processAlgorithmComplexLine(line string) string {
+	// Убираем лишние пробельные конструкции
+	line = strings.ReplaceAll(line, "\\quad", " ")
+	line = strings.ReplaceAll(line, "\\;", " ")
+	// Перевод строки \\ вне формул; внутри формулы он нужен MathJax (cases, matrix)
+	line = algorithmLineBreakRe.ReplaceAllStringFunc(line, func(part string) string {
+		if part == `\\` {
+			return "<br>"
+		}
+		return part
+	})
+
+	// Разбиваем строку на части по точке с запятой
+	parts := statementSeparatorRe.Split(line, -1)
+	var processedParts []string
+
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		// Обрабатываем inline/display математику для алгоритма
+		processedPart := processInlineMathForAlgorithm(part)
+
+		// Обрабатываем текстовые выделения
+		processedPart = textbfRe.ReplaceAllString(processedPart, `<strong>$1</strong>`)
+		processedPart = textitRe.ReplaceAllString(processedPart, `<em>$1</em>`)
+		processedPart = textRe.ReplaceAllString(processedPart, `$1`)
+
+		processedParts = append(processedParts, processedPart)
+	}
+
+	result := strings.Join(processedParts, "; ")
+
+	// ВАЖНО: больше не удаляем все LaTeX-команды подряд,
+	// иначе потеряем математику! Чистим только "мусор".
+	result = strings.ReplaceAll(result, "\\,", " ")
+	result = strings.TrimSpace(result)
+
+	return result
+}
+
+// convertMathInText конвертирует математику в обычном тексте
+func convertMathInText(text string) string {
+	text = strings.TrimSpace(text)
+	text = strings.ReplaceAll(text, "\\quad", " ")
+	text = strings.ReplaceAll(text, "\\;", " ")
+
+	// Если уже есть $...$, оставляем и чистим синтаксис
+	if strings.Contains(text, "$") {
+		return cleanMathSyntax(text)
+	}
+
+	// Если это явно математика, оборачиваем в $
+	if containsMathSymbols(text) {
+		cleanText := cleanMathSyntax(text)
+		return "$" + cleanText + "$"
+	}
+
+	return text
+}
+
+// cleanMathSyntax очищает математический синтаксис
+func cleanMathSyntax(math string) string {
+	// Исправляем фигурные скобки
+	math = strings.ReplaceAll(math, "\\left\\{", "\\{")
+	math = strings.ReplaceAll(math, "\\right\\}", "\\}")
+
+	// Исправляем индексы
+	math = subscriptRe.ReplaceAllString(math, `${1}_{${2}}${3}`)
+
+	// Исправляем степени
+	math = superscriptRe.ReplaceAllString(math, `${1}^{${2}}${3}`)
+
+	// Исправляем команды LaTeX
+	math = strings.ReplaceAll(math, "\\gets", "\\leftarrow")
+	math = strings.ReplaceAll(math, "\\varnothing", "\\emptyset")
+	math = strings.ReplaceAll(math, "\\displaystyle", "")
+
+	// Убираем проблемные символы
+	math = strings.ReplaceAll(math, "&", "")
+
+	// Очищаем множественные пробелы
+	math = whitespaceRunRe.ReplaceAllString(math, " ")
+
+	return strings.TrimSpace(math)
+}
+
+// containsMathSymbols проверяет наличие математических символов
+func containsMathSymbols(text string) bool {
+	mathPatterns := []string{
+		"\\alpha", "\\beta", "\\gamma", "\\delta", "\\tau", "\\rho",
+		"\\mathbb", "\\in", "\\cup", "\\leftarrow", "\\gets", "\\emptyset",
+		"\\infty", "\\ge", "\\le", "\\ne", "_", "^", "\\sum",
+		"\\frac", "\\cdot", "\\times", "\\subset", "\\forall",
+		"\\varnothing", "\\arg", "\\min", "\\max", "\\neq",
+		"\\{", "\\}", "\\cap", "\\setminus", "\\bigl", "\\bigr",
+	}
+
+	for _, pattern := range mathPatterns {
+		if strings.Contains(text, pattern) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// renderEquation выводит формулу с нумерацией
+func renderEquation(ctx *RenderContext, env Environment) string {
+	inner := strings.TrimSpace(env.Body)
+	number := ctx.Next("equation")
+
+	// Метка формулы становится якорем блока
+	idAttr := ""
+	if matches := labelCommandRe.FindStringSubmatch(inner); matches != nil {
+		ctx.SetLabel(matches[1], fmt.Sprint(number))
+		idAttr = fmt.Sprintf(" id=\"%s\"", matches[1])
+		inner = labelCommandRe.ReplaceAllString(inner, "")
+	}
+
+	inner = ctx.Environments(inner)
+	inner = cleanMathSyntax(inner)
+
+	return fmt.Sprintf("\n<div class=\"equation\"%s>$$%s \\tag{%d}$$</div>\n", idAttr, inner, number)
+}
+
+// renderCases приводит строки cases к виду, который понимает MathJax
+func renderCases(ctx *RenderContext, env Environment) string {
+	inner := strings.TrimSpace(env.Body)
+
+	lines := strings.Split(inner, `\\`)
+	var processedLines []string
+
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		parts := strings.Split(line, "&")
+		if len(parts) >= 2 {
+			value := strings.TrimSpace(parts[0])
+			value = cleanMathSyntax(value)
+
+			condition := strings.TrimSpace(parts[1])
+			condition = cleanMathSyntax(condition)
+
+			processedLine := value + " & " + condition
+			processedLines = append(processedLines, processedLine)
+		} else {
+			cleanLine := cleanMathSyntax(line)
+			processedLines = append(processedLines, cleanLine)
+		}
+	}
+
+	if len(processedLines) > 0 {
+		processedCases := strings.Join(processedLines, " \\\\ ")
+		return "\\begin{cases}" + processedCases + "\\end{cases}"
+	}
+
+	return "\\begin{cases}" + inner + "\\end{cases}"
+}
+
+// processParagraphs обрабатывает абзацы
+func processParagraphs(content string) string {
+	lines := strings.Split(content, "\n")
+	var result []string
+	var currentParagraph []string
+
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+
+		if line == "" {
+			if len(currentParagraph) > 0 {
+				paragraph := strings.Join(currentParagraph, " ")
+				if !strings.HasPrefix(paragraph, "<") && !strings.HasPrefix(paragraph, "$$") {
+					paragraph = "<p>" + paragraph + "</p>"
+				}
+				result = append(result, paragraph)
+				currentParagraph = nil
+			}
+		} else if strings.HasPrefix(line, "<") || strings.HasPrefix(line, "$$") {
+			if len(currentParagraph) > 0 {
+				paragraph := strings.Join(currentParagraph, " ")
+				paragraph = "<p>" + paragraph + "</p>"
+				result = append(result, paragraph)
+				currentParagraph = nil
+			}
+			result = append(result, line)
+		} else {
+			currentParagraph = append(currentParagraph, line)
+		}
+	}
+
+	if len(currentParagraph) > 0 {
+		paragraph := strings.Join(currentParagraph, " ")
+		if !strings.HasPrefix(paragraph, "<") {
+			paragraph = "<p>" + paragraph + "</p>"
+		}
+		result = append(result, paragraph)
+	}
+
+	return strings.Join(result, "\n")
+}
+
+// processCommands обрабатывает LaTeX команды
+func processCommands(content string) string {
+	content = textbfRe.ReplaceAllString(content, `<strong>$1</strong>`)
+	content = textitRe.ReplaceAllString(content, `<em>$1</em>`)
+	content = emphRe.ReplaceAllString(content, `<em>$1</em>`)
+
+	return content
+}
+
+// cleanupMathSymbols очищает оставшиеся символы
+func cleanupMathSymbols(content string) string {
+	lines := strings.Split(content, "\n")
+	var result []string
+
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+
+		if line == "$$" {
+			continue
+		}
+
+		line = displayMathParagraphRe.ReplaceAllString(line, `<div class="equation">$$$1$$</div>`)
+
+		if line != "" {
+			result = append(result, line)
+		}
+	}
+
+	return strings.Join(result, "\n")
+}
+
+// generateHTML генерирует финальный HTML; body — тело страницы вместе с заголовком документа
+func generateHTML(body string, references, footnotes []string, meta Metadata, opts Options) string {
+	return generatePageHeader(meta, opts.Locale) + body + generatePageEnd(references, footnotes, opts)
+}
+
+// generatePageEnd формирует сноски, список литературы и конец страницы после тела документа
+func generatePageEnd(references, footnotes []string, opts Options) string {
+	end := `
+        ` + generateFootnotesHTML(footnotes, opts.Locale) + `
+        ` + generateReferencesHTML(references, opts.Locale)
+	if opts.DevOverlay {
+		end += devOverlayScript(opts)
+	}
+	return end + generatePageFooter()
+}
+
+// generateReferencesHTML формирует список литературы
+func generateReferencesHTML(references []string, locale Locale) string {
+	if len(references) == 0 {
+		return ""
+	}
+
+	referencesHTML := `
+<hr>
+<section class="references" role="doc-bibliography" aria-label="` + html.EscapeString(locale.References) + `">
+  <ol>`
+	for _, ref := range references {
+		// Убираем номер в начале
+		ref = referenceNumberRe.ReplaceAllString(ref, "")
+		ref = processTypography(ref, locale.Lang)
+		ref = linkDOIs(ref)
+		referencesHTML += "<li>" + ref + "</li>"
+	}
+	return referencesHTML + "</ol>\n</section>"
+}
+
+// generatePageHeader формирует начало страницы: стили, MathJax и открывающий тег <main>
+func generatePageHeader(meta Metadata, locale Locale) string {
+	return `<!DOCTYPE html>
+<html lang="` + locale.Lang + `">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>` + html.EscapeString(meta.Title) + `</title>
+    ` + generateMetaTags(meta) + `
+    
+    <script>
+        window.MathJax = {
+            tex: {
+                inlineMath: [['$', '$'], ['\\(', '\\)']],
+                displayMath: [['$$', '$$'], ['\\[', '\\]']],
+                tags: 'ams',
+                tagSide: 'right',
+                processEscapes: true,
+                processEnvironments: true
+            },
+            svg: {
+                fontCache: 'global'
+            },
+            startup: {
+                ready: () => {
+                    console.log('MathJax готов');
+                    MathJax.startup.defaultReady();
+                    MathJax.startup.promise.then(() => {
+                        showContent();
+                    });
+                }
+            }
+        };
+    </script>
+    
+    <script async src="https://cdn.jsdelivr.net/npm/mathjax@3/es5/tex-svg.js"></script>
+    
+    <style>
+        body {
+            max-width: 800px;
+            margin: 0 auto;
+            padding: 20px;
+            line-height: 1.6;
+            color: white;
+            background-color: #111;
+            font-family: 'Times New Roman', Times, serif;
+        }
+        
+        .equation {
+            margin: 20px 0;
+            text-align: center;
+            padding: 10px;
+        }
+        
+        .algorithm {
+            margin: 20px 0;
+            padding: 20px;
+            border: 1px solid #444;
+            background-color: #1a1a1a;
+            font-family: 'Courier New', monospace;
+            font-size: 14px;
+            border-radius: 5px;
+        }
+        
+        .algorithm-title {
+            margin-bottom: 15px;
+            font-weight: bold;
+            color: #fff;
+            font-family: 'Times New Roman', Times, serif;
+            text-align: center;
+            font-size: 16px;
+        }
+        
+        .algorithm-input, .algorithm-output, .algorithm-init {
+            margin: 10px 0;
+            padding: 8px 0;
+            color: #ccc;
+            border-bottom: 1px solid #333;
+            font-family: 'Times New Roman', Times, serif;
+        }
+        
+        .algorithm ol {
+            list-style: none;
+            margin: 0;
+            padding-left: 2em;
+        }
+
+        .algorithm ol.algorithm-steps {
+            padding-left: 0;
+        }
+
+        .algorithm-for, .algorithm-while, .algorithm-foreach, .algorithm-if,
+        .algorithm-elseif, .algorithm-else, .algorithm-return {
+            margin: 5px 0;
+            color: #fff;
+            line-height: 1.4;
+        }
+        
+        .algorithm-line {
+            margin: 3px 0;
+            color: #ddd;
+            line-height: 1.4;
+        }
+        
+        .algorithm-comment {
+            margin: 3px 0;
+            color: #888;
+            font-style: italic;
+            line-height: 1.4;
+        }
+        
+        .algorithm mjx-container {
+			font-family: 'Times New Roman', Times, serif !important;
+			font-size: 1em !important;
+			color: #fff !important;
+		}
+		.algorithm-math {
+			display: inline-block;
+			margin: 2px 0;
+		}
+		.algorithm-math div {
+			text-align: center;
+		}
+
+        .algorithm mjx-container[display="true"] {
+            display: block !important;
+            margin: 0.5em 0 !important;
+            text-align: left !important;
+        }
+        
+        .algorithm mjx-container svg {
+            vertical-align: baseline !important;
+        }
+        
+        .section-number {
+            color: #888;
+        }
+
+        .abstract {
+            margin: 20px 40px;
+            font-size: 0.95em;
+        }
+
+        .abstract-title {
+            font-weight: bold;
+            text-align: center;
+        }
+
+        blockquote.quote, blockquote.quotation, blockquote.verse {
+            margin: 15px 40px;
+        }
+
+        blockquote.quotation p {
+            text-indent: 1.5em;
+        }
+
+        blockquote.verse p {
+            white-space: pre-line;
+        }
+
+        .center {
+            text-align: center;
+        }
+
+        .flushleft {
+            text-align: left;
+        }
+
+        .flushright {
+            text-align: right;
+        }
+
+        .minipage {
+            display: inline-block;
+            vertical-align: top;
+            box-sizing: border-box;
+        }
+
+        .title-block {
+            text-align: center;
+            margin-bottom: 30px;
+        }
+
+        .title-authors, .title-date {
+            color: #ccc;
+            font-size: 1.1em;
+        }
+
+        h1 {
+            text-align: center;
+            margin-bottom: 30px;
+            font-size: 2.5em;
+        }
+        
+        p {
+            text-align: justify;
+            margin-bottom: 15px;
+            font-size: 16px;
+        }
+        
+        .loading {
+            text-align: center;
+            color: #666;
+            font-style: italic;
+            padding: 50px;
+        }
+        
+        .loading-spinner {
+            display: inline-block;
+            width: 20px;
+            height: 20px;
+            border: 3px solid #666;
+            border-radius: 50%;
+            border-top-color: #fff;
+            animation: spin 1s ease-in-out infinite;
+            margin-right: 10px;
+        }
+
+		.references {
+			border-top: none;   /* убираем верхнюю линию у блока */
+			border-bottom: none; /* убираем нижнюю */
+			margin-top: 0.5em;
+		}
+
+		.references ol {
+			margin: 0;
+			padding-left: 20px;
+		}
+
+		.theorem, .proof {
+			margin: 20px 0;
+		}
+
+		.theorem {
+			padding: 10px 15px;
+			border-left: 3px solid #6ea8fe;
+			background-color: #161616;
+		}
+
+		.theorem p, .proof p {
+			margin-bottom: 8px;
+		}
+
+		.theorem-head, .proof-head {
+			margin-bottom: 5px;
+		}
+
+		.qed {
+			text-align: right;
+		}
+
+		pre.code {
+			margin: 20px 0;
+			padding: 15px;
+			overflow-x: auto;
+			border: 1px solid #444;
+			border-radius: 5px;
+			background-color: #1a1a1a;
+			font-family: 'Courier New', monospace;
+			font-size: 14px;
+			line-height: 1.4;
+		}
+
+		.listing figcaption {
+			text-align: center;
+			font-weight: bold;
+		}
+
+		.tok-keyword { color: #c678dd; }
+		.tok-builtin { color: #56b6c2; }
+		.tok-string { color: #98c379; }
+		.tok-number { color: #d19a66; }
+		.tok-comment { color: #888; font-style: italic; }
+
+		img.graphics {
+			display: block;
+			max-width: 100%;
+			margin: 20px auto;
+		}
+
+		.param {
+			position: relative;
+			border-bottom: 1px dotted #888;
+			cursor: help;
+		}
+
+		.param-tooltip {
+			display: none;
+			position: absolute;
+			left: 0;
+			top: 100%;
+			z-index: 10;
+			width: max-content;
+			max-width: 360px;
+			padding: 8px 10px;
+			font-size: 14px;
+			line-height: 1.4;
+			text-align: left;
+			color: #eee;
+			background-color: #222;
+			border: 1px solid #444;
+			border-radius: 5px;
+		}
+
+		.param:hover .param-tooltip, .param:focus .param-tooltip {
+			display: block;
+		}
+
+		.footnotes {
+			font-size: 14px;
+			margin-top: 0.5em;
+		}
+
+		.footnotes ol {
+			margin: 0;
+			padding-left: 20px;
+		}
+
+		.footnote-ref a, .footnote-back {
+			text-decoration: none;
+		}
+
+		a {
+			color: #6ea8fe;
+		}
+
+		hr {
+			border: none;
+			border-top: 1px solid #444; /* более мягкий серый */
+			margin: 1em 0;
+		}
+
+        @keyframes spin {
+            to { transform: rotate(360deg); }
+        }
+    </style>
+</head>
+<body>
+    <div id="loading" class="loading" role="status" aria-live="polite">
+        <div class="loading-spinner" aria-hidden="true"></div>
+        ` + locale.Loading + `
+    </div>
+    
+    <main id="content" style="display: none;">`
+}
+
+// generatePageFooter закрывает <main> и добавляет скрипт показа контента после загрузки MathJax
+func generatePageFooter() string {
+	return `
+    </main>
+
+    <script>
+        function showContent() {
+            document.getElementById('loading').style.display = 'none';
+            document.getElementById('content').style.display = 'block';
+            console.log('Контент отображен');
+        }
+
+        function waitForMathJax() {
+            if (window.MathJax && window.MathJax.startup && window.MathJax.startup.promise) {
+                window.MathJax.startup.promise.then(() => {
+                    console.log('MathJax загружен');
+                    showContent();
+                }).catch((err) => {
+                    console.log('Ошибка MathJax:', err);
+                    showContent();
+                });
+            } else {
+                setTimeout(waitForMathJax, 100);
+            }
+        }
+
+        document.addEventListener('DOMContentLoaded', function() {
+            setTimeout(() => {
+                if (document.getElementById('loading').style.display !== 'none') {
+                    showContent();
+                }
+            }, 5000);
+            
+            waitForMathJax();
+        });
+    </script>
+</body>
+</html>`
+}
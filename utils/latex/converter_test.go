@@ -0,0 +1,22 @@
+package latex
+
+import "testing"
+
+// convert конвертирует документ в HTML со встроенным словарем lang
+func convert(t *testing.T, latex, lang string) Result {
+	t.Helper()
+	locale, err := LoadLocale(lang, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := ConvertLatexToHTML(latex, Options{Locale: locale})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+// document оборачивает тело в минимальную преамбулу
+func document(body string) string {
+	return "\\documentclass{article}\n\\begin{document}\n" + body + "\n\\end{document}\n"
+}
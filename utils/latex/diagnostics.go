@@ -0,0 +1,19 @@
+package latex
+
+import (
+	"fmt"
+	"io"
+)
+
+// Diagnostic — замечание к исходному .tex файлу
+type Diagnostic struct {
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
+
+// PrintDiagnostics выводит замечания в формате file:line: message
+func PrintDiagnostics(w io.Writer, file string, diagnostics []Diagnostic) {
+	for _, d := range diagnostics {
+		fmt.Fprintf(w, "%s:%d: %s\n", file, d.Line, d.Message)
+	}
+}
@@ -0,0 +1,198 @@
+package latex
+
+import (
+	"html"
+	"strings"
+	"unicode"
+)
+
+// languageSpec описывает лексику языка для подсветки синтаксиса
+type languageSpec struct {
+	keywords     map[string]bool
+	builtins     map[string]bool
+	lineComment  string
+	blockComment [2]string
+	quotes       string
+	rawQuote     byte // кавычка без экранирования (` в Go)
+	tripleQuotes bool // строки """...""" и '''...''' (Python)
+}
+
+// languageAliases сопоставляет имена языков из опций окружений с поддерживаемыми
+var languageAliases = map[string]string{
+	"go":         "go",
+	"golang":     "go",
+	"javascript": "javascript",
+	"js":         "javascript",
+	"python":     "python",
+	"py":         "python",
+	"python3":    "python",
+}
+
+// languages содержит описания поддерживаемых языков
+var languages = map[string]languageSpec{
+	"go": {
+		keywords: wordSet(`break case chan const continue default defer else fallthrough for func go goto if
+			import interface map package range return select struct switch type var`),
+		builtins: wordSet(`append cap close complex copy delete imag len make new panic print println real recover
+			bool byte complex64 complex128 error float32 float64 int int8 int16 int32 int64 rune string
+			uint uint8 uint16 uint32 uint64 uintptr any true false iota nil`),
+		lineComment:  "//",
+		blockComment: [2]string{"/*", "*/"},
+		quotes:       `"'`,
+		rawQuote:     '`',
+	},
+	"javascript": {
+		keywords: wordSet(`async await break case catch class const continue debugger default delete do else export
+			extends finally for function if import in instanceof let new of return static super switch this throw
+			try typeof var void while with yield`),
+		builtins: wordSet(`Array Math Number Object String Set Map Promise JSON console document window
+			true false null undefined NaN Infinity`),
+		lineComment:  "//",
+		blockComment: [2]string{"/*", "*/"},
+		quotes:       `"'`,
+		rawQuote:     '`',
+	},
+	"python": {
+		keywords: wordSet(`and as assert async await break class continue def del elif else except finally for from
+			global if import in is lambda nonlocal not or pass raise return try while with yield`),
+		builtins: wordSet(`abs all any bool dict enumerate float int len list max min print range round set sorted
+			str sum tuple zip True False None self`),
+		lineComment:  "#",
+		quotes:       `"'`,
+		tripleQuotes: true,
+	},
+}
+
+// wordSet строит множество слов из списка, разделенного пробелами
+func wordSet(words string) map[string]bool {
+	set := make(map[string]bool)
+	for _, word := range strings.Fields(words) {
+		set[word] = true
+	}
+	return set
+}
+
+// highlightCode размечает исходный код классами tok-* и экранирует HTML.
+// Для неизвестного языка код только экранируется
+func highlightCode(code, language string) string {
+	spec, ok := languages[languageAliases[strings.ToLower(language)]]
+	if !ok {
+		return html.EscapeString(code)
+	}
+
+	var out strings.Builder
+	emit := func(class, text string) {
+		if class == "" {
+			out.WriteString(html.EscapeString(text))
+			return
+		}
+		out.WriteString(`<span class="tok-` + class + `">` + html.EscapeString(text) + `</span>`)
+	}
+
+	for i := 0; i < len(code); {
+		rest := code[i:]
+
+		switch {
+		case spec.blockComment[0] != "" && strings.HasPrefix(rest, spec.blockComment[0]):
+			end := strings.Index(rest[len(spec.blockComment[0]):], spec.blockComment[1])
+			n := len(rest)
+			if end >= 0 {
+				n = len(spec.blockComment[0]) + end + len(spec.blockComment[1])
+			}
+			emit("comment", rest[:n])
+			i += n
+
+		case strings.HasPrefix(rest, spec.lineComment):
+			n := strings.IndexByte(rest, '\n')
+			if n < 0 {
+				n = len(rest)
+			}
+			emit("comment", rest[:n])
+			i += n
+
+		case spec.tripleQuotes && (strings.HasPrefix(rest, `"""`) || strings.HasPrefix(rest, `'''`)):
+			end := strings.Index(rest[3:], rest[:3])
+			n := len(rest)
+			if end >= 0 {
+				n = 3 + end + 3
+			}
+			emit("string", rest[:n])
+			i += n
+
+		case spec.rawQuote != 0 && rest[0] == spec.rawQuote:
+			end := strings.IndexByte(rest[1:], spec.rawQuote)
+			n := len(rest)
+			if end >= 0 {
+				n = end + 2
+			}
+			emit("string", rest[:n])
+			i += n
+
+		case strings.IndexByte(spec.quotes, rest[0]) >= 0:
+			n := scanQuoted(rest)
+			emit("string", rest[:n])
+			i += n
+
+		case isDigit(rest[0]) && (i == 0 || !isIdentByte(code[i-1])):
+			n := 1
+			for n < len(rest) && (isIdentByte(rest[n]) || rest[n] == '.') {
+				n++
+			}
+			emit("number", rest[:n])
+			i += n
+
+		case isIdentStart(rest[0]):
+			n := 1
+			for n < len(rest) && isIdentByte(rest[n]) {
+				n++
+			}
+			word := rest[:n]
+			switch {
+			case spec.keywords[word]:
+				emit("keyword", word)
+			case spec.builtins[word]:
+				emit("builtin", word)
+			default:
+				emit("", word)
+			}
+			i += n
+
+		default:
+			// Многобайтные символы UTF-8 копируем целиком
+			n := 1
+			for n < len(rest) && rest[n]&0xC0 == 0x80 {
+				n++
+			}
+			emit("", rest[:n])
+			i += n
+		}
+	}
+
+	return out.String()
+}
+
+// scanQuoted возвращает длину строкового литерала с экранированием через \
+func scanQuoted(s string) int {
+	quote := s[0]
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case quote, '\n':
+			return i + 1
+		}
+	}
+	return len(s)
+}
+
+func isDigit(b byte) bool {
+	return b >= '0' && b <= '9'
+}
+
+func isIdentStart(b byte) bool {
+	return b == '_' || b == '$' || unicode.IsLetter(rune(b)) && b < 0x80
+}
+
+func isIdentByte(b byte) bool {
+	return isIdentStart(b) || isDigit(b)
+}
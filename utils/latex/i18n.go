@@ -0,0 +1,149 @@
+package latex
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Locale содержит строки, которые конвертер вставляет в генерируемый HTML
+type Locale struct {
+	Lang       string `json:"lang"`
+	Algorithm  string `json:"algorithm"`
+	Input      string `json:"input"`
+	Output     string `json:"output"`
+	Init       string `json:"init"`
+	For        string `json:"for"`
+	ForEach    string `json:"foreach"`
+	While      string `json:"while"`
+	Do         string `json:"do"`
+	Return     string `json:"return"`
+	Loading    string `json:"loading"`
+	Untitled   string `json:"untitled"`
+	BackToText string `json:"backtotext"`
+	Proof      string `json:"proof"`
+	If         string `json:"if"`
+	Then       string `json:"then"`
+	Else       string `json:"else"`
+	Footnotes  string `json:"footnotes"`
+	References string `json:"references"`
+	Abstract   string `json:"abstract"`
+}
+
+// DefaultLang используется, если язык не задан и не найден в преамбуле
+const DefaultLang = "ru"
+
+// locales содержит встроенные словари
+var locales = map[string]Locale{
+	"ru": {
+		Lang:       "ru",
+		Algorithm:  "Алгоритм:",
+		Input:      "Вход:",
+		Output:     "Выход:",
+		Init:       "Инициализация:",
+		For:        "для",
+		ForEach:    "для каждого",
+		While:      "пока",
+		Do:         "делать",
+		Return:     "вернуть",
+		Loading:    "Загрузка математических формул...",
+		Untitled:   "Конвертированный документ",
+		BackToText: "Вернуться к тексту",
+		Proof:      "Доказательство",
+		If:         "если",
+		Then:       "то",
+		Else:       "иначе",
+		Footnotes:  "Примечания",
+		References: "Список литературы",
+		Abstract:   "Аннотация",
+	},
+	"en": {
+		Lang:       "en",
+		Algorithm:  "Algorithm:",
+		Input:      "Input:",
+		Output:     "Output:",
+		Init:       "Initialization:",
+		For:        "for",
+		ForEach:    "for each",
+		While:      "while",
+		Do:         "do",
+		Return:     "return",
+		Loading:    "Loading math formulas...",
+		Untitled:   "Converted document",
+		BackToText: "Back to text",
+		Proof:      "Proof",
+		If:         "if",
+		Then:       "then",
+		Else:       "else",
+		Footnotes:  "Footnotes",
+		References: "References",
+		Abstract:   "Abstract",
+	},
+}
+
+// babelLanguages сопоставляет имена языков babel с кодами встроенных словарей
+var babelLanguages = map[string]string{
+	"russian":   "ru",
+	"english":   "en",
+	"american":  "en",
+	"british":   "en",
+	"USenglish": "en",
+	"UKenglish": "en",
+}
+
+// LoadLocale возвращает словарь для языка lang с переопределениями из JSON файла
+func LoadLocale(lang, overridesPath string) (Locale, error) {
+	locale, ok := locales[lang]
+	if !ok {
+		return Locale{}, fmt.Errorf("неизвестный язык %q", lang)
+	}
+
+	if overridesPath == "" {
+		return locale, nil
+	}
+
+	data, err := os.ReadFile(overridesPath)
+	if err != nil {
+		return Locale{}, fmt.Errorf("чтение файла строк: %w", err)
+	}
+
+	// Unmarshal перезаписывает только присутствующие в JSON поля
+	if err := json.Unmarshal(data, &locale); err != nil {
+		return Locale{}, fmt.Errorf("разбор файла строк %s: %w", overridesPath, err)
+	}
+
+	return locale, nil
+}
+
+var babelRe = regexp.MustCompile(`\\usepackage\[([^\]]*)\]\{babel\}`)
+
+// DetectLanguage определяет язык документа по \usepackage[...]{babel}, не считая
+// закомментированных строк; пустая строка — язык не указан
+func DetectLanguage(latex string) string { return detectLanguage(stripComments(latex)) }
+
+// detectLanguage определяет язык документа по \usepackage[...]{babel}
+func detectLanguage(latex string) string {
+	matches := babelRe.FindStringSubmatch(latex)
+	if len(matches) < 2 {
+		return ""
+	}
+
+	options := strings.Split(matches[1], ",")
+	for _, option := range options {
+		option = strings.TrimSpace(option)
+		if strings.HasPrefix(option, "main=") {
+			return babelLanguages[strings.TrimPrefix(option, "main=")]
+		}
+	}
+
+	// Без main= основным языком babel считает последний из перечисленных
+	for i := len(options) - 1; i >= 0; i-- {
+		if lang, ok := babelLanguages[strings.TrimSpace(options[i])]; ok {
+			return lang
+		}
+	}
+
+	return ""
+}
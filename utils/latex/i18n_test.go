@@ -0,0 +1,120 @@
+package latex
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const keywordsDocument = `\begin{algorithm}
+\caption{Поиск}
+\KwIn{$n$}
+\KwOut{$m$}
+\For{$i = 1$}{
+  $x \gets x + 1$\;
+}
+\While{$x > 0$}{
+  $x \gets x - 1$\;
+}
+\KwRet{$x$}
+\end{algorithm}
+\begin{proof}
+Очевидно.
+\end{proof}
+\begin{abstract}
+Кратко.
+\end{abstract}`
+
+func TestLocaleKeywords(t *testing.T) {
+	tests := []struct {
+		lang string
+		want []string
+	}{
+		{"ru", []string{`<html lang="ru">`, "Алгоритм: Поиск", "<strong>Вход:</strong>", "<strong>Выход:</strong>",
+			"<strong>для</strong>", "<strong>пока</strong>", "<strong>делать</strong>", "<strong>вернуть</strong>",
+			"<em>Доказательство.</em>", `<div class="abstract-title">Аннотация</div>`, "<h1>Конвертированный документ</h1>"}},
+		{"en", []string{`<html lang="en">`, "Algorithm: Поиск", "<strong>Input:</strong>", "<strong>Output:</strong>",
+			"<strong>for</strong>", "<strong>while</strong>", "<strong>do</strong>", "<strong>return</strong>",
+			"<em>Proof.</em>", `<div class="abstract-title">Abstract</div>`, "<h1>Converted document</h1>"}},
+	}
+	for _, test := range tests {
+		t.Run(test.lang, func(t *testing.T) {
+			html := convert(t, document(keywordsDocument), test.lang).HTML
+			for _, want := range test.want {
+				if !strings.Contains(html, want) {
+					t.Errorf("нет %q", want)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadLocale(t *testing.T) {
+	dir := t.TempDir()
+	overrides := filepath.Join(dir, "strings.json")
+	if err := os.WriteFile(overrides, []byte(`{"algorithm": "Алг.", "proof": "Док-во"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	malformed := filepath.Join(dir, "malformed.json")
+	if err := os.WriteFile(malformed, []byte(`{"algorithm": `), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	locale, err := LoadLocale("ru", overrides)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if locale.Algorithm != "Алг." || locale.Proof != "Док-во" {
+		t.Errorf("переопределения не применены: algorithm = %q, proof = %q", locale.Algorithm, locale.Proof)
+	}
+	if locale.Input != "Вход:" || locale.Lang != "ru" {
+		t.Errorf("строки без переопределения изменились: input = %q, lang = %q", locale.Input, locale.Lang)
+	}
+	html := convert(t, document(keywordsDocument), "ru").HTML
+	if !strings.Contains(html, "Алгоритм: Поиск") {
+		t.Error("переопределения одного вызова попали во встроенный словарь")
+	}
+
+	tests := []struct {
+		name      string
+		lang      string
+		overrides string
+		want      string
+	}{
+		{"неизвестный язык", "de", "", `неизвестный язык "de"`},
+		{"нет файла строк", "ru", filepath.Join(dir, "missing.json"), "чтение файла строк"},
+		{"файл строк не JSON", "en", malformed, "разбор файла строк"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := LoadLocale(test.lang, test.overrides)
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Errorf("ошибка %v, ожидалась %q", err, test.want)
+			}
+		})
+	}
+}
+
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		name     string
+		preamble string
+		want     string
+	}{
+		{"english", `\usepackage[english]{babel}`, "en"},
+		{"russian", `\usepackage[russian]{babel}`, "ru"},
+		{"последний из списка", `\usepackage[english, russian]{babel}`, "ru"},
+		{"main=", `\usepackage[main=british, russian]{babel}`, "en"},
+		{"неизвестный язык", `\usepackage[german]{babel}`, ""},
+		{"без babel", `\usepackage{amsmath}`, ""},
+		{"закомментирован", "% \\usepackage[english]{babel}\n\\usepackage[russian]{babel}", "ru"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := DetectLanguage("\\documentclass{article}\n" + test.preamble + "\n"); got != test.want {
+				t.Errorf("DetectLanguage = %q, ожидалось %q", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package latex
+
+import (
+	"fmt"
+	"html"
+	"log"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// allowedURLSchemes перечисляет схемы, допустимые в ссылках
+var allowedURLSchemes = map[string]bool{
+	"http":   true,
+	"https":  true,
+	"ftp":    true,
+	"mailto": true,
+}
+
+var hyperrefRe = regexp.MustCompile(`\\hyperref\[([^\]]+)\]\{`)
+
+// processLinks обрабатывает \href, \url и \hyperref
+func processLinks(content string) string {
+	content = replaceCommand(content, "href", 2, func(args []string) string {
+		href, ok := validateURL(args[0])
+		if !ok {
+			log.Printf("Предупреждение: недопустимый URL в \\href: %q", args[0])
+			return args[1]
+		}
+		return `<a href="` + href + `">` + args[1] + `</a>`
+	})
+
+	content = replaceCommand(content, "url", 1, func(args []string) string {
+		href, ok := validateURL(args[0])
+		if !ok {
+			log.Printf("Предупреждение: недопустимый URL в \\url: %q", args[0])
+			return `<code>` + html.EscapeString(unescapeURL(args[0])) + `</code>`
+		}
+		return `<a href="` + href + `" class="url">` + html.EscapeString(unescapeURL(args[0])) + `</a>`
+	})
+
+	// \hyperref[метка]{текст} ссылается на якорь внутри документа
+	for {
+		loc := hyperrefRe.FindStringSubmatchIndex(content)
+		if loc == nil {
+			break
+		}
+		text, end, ok := readBraceGroup(content, loc[1]-1)
+		if !ok {
+			break
+		}
+		label := content[loc[2]:loc[3]]
+		content = content[:loc[0]] + `<a href="#` + html.EscapeString(label) + `">` + text + `</a>` + content[end:]
+	}
+
+	return content
+}
+
+// processFootnotes заменяет \footnote{...} ссылками и собирает тексты сносок
+func processFootnotes(content string, state *documentState) string {
+	return replaceCommand(content, "footnote", 1, func(args []string) string {
+		state.footnotes = append(state.footnotes, strings.TrimSpace(args[0]))
+		n := len(state.footnotes)
+		return fmt.Sprintf(`<sup class="footnote-ref" id="fnref-%d"><a href="#fn-%d" role="doc-noteref">%d</a></sup>`, n, n, n)
+	})
+}
+
+// replaceCommand заменяет команды \name{...}{...} с argCount обязательными аргументами
+func replaceCommand(content, name string, argCount int, handler func(args []string) string) string {
+	var out strings.Builder
+	last, offset := 0, 0
+
+	for {
+		start, pos, ok := findCommand(content, name, offset)
+		if !ok {
+			break
+		}
+
+		args := make([]string, 0, argCount)
+		complete := true
+		for i := 0; i < argCount; i++ {
+			for pos < len(content) && (content[pos] == ' ' || content[pos] == '\t') {
+				pos++
+			}
+			arg, end, ok := readBraceGroup(content, pos)
+			if !ok {
+				complete = false
+				break
+			}
+			args = append(args, arg)
+			pos = end
+		}
+
+		// Незавершенную команду оставляем как есть и ищем дальше; в конце текста pos + 1
+		// вышел бы за его границу
+		if !complete {
+			offset = min(pos+1, len(content))
+			continue
+		}
+
+		out.WriteString(content[last:start])
+		out.WriteString(handler(args))
+		last, offset = pos, pos
+	}
+
+	if last == 0 {
+		return content
+	}
+	out.WriteString(content[last:])
+	return out.String()
+}
+
+// findCommand ищет начиная с from команду \name, за которой после пробелов идет {,
+// и возвращает позиции обратной косой черты и открывающей скобки
+func findCommand(content, name string, from int) (int, int, bool) {
+	prefix := `\` + name
+	for {
+		i := strings.Index(content[from:], prefix)
+		if i < 0 {
+			return 0, 0, false
+		}
+		start := from + i
+		pos := start + len(prefix)
+		for pos < len(content) && strings.IndexByte(" \t\r\n\f", content[pos]) >= 0 {
+			pos++
+		}
+		if pos < len(content) && content[pos] == '{' {
+			return start, pos, true
+		}
+		from = start + 1
+	}
+}
+
+var urlUnescapeReplacer = strings.NewReplacer(`\#`, "#", `\%`, "%", `\_`, "_", `\&`, "&", `\~`, "~")
+
+// unescapeURL убирает экранирование LaTeX из адреса
+func unescapeURL(raw string) string {
+	return strings.TrimSpace(urlUnescapeReplacer.Replace(raw))
+}
+
+// validateURL проверяет адрес и возвращает его в виде, пригодном для атрибута href
+func validateURL(raw string) (string, bool) {
+	link := unescapeURL(raw)
+	if link == "" || strings.ContainsAny(link, " \t\n<>\"") {
+		return "", false
+	}
+
+	parsed, err := url.Parse(link)
+	if err != nil {
+		return "", false
+	}
+
+	// Относительные ссылки и якоря допустимы, неизвестные схемы (javascript: и т.п.) нет
+	if parsed.Scheme != "" {
+		if !allowedURLSchemes[strings.ToLower(parsed.Scheme)] {
+			return "", false
+		}
+		if parsed.Scheme != "mailto" && parsed.Host == "" {
+			return "", false
+		}
+	}
+
+	return html.EscapeString(link), true
+}
+
+// linkDOIs превращает DOI в тексте источника в ссылки на doi.org
+func linkDOIs(text string) string {
+	// Уже оформленные ссылки не трогаем
+	if strings.Contains(text, "href=") {
+		return text
+	}
+
+	return doiRe.ReplaceAllString(text, `<a href="https://doi.org/$0" class="doi">$0</a>`)
+}
+
+// generateFootnotesHTML формирует нумерованный список сносок с обратными ссылками
+func generateFootnotesHTML(footnotes []string, locale Locale) string {
+	if len(footnotes) == 0 {
+		return ""
+	}
+
+	var result []string
+	result = append(result, `<hr>`, `<section class="footnotes" role="doc-endnotes" aria-label="`+html.EscapeString(locale.Footnotes)+`">`, `<ol>`)
+	for i, footnote := range footnotes {
+		n := i + 1
+		result = append(result, fmt.Sprintf(`<li id="fn-%d">%s <a href="#fnref-%d" class="footnote-back" role="doc-backlink" title="%s">↩</a></li>`,
+			n, processTypography(processCommands(footnote), locale.Lang), n, html.EscapeString(locale.BackToText)))
+	}
+	result = append(result, `</ol>`, `</section>`)
+
+	return strings.Join(result, "\n")
+}
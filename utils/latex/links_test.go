@@ -0,0 +1,81 @@
+package latex
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateURL(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+		ok   bool
+	}{
+		{"https://example.org/a", "https://example.org/a", true},
+		{"HTTP://example.org", "HTTP://example.org", true},
+		{"ftp://example.org/file", "ftp://example.org/file", true},
+		{"mailto:ant@example.org", "mailto:ant@example.org", true},
+		{"docs/aco.html", "docs/aco.html", true},
+		{"#sec:intro", "#sec:intro", true},
+		{`https://example.org/a\_b\%20\#top`, "https://example.org/a_b%20#top", true},
+		{"https://example.org/?a=1&b=2", "https://example.org/?a=1&amp;b=2", true},
+		{"javascript:alert(1)", "", false},
+		{"JavaScript:alert(1)", "", false},
+		{"  javascript:alert(1)", "", false},
+		{"vbscript:msgbox", "", false},
+		{"data:text/html,<b>x</b>", "", false},
+		{"http:/example.org", "", false},
+		{`https://example.org/"onclick="x`, "", false},
+		{"https://example.org/a b", "", false},
+		{"", "", false},
+	}
+	for _, test := range tests {
+		got, ok := validateURL(test.raw)
+		if got != test.want || ok != test.ok {
+			t.Errorf("validateURL(%q) = %q, %v; ожидалось %q, %v", test.raw, got, ok, test.want, test.ok)
+		}
+	}
+}
+
+func TestProcessLinks(t *testing.T) {
+	tests := []struct {
+		name  string
+		latex string
+		want  string
+	}{
+		{"href", `\href{https://example.org}{сайт}`, `<a href="https://example.org">сайт</a>`},
+		{"href с javascript:", `\href{javascript:alert(1)}{сайт}`, `сайт`},
+		{"href с data:", `\href{data:text/html,x}{сайт}`, `сайт`},
+		{"url", `\url{https://example.org/a\_b}`, `<a href="https://example.org/a_b" class="url">https://example.org/a_b</a>`},
+		{"url с javascript:", `\url{javascript:alert("x")}`, `<code>javascript:alert(&#34;x&#34;)</code>`},
+		{"hyperref", `\hyperref[sec:a]{см. раздел}`, `<a href="#sec:a">см. раздел</a>`},
+		{"незавершенная команда в конце текста", `\href{https://example.org}`, `\href{https://example.org}`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := processLinks(test.latex); got != test.want {
+				t.Errorf("получено %q, ожидалось %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestFootnotes(t *testing.T) {
+	html := convert(t, document(`Первая\footnote{См. \href{https://example.org}{сайт}.} и вторая\footnote{Текст}.`), "ru").HTML
+	for _, want := range []string{
+		`<sup class="footnote-ref" id="fnref-1"><a href="#fn-1" role="doc-noteref">1</a></sup>`,
+		`<sup class="footnote-ref" id="fnref-2"><a href="#fn-2" role="doc-noteref">2</a></sup>`,
+		`<section class="footnotes" role="doc-endnotes" aria-label="Примечания">`,
+		`<li id="fn-1">См. <a href="https://example.org">сайт</a>.`,
+		`<li id="fn-2">Текст <a href="#fnref-2" class="footnote-back" role="doc-backlink" title="Вернуться к тексту">↩</a></li>`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("нет %q", want)
+		}
+	}
+
+	html = convert(t, document(`\href{javascript:alert(1)}{ссылка} и \url{javascript:void(0)}`), "ru").HTML
+	if strings.Contains(html, `href="javascript:`) {
+		t.Error("ссылка javascript: попала в страницу")
+	}
+}
@@ -0,0 +1,294 @@
+package latex
+
+import (
+	"fmt"
+	"html"
+	"strings"
+)
+
+// markdownRenderer формирует CommonMark с формулами $...$ и $$...$$ по модели документа
+type markdownRenderer struct {
+	locale       Locale
+	environments map[string]TextEnvironment
+	labels       map[string]string
+	footnotes    []string
+	out          strings.Builder
+}
+
+// ConvertLatexToMarkdown конвертирует LaTeX в Markdown через модель документа
+func ConvertLatexToMarkdown(latex string, opts Options) (string, error) {
+	if opts.Locale.Lang == "" {
+		opts.Locale = locales[DefaultLang]
+	}
+	return renderMarkdown(ParseDocument(latex, opts), opts.Locale, opts.textEnvironments()), nil
+}
+
+// renderMarkdown формирует Markdown документ
+func renderMarkdown(doc *Document, locale Locale, environments map[string]TextEnvironment) string {
+	r := &markdownRenderer{locale: locale, environments: environments, labels: collectLabels(doc.Children)}
+
+	r.out.WriteString("# " + markdownText(doc.Metadata.Title, locale.Lang) + "\n\n")
+	if len(doc.Metadata.Authors) > 0 {
+		authors := make([]string, len(doc.Metadata.Authors))
+		for i, author := range doc.Metadata.Authors {
+			authors[i] = markdownText(author, locale.Lang)
+		}
+		r.out.WriteString("*" + strings.Join(authors, ", ") + "*\n\n")
+	}
+
+	r.blocks(doc.Children, "")
+
+	// Сноски и источники оформляются как сноски Markdown в конце документа
+	for i, footnote := range r.footnotes {
+		r.out.WriteString(fmt.Sprintf("[^fn%d]: %s\n", i+1, footnote))
+	}
+	if len(r.footnotes) > 0 {
+		r.out.WriteString("\n")
+	}
+	for _, ref := range doc.References {
+		text := markdownText(ref.Text, locale.Lang)
+		if ref.DOI != "" {
+			text = strings.Replace(text, markdownText(ref.DOI, locale.Lang), "[doi:"+ref.DOI+"](https://doi.org/"+ref.DOI+")", 1)
+		}
+		r.out.WriteString(fmt.Sprintf("[^%d]: %s\n", ref.Number, text))
+	}
+
+	return strings.TrimRight(r.out.String(), "\n") + "\n"
+}
+
+// collectLabels собирает номера помеченных формул и теорем
+func collectLabels(nodes []*Node) map[string]string {
+	labels := make(map[string]string)
+	var walk func(nodes []*Node)
+	walk = func(nodes []*Node) {
+		for _, node := range nodes {
+			if node.Label != "" {
+				labels[node.Label] = node.Number
+			}
+			walk(node.Children)
+		}
+	}
+	walk(nodes)
+	return labels
+}
+
+// blocks выводит блочные узлы; prefix добавляется к каждой строке (для цитат)
+func (r *markdownRenderer) blocks(nodes []*Node, prefix string) {
+	for _, node := range nodes {
+		var block string
+
+		switch node.Type {
+		case "section":
+			block = strings.Repeat("#", node.Level+1) + " " + markdownText(node.Text, r.locale.Lang)
+
+		case "paragraph":
+			block = r.inline(node.Children)
+
+		case "math":
+			block = markdownDisplayMath(node)
+
+		case "algorithm":
+			block = r.algorithm(node)
+
+		case "code":
+			block = "```" + languageAliases[strings.ToLower(node.Language)] + "\n" + node.Text + "\n```"
+			if node.Caption != "" {
+				block = "**" + node.Caption + "**\n\n" + block
+			}
+
+		case "theorem":
+			head := "**" + node.Text
+			if node.Number != "" {
+				head += " " + node.Number
+			}
+			head += "**"
+			if node.Title != "" {
+				head += " (" + markdownText(node.Title, r.locale.Lang) + ")"
+			}
+			r.out.WriteString(prefix + "> " + head + ".\n" + prefix + ">\n")
+			r.blocks(node.Children, prefix+"> ")
+			r.out.WriteString("\n")
+			continue
+
+		case "proof":
+			head := r.locale.Proof
+			if node.Title != "" {
+				head = node.Title
+			}
+			r.out.WriteString(prefix + "*" + head + ".*\n\n")
+			r.blocks(node.Children, prefix)
+			r.out.WriteString(prefix + "∎\n\n")
+			continue
+
+		case "environment":
+			// Цитаты из реестра текстовых окружений оформляются как цитаты Markdown
+			env := r.environments[node.Name]
+			inner := prefix
+			if env.Element == "blockquote" {
+				inner += "> "
+			}
+			if env.Title != "" {
+				r.out.WriteString(inner + "**" + env.Title + "**\n" + strings.TrimRight(inner, " ") + "\n")
+			}
+			r.blocks(node.Children, inner)
+			if inner != prefix {
+				r.out.WriteString("\n")
+			}
+			continue
+
+		default:
+			r.blocks(node.Children, prefix)
+			continue
+		}
+
+		lines := strings.Split(block, "\n")
+		for i := range lines {
+			lines[i] = prefix + lines[i]
+		}
+		r.out.WriteString(strings.Join(lines, "\n") + "\n" + strings.TrimRight(prefix, " ") + "\n")
+	}
+}
+
+// inline выводит строчные узлы абзаца
+func (r *markdownRenderer) inline(nodes []*Node) string {
+	var out strings.Builder
+
+	for _, node := range nodes {
+		switch node.Type {
+		case "text":
+			out.WriteString(markdownText(node.Text, r.locale.Lang))
+		case "math":
+			if node.Display {
+				out.WriteString("$$" + node.TeX + "$$")
+			} else {
+				out.WriteString("$" + node.TeX + "$")
+			}
+		case "cite":
+			for _, target := range node.Targets {
+				out.WriteString("[^" + target + "]")
+			}
+		case "ref":
+			number, ok := r.labels[node.Targets[0]]
+			if !ok {
+				number = "??"
+			}
+			if node.Name == "eqref" {
+				number = "(" + number + ")"
+			}
+			out.WriteString(number)
+		case "footnote":
+			r.footnotes = append(r.footnotes, r.inline(node.Children))
+			out.WriteString(fmt.Sprintf("[^fn%d]", len(r.footnotes)))
+		case "link":
+			// Недопустимый адрес (javascript: и т.п.) выводится только текстом, как в HTML
+			text := markdownText(node.Text, r.locale.Lang)
+			if link, ok := markdownURL(node.URL); ok {
+				out.WriteString("[" + text + "](" + link + ")")
+			} else {
+				out.WriteString(text)
+			}
+		case "code":
+			out.WriteString("`" + node.Text + "`")
+		case "image":
+			if link, ok := markdownURL(node.URL); ok {
+				out.WriteString("![" + markdownText(node.Text, r.locale.Lang) + "](" + link + ")")
+			} else {
+				out.WriteString(markdownText(node.Text, r.locale.Lang))
+			}
+		}
+	}
+
+	return strings.TrimSpace(out.String())
+}
+
+// algorithm выводит алгоритм блоком псевдокода
+func (r *markdownRenderer) algorithm(node *Node) string {
+	var lines []string
+	if node.Caption != "" {
+		lines = append(lines, "**"+r.locale.Algorithm+"** "+markdownText(node.Caption, r.locale.Lang), "")
+	}
+	lines = append(lines, "```text")
+	lines = append(lines, r.algorithmSteps(node.Children, 0)...)
+	lines = append(lines, "```")
+	return strings.Join(lines, "\n")
+}
+
+// algorithmSteps выводит шаги алгоритма с отступом по уровню вложенности
+func (r *markdownRenderer) algorithmSteps(steps []*Node, level int) []string {
+	var lines []string
+	indent := strings.Repeat("    ", level)
+
+	for _, step := range steps {
+		text := whitespaceRunRe.ReplaceAllString(step.Text, " ")
+
+		switch step.Name {
+		case "input":
+			lines = append(lines, indent+r.locale.Input+" "+text)
+		case "output":
+			lines = append(lines, indent+r.locale.Output+" "+text)
+		case "init":
+			lines = append(lines, indent+r.locale.Init+" "+text)
+		case "for":
+			lines = append(lines, indent+r.locale.For+" "+text+" "+r.locale.Do)
+		case "foreach":
+			lines = append(lines, indent+r.locale.ForEach+" "+text+" "+r.locale.Do)
+		case "while":
+			lines = append(lines, indent+r.locale.While+" "+text+" "+r.locale.Do)
+		case "if", "elseif":
+			keyword := r.locale.If
+			if step.Name == "elseif" {
+				keyword = r.locale.Else + " " + r.locale.If
+			}
+			lines = append(lines, indent+keyword+" "+text+" "+r.locale.Then)
+		case "else":
+			lines = append(lines, indent+r.locale.Else)
+		case "return":
+			lines = append(lines, indent+r.locale.Return+" "+text)
+		case "comment":
+			lines = append(lines, indent+"// "+text)
+		default:
+			lines = append(lines, indent+text)
+		}
+
+		lines = append(lines, r.algorithmSteps(step.Children, level+1)...)
+	}
+
+	return lines
+}
+
+// markdownDisplayMath выводит выключную формулу, номер передается через \tag
+func markdownDisplayMath(node *Node) string {
+	tex := node.TeX
+	if node.Number != "" {
+		tex += " \\tag{" + node.Number + "}"
+	}
+	return "$$\n" + tex + "\n$$"
+}
+
+// markdownText переводит текстовые команды TeX в разметку Markdown
+func markdownText(text, lang string) string {
+	text = processTypography(text, lang)
+	// < экранируется, чтобы HTML теги из исходника не попали в разметку как есть
+	text = strings.NewReplacer("*", `\*`, "_", `\_`, "`", "\\`", "<", `\<`).Replace(text)
+
+	text = replaceCommand(text, "textbf", 1, func(args []string) string { return "**" + args[0] + "**" })
+	text = replaceCommand(text, "textit", 1, func(args []string) string { return "*" + args[0] + "*" })
+	text = replaceCommand(text, "emph", 1, func(args []string) string { return "*" + args[0] + "*" })
+	text = replaceCommand(text, "texttt", 1, func(args []string) string { return "`" + args[0] + "`" })
+	text = replaceCommand(text, "text", 1, func(args []string) string { return args[0] })
+
+	return whitespaceRunRe.ReplaceAllString(text, " ")
+}
+
+// markdownURLReplacer кодирует символы, которые закрыли бы (...) ссылки раньше времени
+var markdownURLReplacer = strings.NewReplacer(" ", "%20", "(", "%28", ")", "%29")
+
+// markdownURL проверяет адрес теми же правилами, что и HTML вывод (validateURL),
+// и возвращает его для записи в [текст](адрес)
+func markdownURL(raw string) (string, bool) {
+	link, ok := validateURL(raw)
+	if !ok {
+		return "", false
+	}
+	return markdownURLReplacer.Replace(html.UnescapeString(link)), true
+}
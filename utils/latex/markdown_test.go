@@ -0,0 +1,111 @@
+package latex
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConvertLatexToMarkdown(t *testing.T) {
+	tests := []struct {
+		name, lang, body string
+		want, absent     []string
+	}{
+		{
+			"заголовок и авторы", "ru",
+			`\title{Муравьиный *алгоритм*}` + "\n" + `\author{А. Иванов \and Б. Петров}`,
+			[]string{"# Муравьиный \\*алгоритм\\*\n\n*А. Иванов, Б. Петров*\n"}, nil,
+		},
+		{
+			"разделы на уровень ниже заголовка", "ru",
+			"\\section{Введение}\n\\subsection{Задача}\n\\subsubsection{Детали}",
+			[]string{"## Введение\n", "### Задача\n", "#### Детали\n"}, nil,
+		},
+		{
+			"строчная разметка", "ru",
+			`\textbf{жирный} \emph{курсив} \texttt{код} \verb|x_1| a_b 2*3 <b>`,
+			[]string{"**жирный** *курсив* `код` `x_1` a\\_b 2\\*3 \\<b>"}, nil,
+		},
+		{
+			"формулы и ссылки на них", "ru",
+			"\\begin{equation}\\label{eq:e}\nE = mc^2\n\\end{equation}\nСм. \\eqref{eq:e}, \\ref{eq:none} и $x$.",
+			[]string{"$$\nE = mc^2 \\tag{1}\n$$\n", "См. (1), ?? и $x$."}, nil,
+		},
+		{
+			"сноски и источники", "ru",
+			"Текст\\footnote{Пояснение.} [1].\n\n1. Author A. Title. 10.1000/xyz123.",
+			[]string{"Текст[^fn1] [^1].", "[^fn1]: Пояснение.\n", "[^1]: Author A. Title. [doi:10.1000/xyz123](https://doi.org/10.1000/xyz123)."}, nil,
+		},
+		{
+			"ссылки", "ru",
+			`\href{https://e.org/a?q=1&r=2}{сайт} \url{https://e.org/(x)} \href{javascript:alert(1)}{опасно}`,
+			[]string{"[сайт](https://e.org/a?q=1&r=2) [https://e.org/(x)](https://e.org/%28x%29) опасно"},
+			[]string{"javascript:"},
+		},
+		{
+			"изображение", "ru",
+			`\includegraphics[alt=Схема]{fig.png}`,
+			[]string{"![Схема](fig.png)"}, nil,
+		},
+		{
+			"листинг", "ru",
+			"\\begin{lstlisting}[language=Python, caption=Пример]\nx = 1 # да\n\\end{lstlisting}",
+			[]string{"**Пример**\n\n```python\nx = 1 # да\n```\n"}, nil,
+		},
+		{
+			"алгоритм", "en",
+			"\\begin{algorithm}\n\\caption{Search}\n\\KwIn{graph}\n\\For{$i$}{\\If{$x$}{step}}\n\\Return{path}\n\\end{algorithm}",
+			[]string{"**Algorithm:** Search\n\n```text\nInput: graph\nfor $i$ do\n    if $x$ then\n        step\nreturn path\n```\n"}, nil,
+		},
+		{
+			"теорема и доказательство", "ru",
+			"\\newtheorem{theorem}{Теорема}\n\\begin{theorem}[Ферма]\nТекст.\n\\end{theorem}\n\\begin{proof}\nОчевидно.\n\\end{proof}",
+			[]string{"> **Теорема 1** (Ферма).\n>\n> Текст.\n>\n", "*Доказательство.*\n\nОчевидно.\n\n∎\n"}, nil,
+		},
+		{
+			"цитата", "ru",
+			"\\begin{quote}\nСлова.\n\\end{quote}",
+			[]string{"> Слова.\n"}, nil,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := ConvertLatexToMarkdown(document(test.body), Options{Locale: locales[test.lang]})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range test.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("нет %q в\n%s", want, got)
+				}
+			}
+			for _, absent := range test.absent {
+				if strings.Contains(got, absent) {
+					t.Errorf("лишнее %q в\n%s", absent, got)
+				}
+			}
+		})
+	}
+}
+
+func TestMarkdownURL(t *testing.T) {
+	tests := []struct {
+		raw, want string
+		ok        bool
+	}{
+		{"https://e.org/a b", "", false},
+		{"https://e.org/f(x)", "https://e.org/f%28x%29", true},
+		{"https://e.org/?a=1&b=2", "https://e.org/?a=1&b=2", true},
+		{"fig.png", "fig.png", true},
+		{"javascript:alert(1)", "", false},
+		{" JavaScript:alert(1)", "", false},
+		{"data:text/html,x", "", false},
+	}
+	for _, test := range tests {
+		t.Run(test.raw, func(t *testing.T) {
+			got, ok := markdownURL(test.raw)
+			if got != test.want || ok != test.ok {
+				t.Errorf("получено %q, %v, ожидалось %q, %v", got, ok, test.want, test.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package latex
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ParamSpec — описание параметра из static/latex/params/*.tex.
+//
+// Файл состоит из блоков, разделенных пустой строкой:
+//
+//	имя [!!! ограничение !!!]
+//	Заголовок с формулами TeX
+//	Описание
+type ParamSpec struct {
+	Name        string `json:"name"`
+	Constraint  string `json:"constraint,omitempty"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Line        int    `json:"line"`
+	EndLine     int    `json:"endLine"`
+}
+
+// parseParamSpecs разбирает файл описаний параметров
+func parseParamSpecs(text string) []ParamSpec {
+	var specs []ParamSpec
+	var block []string
+	blockStart := 0
+
+	flush := func() {
+		if len(block) == 0 {
+			return
+		}
+		spec := ParamSpec{Line: blockStart, EndLine: blockStart + len(block) - 1}
+
+		name, constraint, _ := strings.Cut(block[0], "!!!")
+		spec.Name = strings.TrimSpace(name)
+		spec.Constraint = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(constraint), "!!!"))
+		if len(block) > 1 {
+			spec.Title = strings.TrimSpace(block[1])
+		}
+		if len(block) > 2 {
+			spec.Description = strings.TrimSpace(strings.Join(block[2:], " "))
+		}
+
+		specs = append(specs, spec)
+		block = nil
+	}
+
+	for i, line := range strings.Split(text, "\n") {
+		if strings.TrimSpace(line) == "" {
+			flush()
+			continue
+		}
+		if len(block) == 0 {
+			blockStart = i + 1
+		}
+		block = append(block, line)
+	}
+	flush()
+
+	return specs
+}
+
+// LoadDocumentParams читает описания параметров для подсказок \param. Если path не задан,
+// ищется файл params/<имя>.tex в каталоге рядом с каталогом input; его отсутствие не ошибка
+func LoadDocumentParams(path, input string) (map[string]ParamSpec, error) {
+	explicit := path != ""
+	if !explicit {
+		name := strings.TrimSuffix(filepath.Base(input), filepath.Ext(input))
+		path = filepath.Join(filepath.Dir(input), "..", "params", name+".tex")
+	}
+
+	text, err := os.ReadFile(path)
+	if !explicit && errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("чтение описаний параметров: %w", err)
+	}
+
+	params := make(map[string]ParamSpec)
+	for _, spec := range parseParamSpecs(string(text)) {
+		params[spec.Name] = spec
+	}
+	return params, nil
+}
@@ -0,0 +1,182 @@
+package latex
+
+import (
+	"encoding/json"
+	"html"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Metadata содержит сведения о документе, извлеченные из преамбулы. Заголовок, авторы
+// и дата — обычный текст без разметки: при вставке в HTML их нужно экранировать
+type Metadata struct {
+	Title         string   `json:"title,omitempty"`
+	Authors       []string `json:"authors,omitempty"`
+	Date          string   `json:"date,omitempty"`
+	DocumentClass string   `json:"documentclass,omitempty"`
+	ClassOptions  []string `json:"classoptions,omitempty"`
+	Lang          string   `json:"lang,omitempty"`
+}
+
+var (
+	documentClassRe = regexp.MustCompile(`\\documentclass(?:\[([^\]]*)\])?\{([^}]+)\}`)
+	thanksRe        = regexp.MustCompile(`\\thanks\{[^}]*\}`)
+
+	// escapedBraceReplacer прячет экранированные скобки от типографики и mathBraceReplacer
+	escapedBraceReplacer = strings.NewReplacer(`\{`, "\x01", `\}`, "\x02")
+)
+
+// extractMetadata извлекает \documentclass, \title, \author и \date; lang задает
+// типографику текста метаданных
+func extractMetadata(latex, lang string) Metadata {
+	var meta Metadata
+
+	if matches := documentClassRe.FindStringSubmatch(latex); matches != nil {
+		meta.DocumentClass = strings.TrimSpace(matches[2])
+		for _, option := range strings.Split(matches[1], ",") {
+			if option = strings.TrimSpace(option); option != "" {
+				meta.ClassOptions = append(meta.ClassOptions, option)
+			}
+		}
+	}
+
+	if title, ok := extractCommandArgument(latex, "title"); ok {
+		meta.Title = cleanMetadataText(title, lang)
+	}
+
+	if author, ok := extractCommandArgument(latex, "author"); ok {
+		for _, name := range strings.Split(author, `\and`) {
+			if name = cleanMetadataText(name, lang); name != "" {
+				meta.Authors = append(meta.Authors, name)
+			}
+		}
+	}
+
+	if date, ok := extractCommandArgument(latex, "date"); ok {
+		meta.Date = cleanMetadataText(strings.ReplaceAll(date, `\today`, time.Now().Format("02.01.2006")), lang)
+	}
+
+	return meta
+}
+
+// extractCommandArgument возвращает аргумент первой команды \name{...} с учетом вложенных скобок
+func extractCommandArgument(latex, name string) (string, bool) {
+	_, open, ok := findCommand(latex, name, 0)
+	if !ok {
+		return "", false
+	}
+
+	arg, _, ok := readBraceGroup(latex, open)
+	return arg, ok
+}
+
+// readBraceGroup читает группу {...}, начинающуюся в позиции start,
+// и возвращает ее содержимое и позицию после закрывающей скобки
+func readBraceGroup(s string, start int) (string, int, bool) {
+	if start >= len(s) || s[start] != '{' {
+		return "", start, false
+	}
+
+	depth := 0
+	for i := start; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			// Экранированные скобки не меняют глубину
+			i++
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return s[start+1 : i], i + 1, true
+			}
+		}
+	}
+
+	return "", start, false
+}
+
+// metadataTextCommands — команды оформления, от которых в метаданных остается только аргумент
+var metadataTextCommands = []string{
+	"emph", "textbf", "textit", "texttt", "textrm", "textsf", "textsc", "textsl", "textup",
+	"textnormal", "text", "mbox",
+}
+
+// cleanMetadataText превращает текст метаданных в обычный текст: убирает служебные
+// команды и команды оформления, применяет типографику и снимает HTML экранирование
+func cleanMetadataText(text, lang string) string {
+	text = thanksRe.ReplaceAllString(text, "")
+	text = strings.ReplaceAll(text, `\\`, " ")
+
+	// Вложенные команды оформления раскрываются за несколько проходов
+	for unwrapped := ""; unwrapped != text; {
+		unwrapped = text
+		for _, name := range metadataTextCommands {
+			text = replaceCommand(text, name, 1, func(args []string) string { return args[0] })
+		}
+	}
+	text = processTypography(escapedBraceReplacer.Replace(text), lang)
+
+	// Группирующие скобки убираем, экранированные оставляем как символы
+	text = mathBraceReplacer.Replace(text)
+	text = whitespaceRunRe.ReplaceAllString(html.UnescapeString(text), " ")
+	return strings.TrimSpace(text)
+}
+
+// removeCommand удаляет из текста все команды \name{...} вместе с аргументом
+func removeCommand(content, name string) string {
+	return replaceCommand(content, name, 1, func(args []string) string {
+		return ""
+	})
+}
+
+// processMaketitle заменяет \maketitle блоком заголовка
+func processMaketitle(content string, meta Metadata) (string, bool) {
+	// Команды метаданных могут стоять и после \begin{document}
+	for _, name := range []string{"title", "author", "date"} {
+		content = removeCommand(content, name)
+	}
+
+	if !strings.Contains(content, `\maketitle`) {
+		return content, false
+	}
+
+	var block []string
+	block = append(block, `<header class="title-block">`)
+	block = append(block, `<h1>`+html.EscapeString(meta.Title)+`</h1>`)
+	if len(meta.Authors) > 0 {
+		block = append(block, `<div class="title-authors">`+html.EscapeString(strings.Join(meta.Authors, ", "))+`</div>`)
+	}
+	if meta.Date != "" {
+		block = append(block, `<div class="title-date">`+html.EscapeString(meta.Date)+`</div>`)
+	}
+	block = append(block, `</header>`)
+
+	// Пустые строки вокруг блока не дают ему попасть внутрь абзаца
+	content = strings.Replace(content, `\maketitle`, "\n\n"+strings.Join(block, "\n")+"\n\n", 1)
+	content = strings.ReplaceAll(content, `\maketitle`, "")
+	return content, true
+}
+
+// generateMetaTags формирует <meta> теги по метаданным документа
+func generateMetaTags(meta Metadata) string {
+	var tags []string
+	for _, author := range meta.Authors {
+		tags = append(tags, `<meta name="author" content="`+html.EscapeString(author)+`">`)
+	}
+	if meta.Date != "" {
+		tags = append(tags, `<meta name="date" content="`+html.EscapeString(meta.Date)+`">`)
+	}
+
+	return strings.Join(tags, "\n    ")
+}
+
+// GenerateFrontMatter формирует JSON front-matter для сборщика сайта
+func GenerateFrontMatter(meta Metadata) (string, error) {
+	data, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data) + "\n", nil
+}
@@ -0,0 +1,96 @@
+package latex
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExtractMetadata(t *testing.T) {
+	tests := []struct {
+		name     string
+		preamble string
+		want     Metadata
+	}{
+		{"класс с опциями", `\documentclass[12pt, a4paper]{article}`,
+			Metadata{DocumentClass: "article", ClassOptions: []string{"12pt", "a4paper"}}},
+		{"заголовок с оформлением", `\title{Муравьи --- \textbf{\emph{колонии}}}`,
+			Metadata{Title: "Муравьи — колонии"}},
+		{"экранированные скобки и спецсимволы", `\title{Множество \{x\} и A < B \& "C"}`,
+			Metadata{Title: `Множество {x} и A < B & "C"`}},
+		{"вложенные скобки в аргументе", `\title{{Роевой} интеллект}`, Metadata{Title: "Роевой интеллект"}},
+		{"авторы через \\and и \\thanks", `\author{Иван Иванов\thanks{МГУ} \and J.~Smith \\ Lab}`,
+			Metadata{Authors: []string{"Иван Иванов", "J.\u00a0Smith Lab"}}},
+		{"пустой автор пропускается", `\author{А \and \and Б}`, Metadata{Authors: []string{"А", "Б"}}},
+		{"дата", `\date{1 мая 2024}`, Metadata{Date: "1 мая 2024"}},
+		{"без команд", `\usepackage{amsmath}`, Metadata{}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := extractMetadata(test.preamble, "ru"); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("получено %#v, ожидалось %#v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestTitleEscaping(t *testing.T) {
+	result := convert(t, `\documentclass{article}
+\title{A < B & "C" <script>}
+\author{X <b>Y</b> \and Z}
+\date{\textbf{2024}}
+\begin{document}
+\maketitle
+Текст.
+\end{document}`, "en")
+
+	if want := `A < B & "C" <script>`; result.Metadata.Title != want {
+		t.Errorf("Metadata.Title = %q, ожидалось %q", result.Metadata.Title, want)
+	}
+	for _, want := range []string{
+		`<title>A &lt; B &amp; &#34;C&#34; &lt;script&gt;</title>`,
+		`<meta name="author" content="X &lt;b&gt;Y&lt;/b&gt;">`,
+		`<meta name="author" content="Z">`,
+		`<meta name="date" content="2024">`,
+		`<h1>A &lt; B &amp; &#34;C&#34; &lt;script&gt;</h1>`,
+		`<div class="title-authors">X &lt;b&gt;Y&lt;/b&gt;, Z</div>`,
+		`<div class="title-date">2024</div>`,
+	} {
+		if !strings.Contains(result.HTML, want) {
+			t.Errorf("нет %q", want)
+		}
+	}
+	if strings.Contains(result.HTML, "<script>}") || strings.Contains(result.HTML, "<b>Y") {
+		t.Error("метаданные попали в HTML без экранирования")
+	}
+	if strings.Count(result.HTML, `class="title-block"`) != 1 {
+		t.Error("блок заголовка должен выводиться один раз")
+	}
+}
+
+func TestTitleOption(t *testing.T) {
+	// Options.Title заменяет \title, без обоих берется строка словаря
+	locale, err := LoadLocale("ru", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name, title, latex, want string
+	}{
+		{"из преамбулы", "", `\title{Из файла}`, "Из файла"},
+		{"из опций", "Из <опций>", `\title{Из файла}`, "Из <опций>"},
+		{"по умолчанию", "", "", "Конвертированный документ"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			latex := "\\documentclass{article}\n" + test.latex + "\n\\begin{document}\nТекст.\n\\end{document}"
+			result, err := ConvertLatexToHTML(latex, Options{Title: test.title, Locale: locale})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Metadata.Title != test.want {
+				t.Errorf("заголовок %q, ожидался %q", result.Metadata.Title, test.want)
+			}
+		})
+	}
+}
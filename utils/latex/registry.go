@@ -0,0 +1,279 @@
+package latex
+
+import (
+	"fmt"
+	"html"
+	"log"
+	"strings"
+)
+
+// CommandHandler формирует HTML для команды; args — аргументы в порядке argSpec,
+// отсутствующий необязательный аргумент передается пустой строкой
+type CommandHandler func(ctx *RenderContext, args []string) string
+
+// EnvironmentHandler формирует HTML для окружения. Вложенные окружения из реестра
+// в env.Body обрабатываются, только если обработчик вызовет ctx.Environments
+type EnvironmentHandler func(ctx *RenderContext, env Environment) string
+
+// Environment — разобранное окружение \begin{Name}[Option] Body \end{Name}
+type Environment struct {
+	Name   string
+	Option string
+	Body   string
+}
+
+type registeredCommand struct {
+	argSpec string
+	handler CommandHandler
+}
+
+// Реестры заполняются при запуске программы и во время конвертации не меняются:
+// встроенные обработчики регистрируются в init ниже, обработчики импортирующего
+// пакета — в его init или в main до первой конвертации
+var (
+	commandRegistry     = make(map[string]registeredCommand)
+	environmentRegistry = make(map[string]EnvironmentHandler)
+)
+
+func init() {
+	RegisterEnvironment("algorithm", renderAlgorithm)
+	RegisterEnvironment("equation", renderEquation)
+	RegisterEnvironment("cases", renderCases)
+	RegisterCommand("param", "om", renderParam)
+}
+
+// RegisterCommand регистрирует обработчик команды \name. Каждая буква argSpec
+// описывает аргумент: m — обязательный {…}, o — необязательный […].
+// Команда без обязательного аргумента остается в тексте как есть.
+// Регистрация допустима только до начала конвертации: реестр читается без блокировок
+func RegisterCommand(name, argSpec string, handler CommandHandler) {
+	if !htmlNameRe.MatchString(strings.ToLower(name)) || strings.Trim(argSpec, "mo") != "" {
+		panic(fmt.Sprintf("RegisterCommand: недопустимое имя %q или описание аргументов %q", name, argSpec))
+	}
+	commandRegistry[name] = registeredCommand{argSpec: argSpec, handler: handler}
+}
+
+// RegisterEnvironment регистрирует обработчик окружения name.
+// Регистрация допустима только до начала конвертации: реестр читается без блокировок
+func RegisterEnvironment(name string, handler EnvironmentHandler) {
+	environmentRegistry[name] = handler
+}
+
+// RenderContext дает обработчикам доступ к языку, параметрам и общим счетчикам документа
+type RenderContext struct {
+	Locale Locale
+
+	opts  Options
+	state *documentState
+}
+
+// Next увеличивает счетчик counter и возвращает новый номер.
+// Счетчики общие для всего документа, в том числе с теоремами из \newtheorem
+func (ctx *RenderContext) Next(counter string) int {
+	ctx.state.counters[counter]++
+	return ctx.state.counters[counter]
+}
+
+// SetLabel связывает метку с номером, который подставляет \ref
+func (ctx *RenderContext) SetLabel(label, number string) {
+	ctx.state.labels[label] = number
+}
+
+// Param возвращает описание параметра симуляции из Options.Params
+func (ctx *RenderContext) Param(name string) (ParamSpec, bool) {
+	spec, ok := ctx.opts.Params[name]
+	return spec, ok
+}
+
+// Environments обрабатывает окружения из реестра внутри тела другого окружения
+func (ctx *RenderContext) Environments(body string) string {
+	return processRegisteredEnvironments(body, ctx)
+}
+
+// processRegisteredEnvironments заменяет окружения из реестра.
+// Формулы в тексте пропускаются, поэтому cases внутри $…$ остается MathJax
+func processRegisteredEnvironments(content string, ctx *RenderContext) string {
+	var out strings.Builder
+	textStart := 0
+	for i := 0; i < len(content); {
+		if skip := protectedSpanLength(content[i:]); skip > 0 {
+			i += skip
+			continue
+		}
+		if content[i] != '\\' || !strings.HasPrefix(content[i:], `\begin{`) {
+			i++
+			continue
+		}
+
+		nameEnd := strings.IndexByte(content[i:], '}')
+		if nameEnd < 0 {
+			break
+		}
+		name := content[i+len(`\begin{`) : i+nameEnd]
+		handler, ok := environmentRegistry[name]
+		if !ok {
+			i += nameEnd + 1
+			continue
+		}
+		bodyStart, bodyEnd, end, found := matchEnvironment(content, name, i+nameEnd+1)
+		if !found {
+			i += nameEnd + 1
+			continue
+		}
+
+		env := Environment{Name: name, Body: content[bodyStart:bodyEnd]}
+		if bodyStart > i+nameEnd+1 {
+			env.Option = content[i+nameEnd+2 : bodyStart-1]
+		}
+
+		out.WriteString(content[textStart:i])
+		out.WriteString(handler(ctx, env))
+		i = end
+		textStart = i
+	}
+	if textStart == 0 {
+		return content
+	}
+	out.WriteString(content[textStart:])
+
+	return out.String()
+}
+
+// matchEnvironment находит \end{name}, парный окружению, открытому перед from.
+// Необязательный аргумент […] сразу после \begin не входит в тело
+func matchEnvironment(content, name string, from int) (bodyStart, bodyEnd, end int, ok bool) {
+	bodyStart = from
+	if strings.HasPrefix(content[from:], "[") {
+		if close := strings.IndexByte(content[from:], ']'); close >= 0 {
+			bodyStart = from + close + 1
+		}
+	}
+
+	begin, finish := `\begin{`+name+`}`, `\end{`+name+`}`
+	depth := 1
+	for pos := bodyStart; ; {
+		next := strings.Index(content[pos:], finish)
+		if next < 0 {
+			return 0, 0, 0, false
+		}
+		if nested := strings.Index(content[pos:], begin); nested >= 0 && nested < next {
+			depth++
+			pos += nested + len(begin)
+			continue
+		}
+		depth--
+		if depth == 0 {
+			return bodyStart, pos + next, pos + next + len(finish), true
+		}
+		pos += next + len(finish)
+	}
+}
+
+// processRegisteredCommands заменяет команды из реестра вне формул и тегов
+func processRegisteredCommands(content string, ctx *RenderContext) string {
+	if len(commandRegistry) == 0 {
+		return content
+	}
+
+	var out strings.Builder
+	textStart := 0
+	for i := 0; i < len(content); {
+		if skip := protectedSpanLength(content[i:]); skip > 0 {
+			i += skip
+			continue
+		}
+		if content[i] != '\\' {
+			i++
+			continue
+		}
+
+		nameEnd := i + 1
+		for nameEnd < len(content) && isLetter(content[nameEnd]) {
+			nameEnd++
+		}
+		command, ok := commandRegistry[content[i+1:nameEnd]]
+		if !ok {
+			// Экранированный символ вроде \\ пропускается целиком
+			i = max(nameEnd, i+2)
+			continue
+		}
+		args, end, ok := readCommandArguments(content, nameEnd, command.argSpec)
+		if !ok {
+			i = nameEnd
+			continue
+		}
+
+		out.WriteString(content[textStart:i])
+		out.WriteString(command.handler(ctx, args))
+		i = end
+		textStart = i
+	}
+	if textStart == 0 {
+		return content
+	}
+	out.WriteString(content[textStart:])
+
+	return out.String()
+}
+
+// readCommandArguments читает аргументы команды по описанию argSpec начиная с pos
+func readCommandArguments(content string, pos int, argSpec string) ([]string, int, bool) {
+	args := make([]string, 0, len(argSpec))
+	for _, kind := range argSpec {
+		next := pos
+		for next < len(content) && (content[next] == ' ' || content[next] == '\t') {
+			next++
+		}
+
+		switch {
+		case kind == 'o' && next < len(content) && content[next] == '[':
+			close := strings.IndexByte(content[next:], ']')
+			if close < 0 {
+				return nil, 0, false
+			}
+			args = append(args, content[next+1:next+close])
+			pos = next + close + 1
+		case kind == 'o':
+			args = append(args, "")
+		default:
+			arg, end, ok := readBraceGroup(content, next)
+			if !ok {
+				return nil, 0, false
+			}
+			args = append(args, arg)
+			pos = end
+		}
+	}
+	return args, pos, true
+}
+
+// renderParam выводит \param[текст]{имя} как ссылку на параметр симуляции
+// с подсказкой из params/*.tex. Без текста показывается обозначение параметра
+func renderParam(ctx *RenderContext, args []string) string {
+	text, name := args[0], strings.TrimSpace(args[1])
+	if text == "" {
+		switch {
+		case mathSymbolNames[name] != "":
+			text = `$\` + name + `$`
+		case len(name) == 1:
+			text = `$` + name + `$`
+		default:
+			text = `<code>` + html.EscapeString(name) + `</code>`
+		}
+	}
+
+	spec, ok := ctx.Param(name)
+	if !ok {
+		if ctx.opts.Params != nil {
+			log.Printf("Предупреждение: неизвестный параметр %q", name)
+		}
+		return `<span class="param" data-tooltip="` + html.EscapeString(name) + `">` + text + `</span>`
+	}
+
+	tooltip := `<strong>` + spec.Title + `</strong>`
+	if spec.Description != "" {
+		tooltip += `<br>` + spec.Description
+	}
+	return `<span class="param tooltip-label" data-tooltip="` + html.EscapeString(name) + `" tabindex="0">` + text +
+		`<span class="param-tooltip" role="tooltip">` + tooltip + `</span></span>`
+}
@@ -0,0 +1,245 @@
+package latex
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// SearchDocument — описание алгоритма, попавшее в поисковый индекс
+type SearchDocument struct {
+	Name  string `json:"name"` // имя файла без расширения: aco, boids, sds
+	Title string `json:"title"`
+}
+
+// SearchEntry — фрагмент, который возвращает поиск.
+// URL задан относительно каталога templates
+type SearchEntry struct {
+	Doc     int    `json:"doc"`
+	Kind    string `json:"kind"` // section, paragraph, algorithm или param
+	Param   string `json:"param,omitempty"`
+	Title   string `json:"title"`
+	Text    string `json:"text"`
+	Source  string `json:"source"`
+	Line    int    `json:"line"`
+	EndLine int    `json:"endLine"`
+	URL     string `json:"url"`
+}
+
+// SearchIndex — фрагменты документов и обратный индекс основ слов
+type SearchIndex struct {
+	Documents []SearchDocument `json:"documents"`
+	Entries   []SearchEntry    `json:"entries"`
+	Terms     map[string][]int `json:"terms"`
+}
+
+// searchStopWords не попадают в индекс; список совпадает со static/js/search.js
+var searchStopWords = map[string]bool{
+	"и": true, "в": true, "во": true, "на": true, "с": true, "со": true, "по": true, "для": true,
+	"не": true, "что": true, "как": true, "из": true, "к": true, "а": true, "о": true, "от": true,
+	"при": true, "же": true, "или": true, "это": true, "то": true, "его": true, "ее": true,
+	"их": true, "за": true, "до": true, "но": true, "у": true, "the": true, "of": true, "and": true,
+}
+
+var texCommandRe = regexp.MustCompile(`\\[a-zA-Z]+\*?`)
+
+// BuildSearchIndex строит поисковый индекс по root/descriptions/*.tex и root/params/*.tex
+func BuildSearchIndex(root string, opts Options) (*SearchIndex, error) {
+	if opts.Locale.Lang == "" {
+		opts.Locale = locales[DefaultLang]
+	}
+	index := &SearchIndex{Terms: make(map[string][]int)}
+	docs := make(map[string]int)
+
+	document := func(name string) int {
+		if i, ok := docs[name]; ok {
+			return i
+		}
+		index.Documents = append(index.Documents, SearchDocument{Name: name, Title: name})
+		docs[name] = len(index.Documents) - 1
+		return docs[name]
+	}
+
+	descriptions, err := filepath.Glob(filepath.Join(root, "descriptions", "*.tex"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(descriptions)
+	for _, path := range descriptions {
+		latex, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("чтение описания: %w", err)
+		}
+
+		name := strings.TrimSuffix(filepath.Base(path), ".tex")
+		doc := ParseDocument(string(latex), opts)
+		i := document(name)
+		if _, ok := extractCommandArgument(stripComments(string(latex)), "title"); ok {
+			index.Documents[i].Title = doc.Metadata.Title
+		}
+
+		source := filepath.ToSlash(filepath.Join("descriptions", filepath.Base(path)))
+		index.addDescription(i, source, "descriptions/"+name+".html", doc, opts.Locale.Lang)
+	}
+
+	params, err := filepath.Glob(filepath.Join(root, "params", "*.tex"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(params)
+	for _, path := range params {
+		text, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("чтение параметров: %w", err)
+		}
+
+		name := strings.TrimSuffix(filepath.Base(path), ".tex")
+		i := document(name)
+		source := filepath.ToSlash(filepath.Join("params", filepath.Base(path)))
+		for _, spec := range parseParamSpecs(string(text)) {
+			entry := SearchEntry{
+				Doc:     i,
+				Kind:    "param",
+				Param:   spec.Name,
+				Title:   searchText(spec.Title, opts.Locale.Lang),
+				Text:    searchText(spec.Description, opts.Locale.Lang),
+				Source:  source,
+				Line:    spec.Line,
+				EndLine: spec.EndLine,
+				URL:     name + ".html",
+			}
+			index.add(entry, strings.ToLower(spec.Name))
+		}
+	}
+
+	return index, nil
+}
+
+// addDescription добавляет в индекс разделы, абзацы и алгоритмы описания
+func (index *SearchIndex) addDescription(doc int, source, url string, document *Document, lang string) {
+	sectionTitle := index.Documents[doc].Title
+	anchor := ""
+	sections := 0
+
+	var walk func(nodes []*Node)
+	walk = func(nodes []*Node) {
+		for _, node := range nodes {
+			entry := SearchEntry{Doc: doc, Source: source, Line: node.Pos.Line, EndLine: node.Pos.EndLine}
+
+			switch node.Type {
+			case "section":
+				// Якоря совпадают с теми, что выставляет processSections
+				sections++
+				anchor = fmt.Sprintf("section-%d", sections)
+				if node.Label != "" {
+					anchor = node.Label
+				}
+				sectionTitle = searchText(node.Text, lang)
+				entry.Kind, entry.Title = "section", sectionTitle
+
+			case "paragraph":
+				entry.Kind, entry.Title = "paragraph", sectionTitle
+				entry.Text = inlineSearchText(node.Children, lang)
+
+			case "algorithm":
+				var steps []string
+				var collect func([]*Node)
+				collect = func(nodes []*Node) {
+					for _, step := range nodes {
+						steps = append(steps, searchText(step.Text, lang))
+						collect(step.Children)
+					}
+				}
+				collect(node.Children)
+				entry.Kind, entry.Title = "algorithm", searchText(node.Caption, lang)
+				entry.Text = strings.Join(steps, "; ")
+
+			default:
+				walk(node.Children)
+				continue
+			}
+
+			entry.URL = url
+			if anchor != "" {
+				entry.URL += "#" + anchor
+			}
+			index.add(entry)
+		}
+	}
+	walk(document.Children)
+}
+
+// add добавляет фрагмент и основы его слов в обратный индекс
+func (index *SearchIndex) add(entry SearchEntry, extraTerms ...string) {
+	id := len(index.Entries)
+	index.Entries = append(index.Entries, entry)
+
+	seen := make(map[string]bool)
+	for _, term := range append(tokenizeSearchText(entry.Title+" "+entry.Text), extraTerms...) {
+		if seen[term] {
+			continue
+		}
+		seen[term] = true
+		index.Terms[term] = append(index.Terms[term], id)
+	}
+}
+
+// searchText переводит фрагмент TeX в читаемый текст: формулы заменяются описанием mathAltText
+func searchText(tex, lang string) string {
+	return inlineSearchText(parseInline(tex, 0), lang)
+}
+
+// inlineSearchText собирает текст строчных узлов; ссылки на источники и метки опускаются
+func inlineSearchText(nodes []*Node, lang string) string {
+	var out strings.Builder
+
+	for _, node := range nodes {
+		switch node.Type {
+		case "text":
+			text := processTypography(node.Text, lang)
+			text = strings.ReplaceAll(text, "&amp;", "&")
+			text = texCommandRe.ReplaceAllString(text, "")
+			out.WriteString(strings.NewReplacer("{", "", "}", "").Replace(text))
+		case "math":
+			out.WriteString(mathAltText(node.TeX))
+		case "footnote":
+			out.WriteString(" " + inlineSearchText(node.Children, lang))
+		case "link", "code", "image":
+			out.WriteString(node.Text)
+		}
+	}
+
+	return strings.TrimSpace(whitespaceRunRe.ReplaceAllString(out.String(), " "))
+}
+
+// tokenizeSearchText разбивает текст на слова и приводит русские слова к основе.
+// Правила совпадают с tokenize из static/js/search.js
+func tokenizeSearchText(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+
+	var terms []string
+	for _, word := range words {
+		word = strings.ReplaceAll(word, "ё", "е")
+		if searchStopWords[word] || (len(word) == 1 && word[0] < 0x80) {
+			continue
+		}
+		terms = append(terms, stemRussian(word))
+	}
+	return terms
+}
+
+// ConvertSearchIndex строит поисковый индекс и сериализует его в JSON
+func ConvertSearchIndex(root string, opts Options) ([]byte, error) {
+	index, err := BuildSearchIndex(root, opts)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(index)
+}
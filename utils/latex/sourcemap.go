@@ -0,0 +1,204 @@
+package latex
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// SourceBlock связывает блочный элемент страницы со строками исходного .tex
+type SourceBlock struct {
+	Line    int    `json:"line"`
+	EndLine int    `json:"endLine"`
+	Tag     string `json:"tag"`
+	ID      string `json:"id,omitempty"`
+}
+
+// SourceMap — содержимое файла карты исходника, который пишется рядом со страницей.
+// Блоки перечислены в порядке их появления на странице
+type SourceMap struct {
+	Version int           `json:"version"`
+	File    string        `json:"file"`
+	Source  string        `json:"source"`
+	Blocks  []SourceBlock `json:"blocks"`
+}
+
+// DefaultEditorURL открывает файл в VS Code; {path} — абсолютный путь к .tex, {line} — строка
+const DefaultEditorURL = "vscode://file/{path}:{line}"
+
+var (
+	htmlTagRe = regexp.MustCompile(`^<(/?)([a-zA-Z][a-zA-Z0-9]*)(\s[^<>]*)?>`)
+	tagIDRe   = regexp.MustCompile(`\sid="([^"]*)"`)
+
+	voidElements = map[string]bool{"br": true, "hr": true, "img": true, "input": true, "wbr": true, "source": true}
+)
+
+// annotateSourceLine добавляет атрибут data-src-line каждому элементу верхнего уровня
+// в HTML блока и запоминает эти элементы в карте исходника. Блок может дать несколько
+// элементов, например абзац с окружением center посреди строки: все они получают
+// строки блока
+func (c *htmlConverter) annotateSourceLine(content string, line, endLine int) string {
+	var out strings.Builder
+	last := 0
+	for _, tag := range topLevelTags(content) {
+		block := SourceBlock{Line: line, EndLine: endLine, Tag: tag.name}
+		if matches := tagIDRe.FindStringSubmatch(tag.attrs); matches != nil {
+			block.ID = matches[1]
+		}
+		c.sourceMap = append(c.sourceMap, block)
+
+		out.WriteString(content[last:tag.offset])
+		fmt.Fprintf(&out, ` data-src-line="%d"`, line)
+		last = tag.offset
+	}
+	out.WriteString(content[last:])
+	return out.String()
+}
+
+// htmlTag — открывающий тег верхнего уровня; offset — позиция сразу после имени
+type htmlTag struct {
+	name   string
+	attrs  string
+	offset int
+}
+
+// topLevelTags находит открывающие теги верхнего уровня. Содержимое формул и комментариев
+// пропускается: в $x<y$ знак < не начинает тег
+func topLevelTags(content string) []htmlTag {
+	var tags []htmlTag
+	depth := 0
+	for i := 0; i < len(content); i++ {
+		if content[i] != '<' {
+			continue
+		}
+		if strings.HasPrefix(content[i:], "<!--") {
+			end := strings.Index(content[i:], "-->")
+			if end < 0 {
+				break
+			}
+			i += end + 2
+			continue
+		}
+		loc := htmlTagRe.FindStringSubmatchIndex(content[i:])
+		if loc == nil {
+			continue
+		}
+		name := content[i+loc[4] : i+loc[5]]
+		attrs := ""
+		if loc[6] >= 0 {
+			attrs = content[i+loc[6] : i+loc[7]]
+		}
+
+		switch {
+		case loc[3] > loc[2]:
+			depth = max(depth-1, 0)
+		default:
+			if depth == 0 {
+				tags = append(tags, htmlTag{name: name, attrs: attrs, offset: i + loc[5]})
+			}
+			if !voidElements[name] && !strings.HasSuffix(attrs, "/") {
+				depth++
+			}
+		}
+		i += loc[1] - 1
+
+		if name == "span" && strings.Contains(attrs, `class="math"`) && loc[3] == loc[2] {
+			end := strings.Index(content[i:], "</span>")
+			if end < 0 {
+				break
+			}
+			i += end - 1
+		}
+	}
+	return tags
+}
+
+// WriteSourceMap сохраняет карту исходника; пути в ней задаются относительно файла карты
+func WriteSourceMap(path, htmlFile, sourceFile string, blocks []SourceBlock) error {
+	dir, err := filepath.Abs(filepath.Dir(path))
+	if err != nil {
+		return err
+	}
+	relative := func(target string) string {
+		if abs, err := filepath.Abs(target); err == nil {
+			if rel, err := filepath.Rel(dir, abs); err == nil {
+				return filepath.ToSlash(rel)
+			}
+		}
+		return filepath.ToSlash(target)
+	}
+
+	data, err := json.MarshalIndent(SourceMap{
+		Version: 1,
+		File:    relative(htmlFile),
+		Source:  relative(sourceFile),
+		Blocks:  blocks,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// devOverlayScript показывает «файл:строка» при наведении на блок с data-src-line.
+// Подсказка — ссылка на редактор по шаблону opts.EditorURL
+func devOverlayScript(opts Options) string {
+	editorURL := opts.EditorURL
+	if editorURL == "" {
+		editorURL = DefaultEditorURL
+	}
+	path, err := filepath.Abs(opts.SourcePath)
+	if err != nil {
+		path = opts.SourcePath
+	}
+	source, _ := json.Marshal(map[string]string{
+		"name":   filepath.Base(opts.SourcePath),
+		"path":   filepath.ToSlash(path),
+		"editor": editorURL,
+	})
+
+	return `
+    <style>
+        .source-line-badge {
+            position: absolute;
+            z-index: 1000;
+            padding: 1px 6px;
+            font: 12px monospace;
+            color: #fff;
+            background: rgba(40, 40, 40, 0.85);
+            border-radius: 3px;
+            text-decoration: none;
+            transform: translateX(-100%);
+        }
+        [data-src-line]:hover {
+            outline: 1px dashed rgba(120, 120, 120, 0.6);
+        }
+    </style>
+    <script>
+        (function() {
+            const source = ` + string(source) + `;
+            const badge = document.createElement('a');
+            badge.className = 'source-line-badge';
+            badge.hidden = true;
+            document.body.appendChild(badge);
+
+            document.addEventListener('mouseover', (event) => {
+                const block = event.target.closest('[data-src-line]');
+                if (!block) {
+                    return;
+                }
+                const line = block.dataset.srcLine;
+                const rect = block.getBoundingClientRect();
+                badge.textContent = source.name + ':' + line;
+                badge.title = 'Открыть в редакторе';
+                badge.href = source.editor.replace('{path}', encodeURI(source.path)).replace('{line}', line);
+                badge.style.top = (window.scrollY + rect.top) + 'px';
+                badge.style.left = (window.scrollX + rect.right) + 'px';
+                badge.hidden = false;
+            });
+        })();
+    </script>`
+}
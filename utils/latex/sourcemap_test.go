@@ -0,0 +1,81 @@
+package latex
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSourceLines(t *testing.T) {
+	tests := []struct {
+		name, body string
+		// want — блоки карты исходника в виде «тег строка-последняя строка»
+		want []string
+	}{
+		{"абзацы", "Первый\nабзац.\n\n\nВторой.", []string{"p 3-4", "p 7-7"}},
+		{"комментарий не разрывает абзац", "Первый\n% комментарий\nабзац.", []string{"p 3-5"}},
+		{"комментарии до и после абзаца", "% до\nАбзац.\n% после\n\nТекст.", []string{"p 4-4", "p 7-7"}},
+		{"заголовок с меткой", "\\section{Введение}\n\\label{sec:a}\nТекст.", []string{"h2 3-4", "p 5-5"}},
+		{"формула и окружение с новой строки", "Текст.\n\\begin{equation}\nx\n\\end{equation}\n\\begin{center}\nц\n\\end{center}",
+			[]string{"p 3-3", "div 4-6", "div 7-9"}},
+		{"пустая строка в листинге", "\\begin{verbatim}\na\n\nb\n\\end{verbatim}\nТекст.", []string{"pre 3-7", "p 8-8"}},
+		{"окружение посреди абзаца", "Текст и \\begin{center}ц\\end{center} конец.", []string{"p 3-3", "div 3-3", "p 3-3"}},
+		{"\\end{document} не входит в последний блок", "Текст.", []string{"p 3-3"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := convert(t, document(test.body), "ru")
+			var got []string
+			for _, block := range result.SourceMap {
+				got = append(got, fmt.Sprintf("%s %d-%d", block.Tag, block.Line, block.EndLine))
+				if attr := fmt.Sprintf(`<%s data-src-line="%d"`, block.Tag, block.Line); !strings.Contains(result.HTML, attr) {
+					t.Errorf("нет %q", attr)
+				}
+			}
+			if !slices.Equal(got, test.want) {
+				t.Errorf("получено %v, ожидалось %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestSourceLinesSkipMath(t *testing.T) {
+	// Знак < в формуле не начинает тег, и атрибут получает только абзац
+	result := convert(t, document("Если $x<y$ и $$a<b$$, то."), "ru")
+	if got := strings.Count(result.HTML, "data-src-line"); got != 1 {
+		t.Errorf("атрибутов data-src-line %d, ожидался 1", got)
+	}
+	if want := []SourceBlock{{Line: 3, EndLine: 3, Tag: "p"}}; !reflect.DeepEqual(result.SourceMap, want) {
+		t.Errorf("получено %+v, ожидалось %+v", result.SourceMap, want)
+	}
+}
+
+func TestWriteSourceMap(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "maps", "page.map.json")
+	if err := os.Mkdir(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	blocks := []SourceBlock{{Line: 3, EndLine: 4, Tag: "h2", ID: "sec:a"}}
+	if err := WriteSourceMap(path, filepath.Join(dir, "page.html"), filepath.Join(dir, "src", "page.tex"), blocks); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got SourceMap
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	want := SourceMap{Version: 1, File: "../page.html", Source: "../src/page.tex", Blocks: blocks}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("получено %+v, ожидалось %+v", got, want)
+	}
+}
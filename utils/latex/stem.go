@@ -0,0 +1,120 @@
+package latex
+
+import (
+	"sort"
+	"strings"
+)
+
+// stemSuffix — окончание для стеммера; afterAYa требует, чтобы перед окончанием стояла «а» или «я»
+type stemSuffix struct {
+	text     []rune
+	afterAYa bool
+}
+
+// stemSuffixes строит список окончаний, упорядоченный от длинных к коротким:
+// как и в Snowball, выбирается самое длинное подходящее окончание
+func stemSuffixes(afterAYa, plain string) []stemSuffix {
+	var suffixes []stemSuffix
+	for _, s := range strings.Fields(afterAYa) {
+		suffixes = append(suffixes, stemSuffix{text: []rune(s), afterAYa: true})
+	}
+	for _, s := range strings.Fields(plain) {
+		suffixes = append(suffixes, stemSuffix{text: []rune(s)})
+	}
+	sort.SliceStable(suffixes, func(i, j int) bool { return len(suffixes[i].text) > len(suffixes[j].text) })
+	return suffixes
+}
+
+// Классы окончаний русского стеммера Snowball
+var (
+	perfectiveGerundSuffixes = stemSuffixes("в вши вшись", "ив ивши ившись ыв ывши ывшись")
+	adjectiveSuffixes        = stemSuffixes("", "ее ие ые ое ими ыми ей ий ый ой ем им ым ом его ого ему ому их ых ую юю ая яя ою ею")
+	participleSuffixes       = stemSuffixes("ем нн вш ющ щ", "ивш ывш ующ")
+	reflexiveSuffixes        = stemSuffixes("", "ся сь")
+	verbSuffixes             = stemSuffixes("ла на ете йте ли й л ем н ло но ет ют ны ть ешь нно",
+		"ила ыла ена ейте уйте ите или ыли ей уй ил ыл им ым ен ило ыло ено ят ует уют ит ыт ены ить ыть ишь ую ю")
+	nounSuffixes         = stemSuffixes("", "а ев ов ие ье е иями ями ами еи ии и ией ей ой ий й иям ям ием ем ам ом о у ах иях ях ы ь ию ью ю ия ья я")
+	derivationalSuffixes = stemSuffixes("", "ост ость")
+	superlativeSuffixes  = stemSuffixes("", "ейш ейше")
+)
+
+// isRussianVowel сообщает, является ли буква гласной в смысле стеммера
+func isRussianVowel(r rune) bool {
+	return strings.ContainsRune("аеиоуыэюя", r)
+}
+
+// stemRussian возвращает основу русского слова по алгоритму Snowball (Портера).
+// Слово должно быть в нижнем регистре; слова без кириллицы возвращаются без изменений
+func stemRussian(word string) string {
+	w := []rune(strings.ReplaceAll(word, "ё", "е"))
+
+	// RV — часть слова после первой гласной, R2 — область R1 внутри R1
+	rv := len(w)
+	for i, r := range w {
+		if isRussianVowel(r) {
+			rv = i + 1
+			break
+		}
+	}
+	regionAfter := func(start int) int {
+		for i := start + 1; i < len(w); i++ {
+			if !isRussianVowel(w[i]) && isRussianVowel(w[i-1]) {
+				return i + 1
+			}
+		}
+		return len(w)
+	}
+	r2 := regionAfter(regionAfter(0))
+
+	// strip удаляет самое длинное окончание из suffixes, целиком лежащее в области [start:]
+	strip := func(suffixes []stemSuffix, start int) bool {
+		for _, suffix := range suffixes {
+			n := len(suffix.text)
+			if len(w)-n < start || string(w[len(w)-n:]) != string(suffix.text) {
+				continue
+			}
+			if suffix.afterAYa && (len(w)-n-1 < start || (w[len(w)-n-1] != 'а' && w[len(w)-n-1] != 'я')) {
+				continue
+			}
+			w = w[:len(w)-n]
+			return true
+		}
+		return false
+	}
+
+	// Шаг 1: деепричастие, иначе возвратная частица и прилагательное, глагол или существительное
+	if !strip(perfectiveGerundSuffixes, rv) {
+		strip(reflexiveSuffixes, rv)
+		if strip(adjectiveSuffixes, rv) {
+			strip(participleSuffixes, rv)
+		} else if !strip(verbSuffixes, rv) {
+			strip(nounSuffixes, rv)
+		}
+	}
+
+	// Шаг 2: конечная «и»
+	if len(w) > rv && w[len(w)-1] == 'и' {
+		w = w[:len(w)-1]
+	}
+
+	// Шаг 3: словообразовательные окончания в R2
+	strip(derivationalSuffixes, max(r2, rv))
+
+	// Шаг 4: удвоенная «н», превосходная степень или мягкий знак
+	undouble := func() bool {
+		if len(w)-2 >= rv && w[len(w)-1] == 'н' && w[len(w)-2] == 'н' {
+			w = w[:len(w)-1]
+			return true
+		}
+		return false
+	}
+	if !undouble() {
+		if strip(superlativeSuffixes, rv) {
+			undouble()
+		} else if len(w) > rv && w[len(w)-1] == 'ь' {
+			w = w[:len(w)-1]
+		}
+	}
+
+	return string(w)
+}
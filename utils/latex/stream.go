@@ -0,0 +1,312 @@
+package latex
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	environmentBoundaryRe = regexp.MustCompile(`\\(begin|end)\{([^}]*)\}`)
+	blockEnvRe            = regexp.MustCompile(`^\\begin\{([^}]*)\}`)
+	headingLineRe         = regexp.MustCompile(`^\\(?:sub){0,2}section\*?\s*\{`)
+	sectionLabelLineRe    = regexp.MustCompile(`^\\label\{[^}]+\}$`)
+)
+
+// ConvertLatexStream конвертирует LaTeX в HTML, не загружая документ в память целиком.
+// Тело документа делится на блоки по пустым строкам вне окружений; каждый блок
+// конвертируется и записывается сразу, нумерация продолжается между блоками.
+// Страница записывается в w, поле HTML результата остается пустым.
+//
+// Метаданные и \newtheorem берутся только из преамбулы. Ссылки \ref на метки,
+// объявленные ниже по тексту, дописываются скриптом в конце страницы
+func ConvertLatexStream(r io.Reader, w io.Writer, opts Options) (Result, error) {
+	if opts.Bundle {
+		return Result{}, errors.New("потоковая конвертация не поддерживает автономную сборку")
+	}
+
+	in := bufio.NewReader(r)
+	out := bufio.NewWriter(w)
+
+	preamble, body, line, err := readPreamble(in)
+	if err != nil {
+		return Result{}, fmt.Errorf("чтение преамбулы: %w", err)
+	}
+	preamble = stripComments(preamble)
+
+	if opts.Locale.Lang == "" {
+		lang := detectLanguage(preamble)
+		if lang == "" {
+			lang = DefaultLang
+		}
+		opts.Locale = locales[lang]
+	}
+	meta := documentMetadata(preamble, opts)
+	converter := newHTMLConverter(opts, meta, parseTheoremDeclarations(preamble))
+
+	out.WriteString(generatePageHeader(meta, opts.Locale))
+	if err := converter.convertBody(io.MultiReader(strings.NewReader(body), in), line, func(html string) {
+		out.WriteString(html)
+	}); err != nil {
+		return Result{}, fmt.Errorf("чтение документа: %w", err)
+	}
+	converter.warnUnresolved()
+
+	out.WriteString(refFixupScript(converter.state))
+	out.WriteString(generatePageEnd(extractReferences(converter.references.String()), converter.state.footnotes, opts))
+
+	if err := out.Flush(); err != nil {
+		return Result{}, err
+	}
+	return Result{Metadata: meta, SourceMap: converter.sourceMap, Diagnostics: converter.diagnostics}, nil
+}
+
+// convertBody конвертирует тело документа по блокам и передает HTML в write.
+// line — номер строки исходника, с которой начинается r
+func (c *htmlConverter) convertBody(r io.Reader, line int, write func(html string)) error {
+	// Текстовые и неизвестные окружения с новой строки тоже идут отдельным блоком:
+	// иначе их элемент не получил бы своего data-src-line
+	isBlockEnv := func(name string) bool {
+		_, theorem := c.state.kinds[name]
+		_, text := c.environments[name]
+		return theorem || text || !c.knownEnvironment(name) ||
+			name == "proof" || name == "equation" || name == "algorithm" || codeEnvPatterns[name] != nil
+	}
+
+	first := true
+	err := splitDocumentBlocks(bufio.NewReader(r), line, isBlockEnv, func(block string, line, endLine int) {
+		c.checkEnvironments(block, line)
+		latex, codeBlocks := protectVerbatim(block)
+		c.codeBlocks = codeBlocks
+		content := c.convertBlock(stripComments(latex))
+		if content != "" {
+			content = c.annotateSourceLine(content, line, endLine)
+		}
+
+		if first {
+			first = false
+			// При \maketitle заголовок уже выведен в тексте документа
+			titleHTML := ""
+			if !c.hasTitleBlock && !c.titleRendered {
+				titleHTML = `<h1>` + html.EscapeString(c.meta.Title) + `</h1>`
+			}
+			write("\n        " + titleHTML + "\n        " + content)
+		} else if content != "" {
+			write("\n" + content)
+		}
+	})
+	if err == nil && first {
+		write("\n        <h1>" + html.EscapeString(c.meta.Title) + "</h1>\n        ")
+	}
+	return err
+}
+
+// readPreamble читает текст до \begin{document}; body — остаток строки после команды,
+// line — номер этой строки
+func readPreamble(in *bufio.Reader) (preamble, body string, line int, err error) {
+	var text strings.Builder
+	for line = 1; ; line++ {
+		s, err := in.ReadString('\n')
+		if loc := beginDocumentRe.FindStringIndex(stripComments(s)); loc != nil {
+			// stripComments не меняет текст до первого комментария
+			text.WriteString(s[:loc[0]])
+			return text.String(), s[loc[1]:], line, nil
+		}
+		text.WriteString(s)
+
+		if err == io.EOF {
+			// Документ без \begin{document} целиком считается телом
+			return "", text.String(), 1, nil
+		}
+		if err != nil {
+			return "", "", 0, err
+		}
+	}
+}
+
+// splitDocumentBlocks читает тело документа до \end{document} и передает в handle блоки
+// с номерами их первой и последней строки. Блоки разделяются пустыми строками вне окружений;
+// кроме того, отдельными блоками становятся заголовки разделов и окружения isBlockEnv,
+// которые начинаются с новой строки. Внутри окружений с кодом пустые строки
+// и команды \begin, \end не учитываются
+func splitDocumentBlocks(in *bufio.Reader, line int, isBlockEnv func(name string) bool, handle func(block string, line, endLine int)) error {
+	var block strings.Builder
+	blockLine, endLine := 0, 0
+	depth := 0
+	codeEnv := ""
+
+	// standalone — окружение, начатое с новой строки: блок завершается на его \end
+	standalone := ""
+	afterHeading := false
+
+	flush := func() {
+		if blockLine > 0 && strings.TrimSpace(block.String()) != "" {
+			handle(block.String(), blockLine, endLine)
+		}
+		block.Reset()
+		blockLine = 0
+		standalone = ""
+		afterHeading = false
+	}
+	// appendLine добавляет строку к блоку. Строки без текста, например из одного
+	// комментария, не сдвигают границы блока, а перед первой строкой с текстом пропускаются
+	appendLine := func(s string, text bool) {
+		switch {
+		case text:
+			if blockLine == 0 {
+				blockLine = line
+			}
+			endLine = line
+		case blockLine == 0:
+			return
+		}
+		block.WriteString(s)
+	}
+
+	for ; ; line++ {
+		s, err := in.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return err
+		}
+
+		if codeEnv != "" {
+			appendLine(s, true)
+			if strings.Contains(s, `\end{`+codeEnv+`}`) {
+				codeEnv = ""
+				if depth == 0 && standalone != "" {
+					flush()
+				}
+			}
+		} else if strings.TrimSpace(s) == "" {
+			if depth == 0 {
+				flush()
+			} else {
+				appendLine(s, false)
+			}
+		} else {
+			text := stripComments(s)
+			trimmed := strings.TrimSpace(text)
+
+			if depth == 0 {
+				switch {
+				case afterHeading && sectionLabelLineRe.MatchString(trimmed):
+					// \label после заголовка задает его якорь и остается в том же блоке
+				case headingLineRe.MatchString(trimmed):
+					flush()
+					afterHeading = true
+				case afterHeading:
+					flush()
+				}
+				if matches := blockEnvRe.FindStringSubmatch(trimmed); matches != nil && isBlockEnv(matches[1]) {
+					flush()
+					standalone = matches[1]
+				}
+			}
+
+			end := false
+			for _, loc := range environmentBoundaryRe.FindAllStringSubmatchIndex(text, -1) {
+				name := text[loc[4]:loc[5]]
+				switch {
+				case text[loc[2]:loc[3]] == "end" && name == "document":
+					s = s[:loc[0]]
+					end = true
+				case text[loc[2]:loc[3]] == "end":
+					depth = max(depth-1, 0)
+				case codeEnvPatterns[name] != nil:
+					// Конец окружения с кодом может стоять на той же строке
+					if !strings.Contains(s[loc[1]:], `\end{`+name+`}`) {
+						codeEnv = name
+					}
+				default:
+					depth++
+				}
+				if end || codeEnv != "" {
+					break
+				}
+			}
+
+			// Строка с одним \end{document} к последнему блоку тоже не относится
+			appendLine(s, strings.TrimSpace(stripComments(s)) != "")
+			if end {
+				flush()
+				return nil
+			}
+			// Текст после \end на той же строке продолжает абзац, и блок не завершается
+			if depth == 0 && codeEnv == "" && standalone != "" && strings.HasSuffix(trimmed, `\end{`+standalone+`}`) {
+				flush()
+			}
+		}
+
+		if err == io.EOF {
+			flush()
+			return nil
+		}
+	}
+}
+
+// refFixupScript подставляет номера в ссылки на метки, объявленные после ссылки.
+// Возвращает пустую строку, если таких ссылок нет
+func refFixupScript(state *documentState) string {
+	numbers := make(map[string]string)
+	for label := range state.unresolved {
+		if number, ok := state.labels[label]; ok {
+			numbers[label] = number
+		}
+	}
+	if len(numbers) == 0 {
+		return ""
+	}
+
+	labels := make([]string, 0, len(numbers))
+	for label := range numbers {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	ordered := make([]string, len(labels))
+	for i, label := range labels {
+		key, _ := json.Marshal(label)
+		value, _ := json.Marshal(numbers[label])
+		ordered[i] = string(key) + ": " + string(value)
+	}
+
+	return `
+    <script>
+        document.addEventListener('DOMContentLoaded', function() {
+            const labels = {` + strings.Join(ordered, ", ") + `};
+            document.querySelectorAll('a.ref[data-ref]').forEach((link) => {
+                const number = labels[link.dataset.ref];
+                if (number !== undefined) {
+                    link.textContent = link.textContent.replace('??', number);
+                    link.removeAttribute('data-ref');
+                }
+            });
+        });
+    </script>`
+}
+
+// ConvertFileStream конвертирует файл input в output в потоковом режиме
+func ConvertFileStream(input, output string, opts Options) (Result, error) {
+	in, err := os.Open(input)
+	if err != nil {
+		return Result{}, err
+	}
+	defer in.Close()
+
+	out, err := os.Create(output)
+	if err != nil {
+		return Result{}, err
+	}
+	result, err := ConvertLatexStream(in, out, opts)
+	if err != nil {
+		out.Close()
+		return Result{}, err
+	}
+	return result, out.Close()
+}
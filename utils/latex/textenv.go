@@ -0,0 +1,204 @@
+package latex
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// TextEnvironment описывает, каким элементом HTML выводится текстовое окружение LaTeX
+type TextEnvironment struct {
+	Element string `json:"element"`
+	Class   string `json:"class"`
+
+	// Title выводится над содержимым, например «Аннотация» у abstract
+	Title string `json:"title,omitempty"`
+
+	// Width — первый обязательный аргумент окружения задает ширину блока, как у minipage
+	Width bool `json:"width,omitempty"`
+}
+
+// defaultTextEnvironments возвращает встроенный реестр текстовых окружений
+func defaultTextEnvironments(locale Locale) map[string]TextEnvironment {
+	return map[string]TextEnvironment{
+		"abstract":   {Element: "section", Class: "abstract", Title: locale.Abstract},
+		"quote":      {Element: "blockquote", Class: "quote"},
+		"quotation":  {Element: "blockquote", Class: "quotation"},
+		"verse":      {Element: "blockquote", Class: "verse"},
+		"center":     {Element: "div", Class: "center"},
+		"flushleft":  {Element: "div", Class: "flushleft"},
+		"flushright": {Element: "div", Class: "flushright"},
+		"minipage":   {Element: "div", Class: "minipage", Width: true},
+	}
+}
+
+// mathJaxEnvironments обрабатывает MathJax: они стоят внутри формул или выводятся как есть
+var mathJaxEnvironments = map[string]bool{
+	"cases": true, "aligned": true, "gathered": true, "split": true, "array": true,
+	"matrix": true, "pmatrix": true, "bmatrix": true, "Bmatrix": true, "vmatrix": true, "Vmatrix": true,
+	"smallmatrix": true, "subarray": true, "align": true, "align*": true, "alignat": true, "alignat*": true,
+	"gather": true, "gather*": true, "multline": true, "multline*": true, "eqnarray": true, "eqnarray*": true,
+	"equation*": true, "displaymath": true,
+}
+
+var (
+	htmlNameRe    = regexp.MustCompile(`^[a-z][a-z0-9]*$`)
+	htmlClassRe   = regexp.MustCompile(`^[a-zA-Z0-9_ -]*$`)
+	textEnvRe     = regexp.MustCompile(`\\(begin|end)\{([a-zA-Z]+\*?)\}`)
+	environmentRe = regexp.MustCompile(`\\begin\{([^}]+)\}`)
+)
+
+// LoadTextEnvironments читает из JSON файла окружения, которые дополняют встроенный реестр
+// или заменяют его записи. Файл сопоставляет имени окружения объект с полями
+// element, class, title и width
+func LoadTextEnvironments(path string) (map[string]TextEnvironment, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("чтение файла окружений: %w", err)
+	}
+
+	var environments map[string]TextEnvironment
+	if err := json.Unmarshal(data, &environments); err != nil {
+		return nil, fmt.Errorf("разбор файла окружений %s: %w", path, err)
+	}
+	for name, env := range environments {
+		if env.Element == "" {
+			env.Element = "div"
+		}
+		if !htmlNameRe.MatchString(env.Element) || !htmlClassRe.MatchString(env.Class) {
+			return nil, fmt.Errorf("окружение %s: недопустимый элемент %q или класс %q", name, env.Element, env.Class)
+		}
+		environments[name] = env
+	}
+
+	return environments, nil
+}
+
+// processTextEnvironments заменяет окружения из реестра элементами HTML.
+// Команды внутри формул не затрагиваются: там окружения обрабатывает MathJax
+func processTextEnvironments(content string, environments map[string]TextEnvironment) string {
+	var out strings.Builder
+	out.Grow(len(content))
+
+	var open []string
+	replace := func(text string) string {
+		var result strings.Builder
+		for {
+			loc := textEnvRe.FindStringSubmatchIndex(text)
+			if loc == nil {
+				break
+			}
+			name := text[loc[4]:loc[5]]
+			env, ok := environments[name]
+			result.WriteString(text[:loc[0]])
+			rest := text[loc[1]:]
+
+			switch {
+			case !ok:
+				result.WriteString(text[loc[0]:loc[1]])
+			case text[loc[2]:loc[3]] == "end":
+				if len(open) > 0 {
+					result.WriteString("\n\n</" + open[len(open)-1] + ">\n\n")
+					open = open[:len(open)-1]
+				}
+			default:
+				open = append(open, env.Element)
+				style := ""
+				if env.Width {
+					style, rest = environmentWidth(rest)
+				}
+				result.WriteString("\n\n<" + env.Element + ` class="` + env.Class + `"` + style + ">")
+				if env.Title != "" {
+					result.WriteString("\n" + `<div class="` + env.Class + `-title">` + html.EscapeString(env.Title) + `</div>`)
+				}
+				result.WriteString("\n\n")
+			}
+			text = rest
+		}
+		result.WriteString(text)
+		return result.String()
+	}
+
+	textStart := 0
+	for i := 0; i < len(content); {
+		skip := protectedSpanLength(content[i:])
+		if skip == 0 {
+			i++
+			continue
+		}
+		out.WriteString(replace(content[textStart:i]))
+		out.WriteString(content[i : i+skip])
+		i += skip
+		textStart = i
+	}
+	out.WriteString(replace(content[textStart:]))
+
+	return out.String()
+}
+
+// environmentWidth читает [позицию]{ширину} после \begin{minipage} и возвращает атрибут style
+// и текст после аргументов. Позиция на верстку страницы не влияет
+func environmentWidth(text string) (style, rest string) {
+	args := strings.TrimLeft(text, " \t\n")
+	if strings.HasPrefix(args, "[") {
+		if end := strings.IndexByte(args, ']'); end >= 0 {
+			args = strings.TrimLeft(args[end+1:], " \t\n")
+		}
+	}
+	width, end, ok := readBraceGroup(args, 0)
+	if !ok {
+		return "", text
+	}
+	return ` style="width: ` + html.EscapeString(latexLengthToCSS(width)) + `"`, args[end:]
+}
+
+// checkEnvironments сообщает об окружениях, которые конвертер не знает и оставляет как текст.
+// line — номер первой строки block в исходнике
+func (c *htmlConverter) checkEnvironments(block string, line int) {
+	codeEnv := ""
+	for i, text := range strings.Split(block, "\n") {
+		if codeEnv != "" {
+			if strings.Contains(text, `\end{`+codeEnv+`}`) {
+				codeEnv = ""
+			}
+			continue
+		}
+		for _, matches := range environmentRe.FindAllStringSubmatch(stripComments(text), -1) {
+			name := matches[1]
+			if codeEnvPatterns[name] != nil {
+				if !strings.Contains(text, `\end{`+name+`}`) {
+					codeEnv = name
+				}
+				break
+			}
+			if c.knownEnvironment(name) {
+				continue
+			}
+			c.diagnostics = append(c.diagnostics, Diagnostic{
+				Line:    line + i,
+				Message: fmt.Sprintf("неизвестное окружение %s оставлено как текст; его можно описать в файле -environments", name),
+			})
+		}
+	}
+}
+
+// knownEnvironment сообщает, обрабатывает ли конвертер окружение name
+func (c *htmlConverter) knownEnvironment(name string) bool {
+	if _, ok := c.environments[name]; ok {
+		return true
+	}
+	if _, ok := c.state.kinds[name]; ok {
+		return true
+	}
+	if _, ok := environmentRegistry[name]; ok {
+		return true
+	}
+	switch name {
+	case "document", "algorithm*", "proof":
+		return true
+	}
+	return mathJaxEnvironments[name]
+}
@@ -0,0 +1,129 @@
+package latex
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTextEnvironments(t *testing.T) {
+	tests := []struct {
+		name, lang, body string
+		custom           map[string]TextEnvironment
+		want             []string
+	}{
+		{"аннотация с заголовком", "ru", "\\begin{abstract}\nКратко.\n\\end{abstract}",
+			nil, []string{`class="abstract">` + "\n" + `<div class="abstract-title">Аннотация</div>` + "\n<p>Кратко.</p>\n</section>"}},
+		{"заголовок на языке документа", "en", "\\begin{abstract}\nBrief.\n\\end{abstract}",
+			nil, []string{`<div class="abstract-title">Abstract</div>`}},
+		{"цитата", "ru", "\\begin{quote}\nСлова.\n\\end{quote}",
+			nil, []string{`class="quote">` + "\n<p>Слова.</p>\n</blockquote>"}},
+		{"ширина minipage", "ru", "\\begin{minipage}[t]{0.5\\textwidth}\nМ.\n\\end{minipage}",
+			nil, []string{`class="minipage" style="width: 50%">` + "\n<p>М.</p>\n</div>"}},
+		{"вложенные окружения", "ru", "\\begin{center}\n\\begin{quote}\nВ.\n\\end{quote}\n\\end{center}",
+			nil, []string{`class="center">` + "\n<blockquote class=\"quote\">\n<p>В.</p>\n</blockquote>\n</div>"}},
+		{"окружение из Options", "ru", "\\begin{remark}\nR.\n\\end{remark}",
+			map[string]TextEnvironment{"remark": {Element: "aside", Class: "note", Title: "<Заметка>"}},
+			[]string{`<aside data-src-line="3" class="note">` + "\n" + `<div class="note-title">&lt;Заметка&gt;</div>` + "\n<p>R.</p>\n</aside>"}},
+		{"замена встроенного окружения", "ru", "\\begin{quote}\nQ.\n\\end{quote}",
+			map[string]TextEnvironment{"quote": {Element: "div", Class: "epigraph"}},
+			[]string{`<div data-src-line="3" class="epigraph">`}},
+		{"окружение в формуле не заменяется", "ru", "$\\begin{center}x\\end{center}$",
+			nil, []string{`$\begin{center}x\end{center}$`}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			locale, err := LoadLocale(test.lang, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			result, err := ConvertLatexToHTML(document(test.body), Options{Locale: locale, TextEnvironments: test.custom})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range test.want {
+				if !strings.Contains(result.HTML, want) {
+					t.Errorf("нет %q", want)
+				}
+			}
+			if len(result.Diagnostics) > 0 {
+				t.Errorf("лишние замечания %v", result.Diagnostics)
+			}
+		})
+	}
+}
+
+func TestUnknownEnvironmentDiagnostics(t *testing.T) {
+	tests := []struct {
+		name, body string
+		want       []int // строки замечаний
+		names      []string
+	}{
+		{"окружение с новой строки", "Текст.\n\n\\begin{itemize}\n\\item a\n\\end{itemize}", []int{5}, []string{"itemize"}},
+		{"окружение посреди абзаца", "Первая строка\nи \\begin{foo}x\\end{foo}, \\begin{bar}y\\end{bar}.", []int{4, 4}, []string{"foo", "bar"}},
+		{"после ведущего комментария", "% комментарий\n\\begin{baz}\nz\n\\end{baz}", []int{4}, []string{"baz"}},
+		{"в комментарии", "Текст % \\begin{foo}", nil, nil},
+		{"в листинге", "\\begin{verbatim}\n\\begin{foo}\n\\end{verbatim}\n\\begin{lstlisting}\n\\begin{bar}\n\\end{lstlisting}", nil, nil},
+		{"известные окружения", "\\newtheorem{lemma}{Лемма}\n\\begin{lemma}\nЛ.\n\\end{lemma}\n\\begin{proof}\nД.\n\\end{proof}\n" +
+			"\\begin{align}\nx\n\\end{align}\n$\\begin{pmatrix}1\\end{pmatrix}$\n\\begin{center}\nЦ\n\\end{center}", nil, nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := convert(t, document(test.body), "ru")
+			var lines []int
+			for i, d := range result.Diagnostics {
+				lines = append(lines, d.Line)
+				if i < len(test.names) && !strings.Contains(d.Message, "окружение "+test.names[i]+" ") {
+					t.Errorf("замечание %q, ожидалось об окружении %s", d.Message, test.names[i])
+				}
+			}
+			if !reflect.DeepEqual(lines, test.want) {
+				t.Errorf("замечания на строках %v, ожидались %v: %v", lines, test.want, result.Diagnostics)
+			}
+		})
+	}
+}
+
+func TestLoadTextEnvironments(t *testing.T) {
+	tests := []struct {
+		name, data string
+		want       map[string]TextEnvironment
+		err        string
+	}{
+		{"элемент по умолчанию", `{"remark": {"class": "note", "title": "Заметка"}, "box": {"element": "section", "class": "box", "width": true}}`,
+			map[string]TextEnvironment{
+				"remark": {Element: "div", Class: "note", Title: "Заметка"},
+				"box":    {Element: "section", Class: "box", Width: true},
+			}, ""},
+		{"недопустимый элемент", `{"x": {"element": "script onload=alert(1)"}}`, nil, "недопустимый элемент"},
+		{"недопустимый класс", `{"x": {"class": "a\" onclick=\"b"}}`, nil, "недопустимый элемент"},
+		{"не JSON", `{"x": [}`, nil, "разбор файла окружений"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "environments.json")
+			if err := os.WriteFile(path, []byte(test.data), 0644); err != nil {
+				t.Fatal(err)
+			}
+			got, err := LoadTextEnvironments(path)
+			if test.err != "" {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Errorf("ошибка %v, ожидалась %q", err, test.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("получено %+v, ожидалось %+v", got, test.want)
+			}
+		})
+	}
+
+	if _, err := LoadTextEnvironments(filepath.Join(t.TempDir(), "missing.json")); err == nil || !strings.Contains(err.Error(), "чтение файла окружений") {
+		t.Errorf("ошибка %v, ожидалась ошибка чтения", err)
+	}
+}
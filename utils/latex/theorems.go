@@ -0,0 +1,227 @@
+package latex
+
+import (
+	"html"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	theoremDeclRe = regexp.MustCompile(`\\newtheorem(\*?)\{([^}]+)\}(?:\[([^\]]+)\])?\{([^}]+)\}(?:\[([^\]]+)\])?`)
+	refCommandRe  = regexp.MustCompile(`\\(eq)?ref\{([^}]+)\}`)
+	forwardRefRe  = regexp.MustCompile(`<a href="([^"]*)" class="ref" data-ref="([^"]*)">(\(?)\?\?(\)?)</a>`)
+)
+
+// theoremKind описывает окружение, объявленное через \newtheorem
+type theoremKind struct {
+	Name     string
+	Title    string
+	Counter  string // имя окружения, чей счетчик используется
+	Within   int    // глубина раздела, внутри которого идет нумерация (1 — \section); 0 — сквозная
+	Numbered bool
+}
+
+// sectionDepths сопоставляет счетчику раздела из [within] его глубину
+var sectionDepths = map[string]int{"section": 1, "subsection": 2, "subsubsection": 3}
+
+// parseTheoremDeclarations разбирает объявления \newtheorem в преамбуле
+func parseTheoremDeclarations(latex string) map[string]theoremKind {
+	kinds := make(map[string]theoremKind)
+
+	// \newtheorem{name}[shared]{Title}[within] и \newtheorem*{name}{Title}
+	for _, matches := range theoremDeclRe.FindAllStringSubmatch(latex, -1) {
+		name := strings.TrimSpace(matches[2])
+		counter := strings.TrimSpace(matches[3])
+		if counter == "" {
+			counter = name
+		}
+		within := 0
+		if parent := strings.TrimSpace(matches[5]); parent != "" {
+			var ok bool
+			if within, ok = sectionDepths[parent]; !ok {
+				log.Printf("Предупреждение: нумерация %s внутри %s не поддерживается, нумерация будет сквозной", name, parent)
+			}
+		}
+
+		kinds[name] = theoremKind{
+			Name:     name,
+			Title:    strings.TrimSpace(matches[4]),
+			Counter:  counter,
+			Within:   within,
+			Numbered: matches[1] == "",
+		}
+	}
+
+	// Окружение с общим счетчиком нумеруется так же, как владелец счетчика
+	for name, kind := range kinds {
+		if owner, ok := kinds[kind.Counter]; ok && kind.Counter != name {
+			kind.Within = owner.Within
+			kinds[name] = kind
+		}
+	}
+
+	return kinds
+}
+
+// theoremNumber увеличивает счетчик окружения и возвращает его номер. При нумерации
+// внутри раздела номер получает префикс раздела (1.2 — вторая теорема раздела 1),
+// а счетчик сбрасывается, как только префикс меняется; scopes хранит последний префикс
+func theoremNumber(kind theoremKind, counters map[string]int, scopes map[string]string, sections [3]int) string {
+	if kind.Within == 0 {
+		counters[kind.Counter]++
+		return strconv.Itoa(counters[kind.Counter])
+	}
+
+	parts := make([]string, kind.Within)
+	for i := range parts {
+		parts[i] = strconv.Itoa(sections[i])
+	}
+	prefix := strings.Join(parts, ".")
+	if scopes[kind.Counter] != prefix {
+		scopes[kind.Counter] = prefix
+		counters[kind.Counter] = 0
+	}
+	counters[kind.Counter]++
+	return prefix + "." + strconv.Itoa(counters[kind.Counter])
+}
+
+// processTheorems оформляет теоремоподобные окружения и proof в виде нумерованных блоков
+func processTheorems(content string, state *documentState, locale Locale) string {
+	var out strings.Builder
+	for {
+		loc := state.theoremRe.FindStringSubmatchIndex(content)
+		if loc == nil {
+			break
+		}
+
+		name := content[loc[2]:loc[3]]
+		title := ""
+		if loc[4] >= 0 {
+			title = strings.TrimSpace(content[loc[4]:loc[5]])
+		}
+
+		endTag := `\end{` + name + `}`
+		endIdx := strings.Index(content[loc[1]:], endTag)
+		if endIdx < 0 {
+			log.Printf("Предупреждение: окружение %s не закрыто", name)
+			out.WriteString(content[:loc[0]])
+			content = content[loc[1]:]
+			continue
+		}
+		body := content[loc[1] : loc[1]+endIdx]
+
+		var block string
+		if name == "proof" {
+			block = renderProof(processTheorems(body, state, locale), title, locale)
+		} else {
+			kind := state.kinds[name]
+			number := ""
+			if kind.Numbered {
+				number = theoremNumber(kind, state.counters, state.theoremScopes, state.sections)
+			}
+			// Вложенные окружения нумеруются после внешнего
+			block = renderTheorem(kind, number, processTheorems(body, state, locale), title, state.labels)
+		}
+
+		out.WriteString(content[:loc[0]])
+		out.WriteString(block)
+		content = content[loc[1]+endIdx+len(endTag):]
+	}
+	out.WriteString(content)
+
+	return out.String()
+}
+
+// renderTheorem формирует HTML блока теоремы
+func renderTheorem(kind theoremKind, number, body, title string, labels map[string]string) string {
+	id := ""
+	body = labelCommandRe.ReplaceAllStringFunc(body, func(match string) string {
+		label := match[len(`\label{`) : len(match)-1]
+		if id == "" {
+			id = label
+		}
+		labels[label] = number
+		return ""
+	})
+
+	head := `<strong>` + kind.Title
+	if number != "" {
+		head += ` ` + number
+	}
+	head += `</strong>`
+	if title != "" {
+		head += ` (` + title + `)`
+	}
+	head += `.`
+
+	idAttr := ""
+	if id != "" {
+		idAttr = ` id="` + html.EscapeString(id) + `"`
+	}
+
+	// Пустые строки отделяют служебные строки блока от абзацев тела
+	return "\n\n<div class=\"theorem theorem-" + kind.Name + "\"" + idAttr + ">\n" +
+		"<div class=\"theorem-head\">" + head + "</div>\n\n" +
+		strings.TrimSpace(body) + "\n\n</div>\n\n"
+}
+
+// renderProof формирует HTML доказательства со знаком конца доказательства
+func renderProof(body, title string, locale Locale) string {
+	head := locale.Proof
+	if title != "" {
+		head = title
+	}
+
+	return "\n\n<div class=\"proof\">\n" +
+		"<div class=\"proof-head\"><em>" + head + ".</em></div>\n\n" +
+		strings.TrimSpace(body) + "\n\n<div class=\"qed\">∎</div>\n</div>\n\n"
+}
+
+// theoremBeginRegexp находит начало proof и окружений, объявленных через \newtheorem
+func theoremBeginRegexp(kinds map[string]theoremKind) *regexp.Regexp {
+	names := []string{"proof"}
+	for name := range kinds {
+		names = append(names, regexp.QuoteMeta(name))
+	}
+	return regexp.MustCompile(`\\begin\{(` + strings.Join(names, "|") + `)\}(?:\[([^\]]*)\])?`)
+}
+
+// processRefs заменяет \ref и \eqref ссылками на помеченные блоки.
+// Метка может быть объявлена ниже по тексту: такая ссылка получает data-ref, а номер
+// подставляют resolveForwardRefs или, при потоковой конвертации, скрипт refFixupScript
+func processRefs(content string, state *documentState) string {
+	return refCommandRe.ReplaceAllStringFunc(content, func(match string) string {
+		matches := refCommandRe.FindStringSubmatch(match)
+		label := matches[2]
+
+		number, ok := state.labels[label]
+		dataAttr := ""
+		if !ok {
+			state.unresolved[label] = true
+			number = "??"
+			dataAttr = ` data-ref="` + html.EscapeString(label) + `"`
+		}
+		if matches[1] == "eq" {
+			number = "(" + number + ")"
+		}
+
+		return `<a href="#` + html.EscapeString(label) + `" class="ref"` + dataAttr + `>` + number + `</a>`
+	})
+}
+
+// resolveForwardRefs подставляет номера в ссылки на метки, объявленные после ссылки
+func resolveForwardRefs(content string, state *documentState) string {
+	if len(state.unresolved) == 0 {
+		return content
+	}
+	return forwardRefRe.ReplaceAllStringFunc(content, func(match string) string {
+		matches := forwardRefRe.FindStringSubmatch(match)
+		number, ok := state.labels[html.UnescapeString(matches[2])]
+		if !ok {
+			return match
+		}
+		return `<a href="` + matches[1] + `" class="ref">` + matches[3] + number + matches[4] + `</a>`
+	})
+}
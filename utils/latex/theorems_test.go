@@ -0,0 +1,102 @@
+package latex
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestParseTheoremDeclarations(t *testing.T) {
+	kinds := parseTheoremDeclarations(`
+\newtheorem{theorem}{Теорема}[section]
+\newtheorem{lemma}[theorem]{Лемма}
+\newtheorem{definition}{Определение}
+\newtheorem*{remark}{Замечание}
+\newtheorem{claim}{Утверждение}[chapter]`)
+
+	want := map[string]theoremKind{
+		"theorem":    {Name: "theorem", Title: "Теорема", Counter: "theorem", Within: 1, Numbered: true},
+		"lemma":      {Name: "lemma", Title: "Лемма", Counter: "theorem", Within: 1, Numbered: true},
+		"definition": {Name: "definition", Title: "Определение", Counter: "definition", Numbered: true},
+		"remark":     {Name: "remark", Title: "Замечание", Counter: "remark"},
+		"claim":      {Name: "claim", Title: "Утверждение", Counter: "claim", Numbered: true},
+	}
+	if !reflect.DeepEqual(kinds, want) {
+		t.Errorf("получено %+v, ожидалось %+v", kinds, want)
+	}
+}
+
+const theoremsDocument = `\documentclass{article}
+\newtheorem{theorem}{Теорема}[section]
+\newtheorem{lemma}[theorem]{Лемма}
+\newtheorem{definition}{Определение}
+\newtheorem*{remark}{Замечание}
+\begin{document}
+См. теорему \ref{thm:b}.
+
+\section{Первый}
+\begin{theorem}[Основная]
+Текст.
+\end{theorem}
+\begin{lemma}
+\label{lem:a}
+Текст.
+\end{lemma}
+\begin{definition}
+Текст.
+\end{definition}
+\begin{remark}
+Текст.
+\end{remark}
+
+\section{Второй}
+\begin{theorem}
+\label{thm:b}
+Текст.
+\begin{proof}[Набросок]
+Вложенное.
+\end{proof}
+\end{theorem}
+\begin{definition}
+Текст.
+\end{definition}
+По лемме \ref{lem:a} и теореме \ref{thm:b}.
+\end{document}`
+
+var theoremHeadRe = regexp.MustCompile(`<div class="theorem-head">(.*?)</div>`)
+
+func TestTheoremNumbering(t *testing.T) {
+	html := convert(t, theoremsDocument, "ru").HTML
+
+	var heads []string
+	for _, matches := range theoremHeadRe.FindAllStringSubmatch(html, -1) {
+		heads = append(heads, matches[1])
+	}
+	want := []string{
+		"<strong>Теорема 1.1</strong> (Основная).",
+		"<strong>Лемма 1.2</strong>.",
+		"<strong>Определение 1</strong>.",
+		"<strong>Замечание</strong>.",
+		"<strong>Теорема 2.1</strong>.",
+		"<strong>Определение 2</strong>.",
+	}
+	if !reflect.DeepEqual(heads, want) {
+		t.Errorf("заголовки теорем %q, ожидались %q", heads, want)
+	}
+
+	for _, want := range []string{
+		`class="theorem theorem-lemma" id="lem:a">`,
+		`class="theorem theorem-theorem" id="thm:b">`,
+		`<div class="proof-head"><em>Набросок.</em></div>`,
+		`См. теорему <a href="#thm:b" class="ref">2.1</a>`,
+		`По лемме <a href="#lem:a" class="ref">1.2</a> и теореме <a href="#thm:b" class="ref">2.1</a>`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("нет %q", want)
+		}
+	}
+	if strings.Contains(html, "??") {
+		t.Error("осталась неразрешенная ссылка")
+	}
+}
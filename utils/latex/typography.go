@@ -0,0 +1,186 @@
+package latex
+
+import (
+	"strings"
+)
+
+// stripComments удаляет комментарии % вместе с переводом строки
+// и ведущими пробелами следующей строки, как это делает TeX
+func stripComments(latex string) string {
+	var out strings.Builder
+	out.Grow(len(latex))
+
+	for i := 0; i < len(latex); i++ {
+		switch {
+		case latex[i] == '\\' && i+1 < len(latex):
+			// В аргументе \url и первом аргументе \href знак % является частью адреса
+			if strings.HasPrefix(latex[i:], `\url{`) || strings.HasPrefix(latex[i:], `\href{`) {
+				open := strings.IndexByte(latex[i:], '{') + i
+				if _, end, ok := readBraceGroup(latex, open); ok {
+					out.WriteString(latex[i:end])
+					i = end - 1
+					continue
+				}
+			}
+			out.WriteByte(latex[i])
+			out.WriteByte(latex[i+1])
+			i++
+
+		case latex[i] == '%':
+			for i < len(latex) && latex[i] != '\n' {
+				i++
+			}
+			for i+1 < len(latex) && (latex[i+1] == ' ' || latex[i+1] == '\t') {
+				i++
+			}
+
+		default:
+			out.WriteByte(latex[i])
+		}
+	}
+
+	return out.String()
+}
+
+// typographyReplacers строятся один раз: сборка strings.Replacer дороже самой замены.
+// Ключ — русский ли язык документа
+var typographyReplacers = map[bool]*strings.Replacer{
+	true:  typographyReplacer("ru"),
+	false: typographyReplacer(""),
+}
+
+// typographyReplacer возвращает замены текстового режима TeX для языка lang
+func typographyReplacer(lang string) *strings.Replacer {
+	openQuote, closeQuote := "“", "”"
+	if lang == "ru" {
+		openQuote, closeQuote = "«", "»"
+	}
+
+	pairs := []string{
+		// Лигатуры тире и кавычек
+		"---", "—",
+		"--", "–",
+		"``", openQuote,
+		"''", closeQuote,
+		"<<", "«",
+		">>", "»",
+		",,", "„",
+		// Неразрывные и тонкие пробелы, мягкий перенос
+		"~", "\u00a0",
+		`\,`, "\u202f",
+		`\-`, "\u00ad",
+		// Многоточие
+		`\ldots{}`, "…",
+		`\ldots`, "…",
+		`\dots{}`, "…",
+		`\dots`, "…",
+		// Экранированные спецсимволы; \$ остается для MathJax (processEscapes)
+		`\%`, "%",
+		`\&`, "&amp;",
+		`\#`, "#",
+		`\_`, "_",
+		`\{`, "{",
+		`\}`, "}",
+	}
+
+	// Сокращения babel-russian
+	if lang == "ru" {
+		pairs = append([]string{`"<`, "«", `">`, "»", `"---`, " — "}, pairs...)
+	}
+
+	return strings.NewReplacer(pairs...)
+}
+
+// processTypography применяет типографику текстового режима вне формул и HTML разметки
+func processTypography(content, lang string) string {
+	replacer := typographyReplacers[lang == "ru"]
+
+	var out strings.Builder
+	out.Grow(len(content))
+
+	textStart := 0
+	flushText := func(end int) {
+		out.WriteString(replacer.Replace(content[textStart:end]))
+	}
+
+	for i := 0; i < len(content); {
+		// Кавычки-лигатуры проверяются раньше тегов: иначе в <<ACO>> и "<a"> вторая
+		// угловая скобка с буквой приняла бы текст за HTML тег
+		if quoteLigature(content[i:], lang) {
+			i += 2
+			continue
+		}
+		skip := protectedSpanLength(content[i:])
+		if skip == 0 {
+			i++
+			continue
+		}
+
+		flushText(i)
+		out.WriteString(content[i : i+skip])
+		i += skip
+		textStart = i
+	}
+	flushText(len(content))
+
+	return out.String()
+}
+
+// quoteLigature сообщает, начинается ли s с кавычки-лигатуры << или >>, а в русском
+// тексте и с сокращения babel "< или ">
+func quoteLigature(s, lang string) bool {
+	if len(s) < 2 {
+		return false
+	}
+	switch s[:2] {
+	case "<<", ">>":
+		return true
+	case `"<`, `">`:
+		return lang == "ru"
+	}
+	return false
+}
+
+// protectedSpanLength возвращает длину формулы или HTML тега, начинающихся в начале s,
+// либо 0, если s начинается с обычного текста
+func protectedSpanLength(s string) int {
+	closeAfter := func(open, close string) int {
+		end := strings.Index(s[len(open):], close)
+		if end < 0 {
+			return len(s)
+		}
+		return len(open) + end + len(close)
+	}
+
+	switch {
+	case strings.HasPrefix(s, `\$`):
+		return 2
+	case strings.HasPrefix(s, "<!--"):
+		return closeAfter("<!--", "-->")
+	case len(s) > 1 && s[0] == '<' && (isLetter(s[1]) || s[1] == '/' || s[1] == '!'):
+		return closeAfter("<", ">")
+	case strings.HasPrefix(s, "$$"):
+		return closeAfter("$$", "$$")
+	case strings.HasPrefix(s, "$"):
+		for i := 1; i < len(s); i++ {
+			if s[i] == '\\' {
+				i++
+				continue
+			}
+			if s[i] == '$' {
+				return i + 1
+			}
+		}
+		return len(s)
+	case strings.HasPrefix(s, `\(`):
+		return closeAfter(`\(`, `\)`)
+	case strings.HasPrefix(s, `\[`):
+		return closeAfter(`\[`, `\]`)
+	}
+
+	return 0
+}
+
+func isLetter(b byte) bool {
+	return b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z'
+}
@@ -0,0 +1,51 @@
+package latex
+
+import "testing"
+
+func TestStripComments(t *testing.T) {
+	tests := []struct {
+		name, latex, want string
+	}{
+		{"комментарий до конца строки", "a % b\nc", "a c"},
+		{"ведущие пробелы следующей строки", "a%\n   b", "ab"},
+		{"экранированный процент", `50\% % скидка` + "\nдалее", `50\% далее`},
+		{"процент в \\url", `\url{https://e.org/a%20b} % x` + "\n", `\url{https://e.org/a%20b} `},
+		{"процент в \\href", `\href{https://e.org/%7E}{100%} конец`, `\href{https://e.org/%7E}{100`},
+		{"двойная обратная косая", `a\\% b` + "\nc", `a\\c`},
+		{"комментарий в конце текста", "a % b", "a "},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := stripComments(test.latex); got != test.want {
+				t.Errorf("получено %q, ожидалось %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestProcessTypography(t *testing.T) {
+	tests := []struct {
+		name, lang, text, want string
+	}{
+		{"тире", "ru", "a---b, 1--2", "a—b, 1–2"},
+		{"кавычки ru", "ru", "``слово''", "«слово»"},
+		{"кавычки en", "en", "``word''", "“word”"},
+		{"угловые кавычки", "en", "<<ACO>> и ,,x", "«ACO» и „x"},
+		{"сокращения babel ru", "ru", `"<a"> и "---`, "«a» и \u00a0— "},
+		{"сокращения babel только для ru", "en", `"<a">`, `"<a">`},
+		{"пробелы и перенос", "ru", `A.~B. 1\,000 сло\-во`, "A.\u00a0B. 1\u202f000 сло\u00adво"},
+		{"многоточие", "ru", `a\ldots{} b\dots`, "a… b…"},
+		{"спецсимволы", "ru", `\% \& \# \_ \{\}`, "% &amp; # _ {}"},
+		{"доллар остается для MathJax", "ru", `\$5 -- \$6`, `\$5 – \$6`},
+		{"формулы не меняются", "ru", `$a--b$, $$c~d$$, \(e---f\), \[g''\]`, `$a--b$, $$c~d$$, \(e---f\), \[g''\]`},
+		{"экранированный доллар в формуле", "ru", `$a\$--b$ --`, `$a\$--b$ –`},
+		{"HTML теги не меняются", "ru", `<a href="x--y">p--q</a><!-- -- -->`, `<a href="x--y">p–q</a><!-- -- -->`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := processTypography(test.text, test.lang); got != test.want {
+				t.Errorf("получено %q, ожидалось %q", got, test.want)
+			}
+		})
+	}
+}
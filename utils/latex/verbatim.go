@@ -0,0 +1,175 @@
+package latex
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// codeEnvPatterns выделяют опции (1), язык minted (2) и текст (3) окружений с кодом
+var codeEnvPatterns = map[string]*regexp.Regexp{
+	"verbatim":   regexp.MustCompile(`(?s)\\begin\{verbatim\}()()(.*?)\\end\{verbatim\}`),
+	"lstlisting": regexp.MustCompile(`(?s)\\begin\{lstlisting\}(?:\[([^\]]*)\])?()(.*?)\\end\{lstlisting\}`),
+	"minted":     regexp.MustCompile(`(?s)\\begin\{minted\}(?:\[([^\]]*)\])?\{([^}]*)\}(.*?)\\end\{minted\}`),
+}
+
+var inlineVerbRe = regexp.MustCompile(`\\(?:verb|lstinline)\*?([^a-zA-Z\s{])`)
+
+// codeBlock содержит защищенный от обработки фрагмент исходного текста
+type codeBlock struct {
+	Code     string
+	Language string
+	Caption  string
+	Inline   bool
+}
+
+// verbatimPlaceholder формирует метку, которая заменяет блок кода на время обработки.
+// Блочная метка начинается с "<", поэтому processParagraphs не оборачивает ее в абзац
+func verbatimPlaceholder(index int, inline bool) string {
+	if inline {
+		return fmt.Sprintf("\x00verb%d\x00", index)
+	}
+	return fmt.Sprintf("\n\n<!--verbatim:%d-->\n\n", index)
+}
+
+// protectVerbatim заменяет verbatim, lstlisting, minted и \verb метками,
+// чтобы их содержимое не проходило через регулярные выражения конвертера
+func protectVerbatim(content string) (string, []codeBlock) {
+	var blocks []codeBlock
+
+	for _, env := range []string{"verbatim", "lstlisting", "minted"} {
+		envRe := codeEnvPatterns[env]
+		content = envRe.ReplaceAllStringFunc(content, func(match string) string {
+			matches := envRe.FindStringSubmatch(match)
+			options := parseKeyValueOptions(matches[1])
+
+			block := codeBlock{Code: trimCodeNewlines(matches[3]), Caption: options["caption"]}
+			if env == "minted" {
+				block.Language = matches[2]
+			} else {
+				block.Language = options["language"]
+			}
+
+			blocks = append(blocks, block)
+			return verbatimPlaceholder(len(blocks)-1, false)
+		})
+	}
+
+	// \verb|...| и \lstinline|...| с произвольным разделителем
+	for {
+		loc := inlineVerbRe.FindStringSubmatchIndex(content)
+		if loc == nil {
+			break
+		}
+		delimiter := content[loc[2]:loc[3]]
+		end := strings.Index(content[loc[1]:], delimiter)
+		if end < 0 {
+			break
+		}
+		blocks = append(blocks, codeBlock{Code: content[loc[1] : loc[1]+end], Inline: true})
+		content = content[:loc[0]] + verbatimPlaceholder(len(blocks)-1, true) + content[loc[1]+end+len(delimiter):]
+	}
+
+	return content, blocks
+}
+
+// restoreVerbatim подставляет на место меток отрендеренные блоки кода
+func restoreVerbatim(content string, blocks []codeBlock) string {
+	if len(blocks) == 0 {
+		return content
+	}
+
+	// Метки заменяются за один проход по тексту, каждая не более одного раза
+	restored := make([]bool, len(blocks))
+	restore := func(content, open, close string, render func(codeBlock) string) string {
+		var out strings.Builder
+		for {
+			start := strings.Index(content, open)
+			if start < 0 {
+				break
+			}
+			end := strings.Index(content[start+len(open):], close)
+			if end < 0 {
+				break
+			}
+			end += start + len(open)
+
+			i, err := strconv.Atoi(content[start+len(open) : end])
+			out.WriteString(content[:start])
+			if err == nil && i < len(blocks) && !restored[i] {
+				restored[i] = true
+				out.WriteString(render(blocks[i]))
+			} else {
+				out.WriteString(content[start : end+len(close)])
+			}
+			content = content[end+len(close):]
+		}
+		out.WriteString(content)
+		return out.String()
+	}
+
+	content = restore(content, "\x00verb", "\x00", func(block codeBlock) string {
+		return `<code>` + html.EscapeString(block.Code) + `</code>`
+	})
+	return restore(content, "<!--verbatim:", "-->", renderCodeBlock)
+}
+
+// renderCodeBlock формирует HTML блока кода с подсветкой
+func renderCodeBlock(block codeBlock) string {
+	language := languageAliases[strings.ToLower(block.Language)]
+	class := "code"
+	if language != "" {
+		class += " language-" + language
+	}
+
+	pre := `<pre class="` + class + `"><code>` + highlightCode(block.Code, language) + `</code></pre>`
+	if block.Caption == "" {
+		return pre
+	}
+
+	return `<figure class="listing"><figcaption>` + html.EscapeString(block.Caption) + `</figcaption>` + pre + `</figure>`
+}
+
+// parseKeyValueOptions разбирает опции вида key=value, key={a, b}
+func parseKeyValueOptions(options string) map[string]string {
+	result := make(map[string]string)
+
+	var parts []string
+	depth, start := 0, 0
+	for i := 0; i < len(options); i++ {
+		switch options[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, options[start:i])
+				start = i + 1
+			}
+		}
+	}
+	parts = append(parts, options[start:])
+
+	for _, part := range parts {
+		key, value, _ := strings.Cut(part, "=")
+		key = strings.TrimSpace(key)
+		if key == "" {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		value = strings.TrimSuffix(strings.TrimPrefix(value, "{"), "}")
+		result[key] = value
+	}
+
+	return result
+}
+
+// trimCodeNewlines убирает перевод строки после \begin и пустую строку перед \end
+func trimCodeNewlines(code string) string {
+	code = strings.TrimPrefix(code, "\r")
+	code = strings.TrimPrefix(code, "\n")
+	return strings.TrimRight(code, " \t\r\n")
+}
@@ -0,0 +1,44 @@
+package latex
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestVerbatimPassthrough(t *testing.T) {
+	tests := []struct {
+		name  string
+		latex string
+		want  string
+	}{
+		{"verbatim без обработки", "\\begin{verbatim}\n\\section{Не раздел}\n$a_b$ -- 50% <b>&</b>\n\\footnote{нет}\n\\end{verbatim}",
+			"<pre data-src-line=\"3\" class=\"code\"><code>\\section{Не раздел}\n$a_b$ -- 50% &lt;b&gt;&amp;&lt;/b&gt;\n\\footnote{нет}</code></pre>"},
+		{"verb с разными разделителями", `Код \verb|$x$ -- \emph{y}| и \verb+a%b+.`,
+			`<p data-src-line="3">Код <code>$x$ -- \emph{y}</code> и <code>a%b</code>.</p>`},
+		{"lstlisting с подписью и языком", "\\begin{lstlisting}[language=Python, caption={Цикл, простой}]\nx = \"---\"\n\\end{lstlisting}",
+			`<figure data-src-line="3" class="listing"><figcaption>Цикл, простой</figcaption><pre class="code language-python"><code>x = <span class="tok-string">&#34;---&#34;</span></code></pre></figure>`},
+		{"minted", "\\begin{minted}{go}\nreturn \"<x>\" // $y$\n\\end{minted}",
+			`<pre data-src-line="3" class="code language-go"><code><span class="tok-keyword">return</span> <span class="tok-string">&#34;&lt;x&gt;&#34;</span> <span class="tok-comment">// $y$</span></code></pre>`},
+		{"неизвестный язык без подсветки", "\\begin{lstlisting}[language=Cobol]\nif x\n\\end{lstlisting}",
+			`<pre data-src-line="3" class="code"><code>if x</code></pre>`},
+		{"метка в тексте не подменяет блок", "\\begin{verbatim}\n<!--verbatim:0-->\n\\end{verbatim}",
+			`<pre data-src-line="3" class="code"><code>&lt;!--verbatim:0--&gt;</code></pre>`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			html := convert(t, document(test.latex), "ru").HTML
+			if !strings.Contains(html, test.want) {
+				t.Errorf("нет %q в\n%s", test.want, html[strings.Index(html, "<main"):strings.Index(html, "</main>")])
+			}
+		})
+	}
+}
+
+func TestParseKeyValueOptions(t *testing.T) {
+	got := parseKeyValueOptions(`language=Python, caption={Цикл, простой}, numbers , =x, label = lst:a`)
+	want := map[string]string{"language": "Python", "caption": "Цикл, простой", "numbers": "", "label": "lst:a"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("получено %q, ожидалось %q", got, want)
+	}
+}
@@ -0,0 +1,206 @@
+// Команда utils конвертирует LaTeX описания алгоритмов в HTML, Markdown или JSON модель
+// документа и строит поисковый индекс страниц:
+//
+//	go run ./utils -input static/latex/descriptions/aco.tex -output aco.html
+//	go run ./utils -search-index static/latex -output static/search-index.json
+//
+// Сам конвертер — пакет utils/latex; команда только разбирает флаги и пишет файлы
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/RiddlerXenon/roi/utils/latex"
+)
+
+func main() {
+	inputFile := flag.String("input", "", "Путь к LaTeX файлу")
+	outputFile := flag.String("output", "output.html", "Путь к выходному файлу")
+	title := flag.String("title", "", "Заголовок документа; по умолчанию берется из \\title")
+	lang := flag.String("lang", "", "Язык ключевых слов (ru, en); по умолчанию определяется по babel")
+	stringsFile := flag.String("strings", "", "JSON файл с переопределениями строк словаря")
+	format := flag.String("format", "html", "Формат вывода: html, markdown или ast (JSON модель документа)")
+	bundle := flag.Bool("bundle", false, "Собрать автономную страницу: встроить CSS, изображения и MathJax из -mathjax-dir")
+	mathJaxDir := flag.String("mathjax-dir", "", "Каталог с локальной копией MathJax (содержит tex-svg.js) для режима -bundle")
+	frontMatter := flag.Bool("front-matter", false, "Добавить JSON front-matter с метаданными в начало выходного файла")
+	searchIndex := flag.String("search-index", "", "Каталог с descriptions/*.tex и params/*.tex: построить JSON поисковый индекс и записать его в -output")
+	lint := flag.Bool("lint", false, "Проверить доступность документа (alt у изображений, подписи, уровни заголовков) вместо конвертации")
+	stream := flag.Bool("stream", false, "Потоковая конвертация в HTML: документ читается и записывается по блокам, не загружаясь в память целиком")
+	environmentsFile := flag.String("environments", "", "JSON файл с текстовыми окружениями: имя -> {element, class, title, width}")
+	sourceMap := flag.String("source-map", "", "Записать карту соответствия блоков страницы строкам .tex в указанный JSON файл")
+	dev := flag.Bool("dev", false, "Режим разработки: показывать «файл:строка» при наведении на блок")
+	editorURL := flag.String("editor-url", latex.DefaultEditorURL, "Шаблон ссылки на редактор для -dev; {path} — путь к .tex, {line} — строка")
+	paramsFile := flag.String("params", "", "Файл описаний параметров для подсказок \\param; по умолчанию ../params/<имя>.tex рядом с входным файлом")
+	flag.Parse()
+
+	if *searchIndex != "" {
+		if *lang == "" {
+			*lang = latex.DefaultLang
+		}
+		locale, err := latex.LoadLocale(*lang, *stringsFile)
+		if err != nil {
+			log.Fatalf("Ошибка загрузки словаря: %v", err)
+		}
+		output, err := latex.ConvertSearchIndex(*searchIndex, latex.Options{Locale: locale})
+		if err != nil {
+			log.Fatalf("Ошибка построения поискового индекса: %v", err)
+		}
+		if err := os.WriteFile(*outputFile, output, 0644); err != nil {
+			log.Fatalf("Ошибка записи выходного файла: %v", err)
+		}
+		fmt.Printf("Поисковый индекс сохранен в: %s\n", *outputFile)
+		return
+	}
+
+	if *inputFile == "" {
+		log.Fatal("Необходимо указать входной файл")
+	}
+
+	var environments map[string]latex.TextEnvironment
+	if *environmentsFile != "" {
+		var err error
+		environments, err = latex.LoadTextEnvironments(*environmentsFile)
+		if err != nil {
+			log.Fatalf("Ошибка загрузки окружений: %v", err)
+		}
+	}
+
+	params, err := latex.LoadDocumentParams(*paramsFile, *inputFile)
+	if err != nil {
+		log.Fatalf("Ошибка загрузки параметров: %v", err)
+	}
+
+	if *stream {
+		if *format != "html" || *bundle || *frontMatter {
+			log.Fatal("Потоковый режим поддерживает только формат html без -bundle и -front-matter")
+		}
+
+		// Без -lang и -strings язык определяется по преамбуле при чтении
+		opts := latex.Options{
+			Title:      *title,
+			BaseDir:    filepath.Dir(*inputFile),
+			DevOverlay: *dev,
+			SourcePath: *inputFile,
+			EditorURL:  *editorURL,
+
+			TextEnvironments: environments,
+			Params:           params,
+		}
+		if *lang != "" || *stringsFile != "" {
+			if *lang == "" {
+				*lang = latex.DefaultLang
+			}
+			locale, err := latex.LoadLocale(*lang, *stringsFile)
+			if err != nil {
+				log.Fatalf("Ошибка загрузки словаря: %v", err)
+			}
+			opts.Locale = locale
+		}
+
+		result, err := latex.ConvertFileStream(*inputFile, *outputFile, opts)
+		if err != nil {
+			log.Fatalf("Ошибка потоковой конвертации: %v", err)
+		}
+		latex.PrintDiagnostics(os.Stderr, *inputFile, result.Diagnostics)
+		if *sourceMap != "" {
+			if err := latex.WriteSourceMap(*sourceMap, *outputFile, *inputFile, result.SourceMap); err != nil {
+				log.Fatalf("Ошибка записи карты исходника: %v", err)
+			}
+		}
+		fmt.Printf("Конвертация завершена. Результат сохранен в: %s\n", *outputFile)
+		return
+	}
+
+	latexContent, err := os.ReadFile(*inputFile)
+	if err != nil {
+		log.Fatalf("Ошибка чтения входного файла: %v", err)
+	}
+
+	if *lang == "" {
+		*lang = latex.DetectLanguage(string(latexContent))
+	}
+	if *lang == "" {
+		*lang = latex.DefaultLang
+	}
+
+	locale, err := latex.LoadLocale(*lang, *stringsFile)
+	if err != nil {
+		log.Fatalf("Ошибка загрузки словаря: %v", err)
+	}
+
+	opts := latex.Options{
+		Title:      *title,
+		Locale:     locale,
+		BaseDir:    filepath.Dir(*inputFile),
+		Bundle:     *bundle,
+		MathJaxDir: *mathJaxDir,
+		DevOverlay: *dev,
+		SourcePath: *inputFile,
+		EditorURL:  *editorURL,
+
+		TextEnvironments: environments,
+		Params:           params,
+	}
+
+	if *lint {
+		diagnostics := latex.LintAccessibility(latex.ParseDocument(string(latexContent), opts))
+		latex.PrintDiagnostics(os.Stderr, *inputFile, diagnostics)
+		if len(diagnostics) > 0 {
+			os.Exit(1)
+		}
+		return
+	}
+
+	var output []byte
+	switch *format {
+	case "html":
+		result, err := latex.ConvertLatexToHTML(string(latexContent), opts)
+		if err != nil {
+			log.Fatalf("Ошибка конвертации LaTeX в HTML: %v", err)
+		}
+		latex.PrintDiagnostics(os.Stderr, *inputFile, result.Diagnostics)
+
+		htmlContent := result.HTML
+		if *frontMatter {
+			header, err := latex.GenerateFrontMatter(result.Metadata)
+			if err != nil {
+				log.Fatalf("Ошибка формирования front-matter: %v", err)
+			}
+			htmlContent = header + htmlContent
+		}
+		output = []byte(htmlContent)
+
+		if *sourceMap != "" {
+			if err := latex.WriteSourceMap(*sourceMap, *outputFile, *inputFile, result.SourceMap); err != nil {
+				log.Fatalf("Ошибка записи карты исходника: %v", err)
+			}
+		}
+
+	case "markdown", "md":
+		markdown, err := latex.ConvertLatexToMarkdown(string(latexContent), opts)
+		if err != nil {
+			log.Fatalf("Ошибка конвертации LaTeX в Markdown: %v", err)
+		}
+		output = []byte(markdown)
+
+	case "ast":
+		output, err = latex.ConvertLatexToAST(string(latexContent), opts)
+		if err != nil {
+			log.Fatalf("Ошибка построения модели документа: %v", err)
+		}
+
+	default:
+		log.Fatalf("Неизвестный формат вывода: %s", *format)
+	}
+
+	err = os.WriteFile(*outputFile, output, 0644)
+	if err != nil {
+		log.Fatalf("Ошибка записи выходного файла: %v", err)
+	}
+
+	fmt.Printf("Конвертация завершена. Результат сохранен в: %s\n", *outputFile)
+}